@@ -1,13 +1,20 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/google/gopacket/pcap"
+	"github.com/julianarchila/gocapture/internal/analyzer"
 	"github.com/julianarchila/gocapture/internal/capture"
+	"github.com/julianarchila/gocapture/internal/filter"
+	"github.com/julianarchila/gocapture/internal/parser"
+	"github.com/julianarchila/gocapture/internal/storage"
+	"github.com/julianarchila/gocapture/pkg/models"
 	"github.com/julianarchila/gocapture/ui"
 )
 
@@ -15,9 +22,38 @@ func main() {
 	// Parse command line arguments
 	interfaceName := flag.String("interface", "", "Network interface to capture from")
 	promiscuous := flag.Bool("promiscuous", true, "Enable promiscuous mode")
-	filter := flag.String("filter", "", "BPF filter expression")
+	bpfFilter := flag.String("filter", "", "BPF filter expression")
+	dscpMap := flag.String("dscp-map", "", "DSCP to priority overrides for the DSCP audit (e.g. \"46=6,34=5\")")
+	gateways := flag.String("gateway", "", "Gateway IPs for ARP spoofing detection, optionally with their MAC (e.g. \"192.168.1.1=aa:bb:cc:dd:ee:ff\")")
+	stpRoots := flag.String("stp-root", "", "Bridge MACs expected to be spanning tree root (e.g. \"00:11:22:33:44:55\")")
+	raRouters := flag.String("ra-router", "", "IPv6 or MAC addresses of the routers allowed to send router advertisements (e.g. \"fe80::1,00:11:22:33:44:55\")")
+	fcsMode := flag.String("fcs", "auto", "Whether Ethernet frames end with their FCS: auto, present (taps) or absent")
+	hierarchyFile := flag.String("hierarchy", "", "Print the protocol hierarchy of a .gcap, pcap or pcapng file as JSON and exit")
+	displayFilter := flag.String("display-filter", "", "Display filter selecting the frames counted by -hierarchy (e.g. \"tcp.port == 443 || dns\")")
+	ringSize := flag.Int64("ring-size", 0, "Record captures in a ring of files, starting a new file every N MB")
+	ringDuration := flag.Duration("ring-duration", 0, "Record captures in a ring of files, starting a new file every interval (e.g. 10m)")
+	ringFrames := flag.Int("ring-frames", 0, "Record captures in a ring of files, starting a new file every N frames")
+	ringFiles := flag.Int("ring-files", 0, "Number of ring files kept, deleting the oldest (0 keeps every file)")
+	ringFormat := flag.String("ring-format", storage.RingFormatGcap, "Format of the ring files: gcap, pcap or pcapng")
+	ringPrefix := flag.String("ring-prefix", "ring", "Start of the names of the ring files")
 	flag.Parse()
 
+	// Print the protocol hierarchy of a capture file without starting the UI
+	if *hierarchyFile != "" {
+		mode, err := parser.ParseFCSMode(*fcsMode)
+		if err != nil {
+			log.Fatalf("Invalid FCS mode: %v", err)
+		}
+		selection, err := filter.Compile(*displayFilter)
+		if err != nil {
+			log.Fatalf("Invalid display filter: %v", err)
+		}
+		if err := printHierarchy(*hierarchyFile, mode, selection); err != nil {
+			log.Fatalf("Failed to build protocol hierarchy: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	// List available interfaces if none specified
 	if *interfaceName == "" {
 		interfaces, err := pcap.FindAllDevs()
@@ -36,13 +72,115 @@ func main() {
 	}
 
 	// Initialize the capture engine
-	captureEngine, err := capture.NewCaptureEngine(*interfaceName, *promiscuous, *filter)
+	captureEngine, err := capture.NewCaptureEngine(*interfaceName, *promiscuous, *bpfFilter)
 	if err != nil {
 		log.Fatalf("Failed to initialize capture engine: %v", err)
 	}
+	mode, err := parser.ParseFCSMode(*fcsMode)
+	if err != nil {
+		log.Fatalf("Invalid FCS mode: %v", err)
+	}
+	captureEngine.SetFCSMode(mode)
+
+	// Record every captured frame in a ring of files when a rotation limit is given
+	if *ringSize != 0 || *ringDuration != 0 || *ringFrames != 0 || *ringFiles != 0 {
+		storageManager, err := storage.NewStorageManager("")
+		if err != nil {
+			log.Fatalf("Failed to initialize storage manager: %v", err)
+		}
+		policy := storage.RotationPolicy{
+			Prefix:      *ringPrefix,
+			Format:      *ringFormat,
+			MaxBytes:    *ringSize * 1000 * 1000,
+			MaxDuration: *ringDuration,
+			MaxFrames:   *ringFrames,
+			MaxFiles:    *ringFiles,
+		}
+		ring, err := storageManager.CreateRotatingWriter(policy, storage.SaveMetadata{
+			Interface:   *interfaceName,
+			Description: "Grabación rotativa de GoCapture",
+		})
+		if err != nil {
+			log.Fatalf("Invalid ring recording options: %v", err)
+		}
+		if err := captureEngine.SetRecorder(ring); err != nil {
+			log.Fatalf("Failed to set up ring recording: %v", err)
+		}
+	}
+
+	// Initialize the frame analyzer, bounding its tables while recording a ring
+	frameAnalyzer := analyzer.NewFrameAnalyzer()
+	frameAnalyzer.SetBounded(captureEngine.Recorder() != nil)
+	if *dscpMap != "" {
+		mapping, err := analyzer.ParseDSCPMapping(*dscpMap)
+		if err != nil {
+			log.Fatalf("Invalid DSCP mapping: %v", err)
+		}
+		frameAnalyzer.SetDSCPMapping(mapping)
+	}
+	if *gateways != "" {
+		gatewayMACs, err := analyzer.ParseGateways(*gateways)
+		if err != nil {
+			log.Fatalf("Invalid gateway list: %v", err)
+		}
+		for ip, mac := range gatewayMACs {
+			frameAnalyzer.SetGateway(ip, mac)
+		}
+	}
+	if *stpRoots != "" {
+		rootMACs, err := analyzer.ParseBridgeMACs(*stpRoots)
+		if err != nil {
+			log.Fatalf("Invalid spanning tree root list: %v", err)
+		}
+		for _, mac := range rootMACs {
+			frameAnalyzer.SetExpectedRoot(mac)
+		}
+	}
+	if *raRouters != "" {
+		routers, err := analyzer.ParseRouters(*raRouters)
+		if err != nil {
+			log.Fatalf("Invalid router list: %v", err)
+		}
+		for _, router := range routers {
+			frameAnalyzer.SetExpectedRouter(router)
+		}
+	}
 
 	// Start the UI
-	if err := ui.StartUI(captureEngine); err != nil {
+	if err := ui.StartUI(captureEngine, frameAnalyzer); err != nil {
 		log.Fatalf("UI error: %v", err)
 	}
 }
+
+// printHierarchy writes the protocol hierarchy of the frames of a capture file
+// selected by a display filter to stdout as JSON
+func printHierarchy(path string, fcsMode parser.FCSMode, selection *filter.Filter) error {
+	var frames []*models.Frame
+	if filepath.Ext(path) == ".gcap" {
+		storageManager, err := storage.NewStorageManager(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		frames, _, err = storageManager.LoadFrames(filepath.Base(path))
+		if err != nil {
+			return err
+		}
+	} else {
+		var err error
+		frames, err = capture.ReadCaptureFile(path, fcsMode)
+		if err != nil {
+			return err
+		}
+	}
+
+	hierarchyAnalyzer := analyzer.NewHierarchyAnalyzer()
+	for _, frame := range frames {
+		if selection.Matches(frame) {
+			hierarchyAnalyzer.AnalyzeHierarchy(frame)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(hierarchyAnalyzer.Hierarchy())
+}
@@ -0,0 +1,246 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// defaultUtilizationWindow is the width of each channel utilization window
+	defaultUtilizationWindow = time.Second
+	// defaultUtilizationHistory is the number of windows kept for the sliding average
+	defaultUtilizationHistory = 60
+	// minFramesForRetryRanking avoids ranking stations with too few frames by retry rate
+	minFramesForRetryRanking = 10
+)
+
+// AirtimeOrder selects how airtime statistics are ranked
+type AirtimeOrder int
+
+const (
+	// OrderByRetries ranks entries by retry percentage
+	OrderByRetries AirtimeOrder = iota
+	// OrderByAirtime ranks entries by estimated airtime
+	OrderByAirtime
+)
+
+// AirtimeStats contains retry and airtime counters for a BSS or station
+type AirtimeStats struct {
+	Address string
+	Frames  int
+	Retries int
+	Bytes   int
+	Airtime time.Duration
+}
+
+// RetryPercent returns the percentage of frames that had the Retry flag set
+func (s *AirtimeStats) RetryPercent() float64 {
+	if s.Frames == 0 {
+		return 0
+	}
+	return float64(s.Retries) * 100 / float64(s.Frames)
+}
+
+// UtilizationWindow accumulates the airtime observed during one window
+type UtilizationWindow struct {
+	Start   time.Time
+	Frames  int
+	Airtime time.Duration
+}
+
+// AirtimeAnalyzer summarizes retries and estimated airtime of 802.11 traffic
+type AirtimeAnalyzer struct {
+	window   time.Duration
+	history  int
+	bss      map[string]*AirtimeStats
+	stations map[string]*AirtimeStats
+	windows  []UtilizationWindow
+}
+
+// NewAirtimeAnalyzer creates a new airtime analyzer
+func NewAirtimeAnalyzer() *AirtimeAnalyzer {
+	return &AirtimeAnalyzer{
+		window:   defaultUtilizationWindow,
+		history:  defaultUtilizationHistory,
+		bss:      make(map[string]*AirtimeStats),
+		stations: make(map[string]*AirtimeStats),
+	}
+}
+
+// Reset discards all accumulated statistics
+func (aa *AirtimeAnalyzer) Reset() {
+	aa.bss = make(map[string]*AirtimeStats)
+	aa.stations = make(map[string]*AirtimeStats)
+	aa.windows = nil
+}
+
+// Observe accounts a WLAN frame in the retry and airtime statistics
+func (aa *AirtimeAnalyzer) Observe(frame *models.Frame) {
+	frameControl, ok := frame.FrameControl.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	retry, _ := frameControl["Retry"].(bool)
+	airtime := estimateAirtime(frame)
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+	if airtime > 0 {
+		frame.AnalysisResults["Airtime"] = fmt.Sprintf("%d μs", airtime.Microseconds())
+	}
+
+	// Per-BSS statistics
+	if bssid := frame.BSSID(); bssid != "" {
+		aa.account(aa.bss, bssid, frame, retry, airtime)
+	}
+
+	// Per-station statistics are attributed to the transmitter
+	if frame.Address2 != "" {
+		aa.account(aa.stations, frame.Address2, frame, retry, airtime)
+	}
+
+	aa.accountWindow(frame.Timestamp, airtime)
+}
+
+// account updates the statistics entry for an address
+func (aa *AirtimeAnalyzer) account(table map[string]*AirtimeStats, address string, frame *models.Frame, retry bool, airtime time.Duration) {
+	stats, ok := table[address]
+	if !ok {
+		stats = &AirtimeStats{Address: address}
+		table[address] = stats
+	}
+
+	stats.Frames++
+	stats.Bytes += frame.Length
+	stats.Airtime += airtime
+	if retry {
+		stats.Retries++
+	}
+}
+
+// accountWindow adds airtime to the utilization window containing timestamp
+func (aa *AirtimeAnalyzer) accountWindow(timestamp time.Time, airtime time.Duration) {
+	start := timestamp.Truncate(aa.window)
+
+	if len(aa.windows) == 0 || start.After(aa.windows[len(aa.windows)-1].Start) {
+		aa.windows = append(aa.windows, UtilizationWindow{Start: start})
+		if len(aa.windows) > aa.history {
+			aa.windows = aa.windows[len(aa.windows)-aa.history:]
+		}
+	}
+
+	// Late frames are accounted in the most recent window
+	current := &aa.windows[len(aa.windows)-1]
+	current.Frames++
+	current.Airtime += airtime
+}
+
+// Utilization returns the channel utilization percentage of the most recent
+// window and the average over the sliding history
+func (aa *AirtimeAnalyzer) Utilization() (current float64, average float64) {
+	if len(aa.windows) == 0 {
+		return 0, 0
+	}
+
+	last := aa.windows[len(aa.windows)-1]
+	current = float64(last.Airtime) * 100 / float64(aa.window)
+
+	var total time.Duration
+	for _, w := range aa.windows {
+		total += w.Airtime
+	}
+	span := last.Start.Sub(aa.windows[0].Start) + aa.window
+	average = float64(total) * 100 / float64(span)
+
+	return current, average
+}
+
+// Windows returns a copy of the utilization windows, oldest first
+func (aa *AirtimeAnalyzer) Windows() []UtilizationWindow {
+	windows := make([]UtilizationWindow, len(aa.windows))
+	copy(windows, aa.windows)
+	return windows
+}
+
+// WindowSize returns the width of a utilization window
+func (aa *AirtimeAnalyzer) WindowSize() time.Duration {
+	return aa.window
+}
+
+// TopBSS returns up to n BSS entries ranked by the given order
+func (aa *AirtimeAnalyzer) TopBSS(n int, order AirtimeOrder) []*AirtimeStats {
+	return rankAirtimeStats(aa.bss, n, order)
+}
+
+// TopStations returns up to n station entries ranked by the given order
+func (aa *AirtimeAnalyzer) TopStations(n int, order AirtimeOrder) []*AirtimeStats {
+	return rankAirtimeStats(aa.stations, n, order)
+}
+
+// rankAirtimeStats sorts a statistics table and returns its first n entries
+func rankAirtimeStats(table map[string]*AirtimeStats, n int, order AirtimeOrder) []*AirtimeStats {
+	entries := make([]*AirtimeStats, 0, len(table))
+	for _, stats := range table {
+		if order == OrderByRetries && stats.Frames < minFramesForRetryRanking {
+			continue
+		}
+		entries = append(entries, stats)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if order == OrderByRetries && a.RetryPercent() != b.RetryPercent() {
+			return a.RetryPercent() > b.RetryPercent()
+		}
+		if a.Airtime != b.Airtime {
+			return a.Airtime > b.Airtime
+		}
+		return a.Address < b.Address
+	})
+
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// estimateAirtime estimates how long a frame occupied the medium. The radiotap
+// data rate is used when available, otherwise the Duration/NAV field.
+func estimateAirtime(frame *models.Frame) time.Duration {
+	if radioTap := frame.RadioTap; radioTap != nil && radioTap.Rate > 0 {
+		length := frame.Length - radioTap.Length
+		if !radioTap.FCSIncluded {
+			length += 4
+		}
+
+		micros := getPreambleMicros(radioTap) + float64(length*8)/radioTap.Rate
+		return time.Duration(micros * float64(time.Microsecond))
+	}
+
+	// Values with the most significant bit set are not durations (e.g. PS-Poll AID)
+	if frame.Duration&0x8000 == 0 {
+		return time.Duration(frame.Duration) * time.Microsecond
+	}
+
+	return 0
+}
+
+// getPreambleMicros returns the PHY preamble and header duration in microseconds
+func getPreambleMicros(radioTap *models.RadioTapInfo) float64 {
+	switch {
+	case radioTap.MCS >= 0:
+		return 36 // HT mixed format
+	case radioTap.Rate == 1 || radioTap.Rate == 2 || radioTap.Rate == 5.5 || radioTap.Rate == 11:
+		if radioTap.ShortPreamble {
+			return 96
+		}
+		return 192 // DSSS long preamble
+	default:
+		return 20 // OFDM
+	}
+}
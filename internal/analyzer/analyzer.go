@@ -2,22 +2,198 @@ package analyzer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/julianarchila/gocapture/pkg/models"
 )
 
 // FrameAnalyzer analyzes network frames and provides insights
 type FrameAnalyzer struct {
-	securityAnalyzer *SecurityAnalyzer
-	qosAnalyzer      *QoSAnalyzer
+	securityAnalyzer  *SecurityAnalyzer
+	qosAnalyzer       *QoSAnalyzer
+	airtimeAnalyzer   *AirtimeAnalyzer
+	wmmAnalyzer       *WMMAnalyzer
+	dscpAnalyzer      *DSCPAnalyzer
+	arpAnalyzer       *ARPAnalyzer
+	dhcpAnalyzer      *DHCPAnalyzer
+	dnsAnalyzer       *DNSAnalyzer
+	neighborAnalyzer  *NeighborAnalyzer
+	stpAnalyzer       *STPAnalyzer
+	errorAnalyzer     *ErrorAnalyzer
+	streamAnalyzer    *StreamAnalyzer
+	tcpAnalyzer       *TCPAnalyzer
+	convAnalyzer      *ConversationAnalyzer
+	hierarchyAnalyzer *HierarchyAnalyzer
+	tlsAnalyzer       *TLSAnalyzer
+	httpAnalyzer      *HTTPAnalyzer
+	ndAnalyzer        *NDAnalyzer
+
+	// tunnelDepth is the nesting level of the frame being analyzed, 0 for captured frames
+	tunnelDepth int
 }
 
 // NewFrameAnalyzer creates a new frame analyzer
 func NewFrameAnalyzer() *FrameAnalyzer {
-	return &FrameAnalyzer{
-		securityAnalyzer: NewSecurityAnalyzer(),
-		qosAnalyzer:      NewQoSAnalyzer(),
+	fa := &FrameAnalyzer{
+		securityAnalyzer:  NewSecurityAnalyzer(),
+		qosAnalyzer:       NewQoSAnalyzer(),
+		airtimeAnalyzer:   NewAirtimeAnalyzer(),
+		wmmAnalyzer:       NewWMMAnalyzer(),
+		dscpAnalyzer:      NewDSCPAnalyzer(DefaultDSCPMapping()),
+		arpAnalyzer:       NewARPAnalyzer(),
+		dhcpAnalyzer:      NewDHCPAnalyzer(),
+		dnsAnalyzer:       NewDNSAnalyzer(),
+		neighborAnalyzer:  NewNeighborAnalyzer(),
+		stpAnalyzer:       NewSTPAnalyzer(),
+		errorAnalyzer:     NewErrorAnalyzer(),
+		streamAnalyzer:    NewStreamAnalyzer(),
+		tcpAnalyzer:       NewTCPAnalyzer(),
+		convAnalyzer:      NewConversationAnalyzer(),
+		hierarchyAnalyzer: NewHierarchyAnalyzer(),
+		tlsAnalyzer:       NewTLSAnalyzer(),
+		httpAnalyzer:      NewHTTPAnalyzer(),
+		ndAnalyzer:        NewNDAnalyzer(),
 	}
+
+	// TLS handshake messages and HTTP messages are decoded from the reassembled streams
+	fa.streamAnalyzer.AddConsumer(fa.tlsAnalyzer)
+	fa.streamAnalyzer.AddConsumer(fa.httpAnalyzer)
+
+	return fa
+}
+
+// Reset discards the statistics accumulated across frames
+func (fa *FrameAnalyzer) Reset() {
+	fa.airtimeAnalyzer.Reset()
+	fa.wmmAnalyzer.Reset()
+	fa.dscpAnalyzer.Reset()
+	fa.arpAnalyzer.Reset()
+	fa.dhcpAnalyzer.Reset()
+	fa.dnsAnalyzer.Reset()
+	fa.neighborAnalyzer.Reset()
+	fa.stpAnalyzer.Reset()
+	fa.errorAnalyzer.Reset()
+	fa.streamAnalyzer.Reset()
+	fa.tcpAnalyzer.Reset()
+	fa.convAnalyzer.Reset()
+	fa.hierarchyAnalyzer.Reset()
+	fa.tlsAnalyzer.Reset()
+	fa.httpAnalyzer.Reset()
+	fa.ndAnalyzer.Reset()
+}
+
+// Flush completes the analysis once no more frames arrive, delivering the TCP
+// stream data held back by segments that were not captured
+func (fa *FrameAnalyzer) Flush() {
+	fa.streamAnalyzer.Flush()
+}
+
+// SetDSCPMapping replaces the DSCP to priority mapping used by the DSCP audit
+func (fa *FrameAnalyzer) SetDSCPMapping(mapping DSCPMapping) {
+	fa.dscpAnalyzer.SetMapping(mapping)
+}
+
+// SetBounded caps the per-connection tables and the payload kept for each TCP
+// stream, for long unattended captures such as ring recordings
+func (fa *FrameAnalyzer) SetBounded(bounded bool) {
+	fa.streamAnalyzer.SetBounded(bounded)
+	fa.tcpAnalyzer.SetBounded(bounded)
+	fa.dnsAnalyzer.SetBounded(bounded)
+}
+
+// SetGateway declares a gateway IP, and optionally its MAC, for ARP spoofing detection
+func (fa *FrameAnalyzer) SetGateway(ip string, mac string) {
+	fa.arpAnalyzer.SetGateway(ip, mac)
+}
+
+// SetExpectedRoot declares a bridge MAC allowed to become spanning tree root
+func (fa *FrameAnalyzer) SetExpectedRoot(mac string) {
+	fa.stpAnalyzer.SetExpectedRoot(mac)
+}
+
+// SetExpectedRouter declares the IPv6 address or MAC of a router allowed to send Router Advertisements
+func (fa *FrameAnalyzer) SetExpectedRouter(router string) {
+	fa.ndAnalyzer.SetExpectedRouter(router)
+}
+
+// Airtime returns the analyzer holding retry and airtime statistics
+func (fa *FrameAnalyzer) Airtime() *AirtimeAnalyzer {
+	return fa.airtimeAnalyzer
+}
+
+// WMM returns the analyzer holding per-station WMM usage
+func (fa *FrameAnalyzer) WMM() *WMMAnalyzer {
+	return fa.wmmAnalyzer
+}
+
+// DSCP returns the analyzer auditing DSCP against layer 2 priorities
+func (fa *FrameAnalyzer) DSCP() *DSCPAnalyzer {
+	return fa.dscpAnalyzer
+}
+
+// ARP returns the analyzer holding the ARP binding table and alerts
+func (fa *FrameAnalyzer) ARP() *ARPAnalyzer {
+	return fa.arpAnalyzer
+}
+
+// DHCP returns the analyzer holding DHCP leases, servers and alerts
+func (fa *FrameAnalyzer) DHCP() *DHCPAnalyzer {
+	return fa.dhcpAnalyzer
+}
+
+// DNS returns the analyzer correlating DNS, mDNS and LLMNR transactions
+func (fa *FrameAnalyzer) DNS() *DNSAnalyzer {
+	return fa.dnsAnalyzer
+}
+
+// Neighbors returns the analyzer holding the devices discovered through LLDP and CDP
+func (fa *FrameAnalyzer) Neighbors() *NeighborAnalyzer {
+	return fa.neighborAnalyzer
+}
+
+// STP returns the analyzer tracking spanning tree roots and topology changes
+func (fa *FrameAnalyzer) STP() *STPAnalyzer {
+	return fa.stpAnalyzer
+}
+
+// Errors returns the analyzer counting bad FCS, runt and giant frames
+func (fa *FrameAnalyzer) Errors() *ErrorAnalyzer {
+	return fa.errorAnalyzer
+}
+
+// Streams returns the analyzer reassembling TCP connections
+func (fa *FrameAnalyzer) Streams() *StreamAnalyzer {
+	return fa.streamAnalyzer
+}
+
+// TCP returns the analyzer performing TCP expert analysis
+func (fa *FrameAnalyzer) TCP() *TCPAnalyzer {
+	return fa.tcpAnalyzer
+}
+
+// Conversations returns the analyzer holding the conversation and endpoint tables
+func (fa *FrameAnalyzer) Conversations() *ConversationAnalyzer {
+	return fa.convAnalyzer
+}
+
+// Hierarchy returns the analyzer building the protocol hierarchy
+func (fa *FrameAnalyzer) Hierarchy() *HierarchyAnalyzer {
+	return fa.hierarchyAnalyzer
+}
+
+// TLS returns the analyzer holding the TLS sessions and their fingerprints
+func (fa *FrameAnalyzer) TLS() *TLSAnalyzer {
+	return fa.tlsAnalyzer
+}
+
+// HTTP returns the analyzer holding the HTTP transactions
+func (fa *FrameAnalyzer) HTTP() *HTTPAnalyzer {
+	return fa.httpAnalyzer
+}
+
+// ND returns the analyzer holding the IPv6 neighbor cache, routers and Neighbor Discovery alerts
+func (fa *FrameAnalyzer) ND() *NDAnalyzer {
+	return fa.ndAnalyzer
 }
 
 // AnalyzeFrame performs analysis on a frame to provide insights
@@ -39,6 +215,71 @@ func (fa *FrameAnalyzer) AnalyzeFrame(frame *models.Frame) {
 		fa.analyzeWLANDataFrame(frame)
 	}
 
+	// Describe the network and transport layers if they were decoded
+	if frame.TransportProtocol() != "" {
+		fa.analyzeNetworkLayers(frame)
+	}
+
+	// Describe the tunnel and analyze the frame it carries
+	if frame.Tunnel != nil {
+		fa.analyzeTunnel(frame)
+	}
+
+	// The protocol stack of a captured frame already includes its inner frames
+	if fa.tunnelDepth == 0 {
+		fa.hierarchyAnalyzer.AnalyzeHierarchy(frame)
+	}
+
+	// Account the frame in the conversation and endpoint tables
+	fa.convAnalyzer.AnalyzeConversation(frame)
+
+	// Reassemble TCP payload into streams and look for retransmissions and stalls
+	if frame.TCP != nil {
+		fa.streamAnalyzer.AnalyzeStream(frame)
+		fa.tcpAnalyzer.AnalyzeTCP(frame)
+	}
+
+	// Describe the TLS records not already described by their handshake messages
+	if frame.TLS != nil {
+		fa.tlsAnalyzer.AnalyzeRecords(frame)
+	}
+
+	// Record neighboring devices advertised through LLDP and CDP
+	if frame.Discovery != nil {
+		fa.neighborAnalyzer.AnalyzeDiscovery(frame)
+	}
+
+	// Track spanning tree roots and topology changes
+	if frame.STP != nil {
+		fa.stpAnalyzer.AnalyzeBPDU(frame)
+	}
+
+	// Track ARP bindings and spoofing indicators
+	if frame.ARP != nil {
+		fa.arpAnalyzer.AnalyzeARP(frame)
+	}
+
+	// Track IPv6 neighbors and routers, rogue router advertisements and duplicate addresses
+	if frame.ICMP != nil && frame.ICMP.ND != nil {
+		fa.ndAnalyzer.AnalyzeND(frame)
+	}
+
+	// Track DHCP leases and rogue servers
+	if frame.DHCP != nil {
+		fa.dhcpAnalyzer.AnalyzeDHCP(frame)
+	}
+
+	// Correlate name resolution queries with their responses
+	if frame.DNS != nil {
+		fa.dnsAnalyzer.AnalyzeDNS(frame)
+	}
+
+	// Account retries, airtime and WMM usage of 802.11 frames
+	if frame.FrameType != models.EthernetFrame {
+		fa.airtimeAnalyzer.Observe(frame)
+		fa.wmmAnalyzer.Observe(frame)
+	}
+
 	// Analyze security if present
 	if frame.Security != nil {
 		fa.securityAnalyzer.AnalyzeSecurity(frame)
@@ -48,6 +289,12 @@ func (fa *FrameAnalyzer) AnalyzeFrame(frame *models.Frame) {
 	if frame.QoS != nil {
 		fa.qosAnalyzer.AnalyzeQoS(frame)
 	}
+
+	// Audit the DSCP marking against the layer 2 priority
+	fa.dscpAnalyzer.AnalyzeDSCP(frame)
+
+	// Validate the FCS and frame size
+	fa.errorAnalyzer.AnalyzeErrors(frame)
 }
 
 // analyzeEthernetFrame provides analysis for Ethernet frames
@@ -55,7 +302,18 @@ func (fa *FrameAnalyzer) analyzeEthernetFrame(frame *models.Frame) {
 	// Add Ethernet-specific analysis
 	etherTypeDescription := getEtherTypeDescription(frame.EtherType)
 
-	frame.AnalysisResults["Summary"] = fmt.Sprintf("Ethernet frame: %s", etherTypeDescription)
+	summary := fmt.Sprintf("Ethernet frame: %s", etherTypeDescription)
+	// Packets decapsulated from IP tunnels have no link layer header
+	if frame.SourceMAC == "" && (frame.IPv4 != nil || frame.IPv6 != nil) {
+		summary = fmt.Sprintf("%s packet", etherTypeDescription)
+	}
+	if len(frame.VLANTags) > 0 {
+		vids := make([]string, 0, len(frame.VLANTags))
+		for _, tag := range frame.VLANTags {
+			vids = append(vids, fmt.Sprintf("%d", tag.VID))
+		}
+		summary = fmt.Sprintf("Ethernet frame (VLAN %s): %s", strings.Join(vids, "/"), etherTypeDescription)
+	}
 	frame.AnalysisResults["Details"] = map[string]interface{}{
 		"EtherType":      frame.EtherType,
 		"EtherTypeDesc":  etherTypeDescription,
@@ -63,10 +321,56 @@ func (fa *FrameAnalyzer) analyzeEthernetFrame(frame *models.Frame) {
 		"DestinationMAC": frame.DestinationMAC,
 	}
 
-	// Add VLAN info if present
-	if frame.VLANInfo != nil {
-		frame.AnalysisResults["VLANInfo"] = frame.VLANInfo
+	// Add the VLAN tag stack if present
+	if len(frame.VLANTags) > 0 {
+		tags := make([]string, 0, len(frame.VLANTags))
+		for _, tag := range frame.VLANTags {
+			tags = append(tags, fmt.Sprintf("%s VID=%d PCP=%d DEI=%v", tag.Kind(), tag.VID, tag.Priority, tag.DEI))
+		}
+		frame.AnalysisResults["VLANTags"] = tags
 	}
+
+	// Add the MPLS label stack if present
+	if len(frame.MPLSLabels) > 0 {
+		labels := make([]string, 0, len(frame.MPLSLabels))
+		for _, label := range frame.MPLSLabels {
+			labels = append(labels, fmt.Sprintf("%d", label.Label))
+		}
+		frame.AnalysisResults["MPLS"] = fmt.Sprintf("Label stack %s", strings.Join(labels, "/"))
+		summary += fmt.Sprintf(" [%s]", strings.Join(labels, "/"))
+	}
+
+	// Describe the PPPoE stage and PPP negotiation
+	if pppoe := frame.PPPoE; pppoe != nil {
+		pppoeInfo := map[string]interface{}{
+			"Code":      pppoe.CodeName,
+			"SessionID": fmt.Sprintf("0x%04x", pppoe.SessionID),
+		}
+		description := fmt.Sprintf("PPPoE %s", pppoe.CodeName)
+		switch pppoe.CodeName {
+		case "PADI":
+			pppoeInfo["Context"] = "A client is looking for access concentrators"
+		case "PADO":
+			pppoeInfo["Context"] = "An access concentrator is offering service"
+		case "PADR":
+			pppoeInfo["Context"] = "A client is requesting a session"
+		case "PADS":
+			pppoeInfo["Context"] = "The access concentrator confirmed the session"
+		case "PADT":
+			pppoeInfo["Context"] = "The session is being terminated"
+		case "Session":
+			pppoeInfo["PPPProtocol"] = pppoe.PPPProtocolName
+			description = fmt.Sprintf("PPPoE session 0x%04x %s", pppoe.SessionID, pppoe.PPPProtocolName)
+		}
+		if control := pppoe.Control; control != nil {
+			pppoeInfo["Negotiation"] = fmt.Sprintf("%s %s id %d", control.Protocol, control.CodeName, control.Identifier)
+			description += " " + control.CodeName
+		}
+		frame.AnalysisResults["PPPoE"] = pppoeInfo
+		summary += " | " + description
+	}
+
+	frame.AnalysisResults["Summary"] = summary
 }
 
 // analyzeWLANManagementFrame provides analysis for WLAN management frames
@@ -201,8 +505,78 @@ func (fa *FrameAnalyzer) analyzeWLANDataFrame(frame *models.Frame) {
 	frame.AnalysisResults["Direction"] = direction
 }
 
+// analyzeNetworkLayers provides analysis for the IP and transport layers of a frame
+func (fa *FrameAnalyzer) analyzeNetworkLayers(frame *models.Frame) {
+	protocol := frame.TransportProtocol()
+	src, dst := frame.Endpoints()
+
+	description := fmt.Sprintf("%s %s → %s", protocol, src, dst)
+	switch {
+	case frame.TCP != nil:
+		description += fmt.Sprintf(" [%s] Seq=%d Ack=%d Win=%d Len=%d",
+			frame.TCP.Flags, frame.TCP.Seq, frame.TCP.Ack, frame.TCP.Window, frame.TCP.PayloadLength)
+	case frame.UDP != nil:
+		description += fmt.Sprintf(" Len=%d", frame.UDP.PayloadLength)
+	case frame.ICMP != nil:
+		description += fmt.Sprintf(" %s", frame.ICMP.TypeName)
+	}
+
+	frame.AnalysisResults["Network"] = description
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | %s", summary, description)
+	}
+}
+
+// analyzeTunnel describes the tunnel header of a frame and analyzes the inner frame it carries
+func (fa *FrameAnalyzer) analyzeTunnel(frame *models.Frame) {
+	tunnel := frame.Tunnel
+
+	tunnelInfo := map[string]interface{}{
+		"Type":     tunnel.Type,
+		"Protocol": getEtherTypeDescription(tunnel.Protocol),
+	}
+	switch tunnel.Type {
+	case "VXLAN", "Geneve":
+		tunnelInfo["VNI"] = tunnel.VNI
+	case "GRE":
+		if tunnel.KeyPresent {
+			tunnelInfo["Key"] = tunnel.Key
+		}
+	case "ERSPAN":
+		tunnelInfo["SessionID"] = tunnel.SessionID
+	}
+
+	description := tunnel.String()
+	if inner := frame.Inner; inner != nil {
+		// The inner frame goes through the same analyzers as a captured frame
+		fa.tunnelDepth++
+		fa.AnalyzeFrame(inner)
+		fa.tunnelDepth--
+		if innerSummary, ok := inner.AnalysisResults["Summary"].(string); ok {
+			tunnelInfo["Inner"] = innerSummary
+			description = fmt.Sprintf("%s → %s", description, innerSummary)
+		}
+	} else {
+		tunnelInfo["Context"] = "The encapsulated payload was not decapsulated"
+	}
+	frame.AnalysisResults["Tunnel"] = tunnelInfo
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | %s", summary, description)
+	}
+}
+
 // getEtherTypeDescription returns a description of the Ethertype
 func getEtherTypeDescription(etherType uint16) string {
+	// Values up to 1500 are IEEE 802.3 lengths followed by an LLC header
+	if etherType <= 1500 {
+		if etherType == 0 {
+			return "IEEE 802.3 LLC"
+		}
+		return fmt.Sprintf("IEEE 802.3 LLC (length %d)", etherType)
+	}
+
 	switch etherType {
 	case 0x0800:
 		return "IPv4"
@@ -224,6 +598,12 @@ func getEtherTypeDescription(etherType uint16) string {
 		return "MPLS unicast"
 	case 0x8848:
 		return "MPLS multicast"
+	case 0x88CC:
+		return "LLDP"
+	case 0x6558:
+		return "Transparent Ethernet Bridging"
+	case 0x88BE:
+		return "ERSPAN"
 	default:
 		return fmt.Sprintf("Unknown (0x%04X)", etherType)
 	}
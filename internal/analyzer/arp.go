@@ -0,0 +1,285 @@
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// gratuitousARPWindow is the period over which gratuitous ARPs are counted
+	gratuitousARPWindow = 10 * time.Second
+	// gratuitousARPStormThreshold is the number of gratuitous ARPs per window considered a storm
+	gratuitousARPStormThreshold = 10
+)
+
+// ARP alert types
+const (
+	ARPAlertConflict        = "Binding conflict"
+	ARPAlertGratuitousStorm = "Gratuitous ARP storm"
+	ARPAlertGatewayClaim    = "Gateway impersonation"
+)
+
+// ARPBinding is an IP to MAC binding learned from ARP traffic
+type ARPBinding struct {
+	IP           string
+	MAC          string
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	Frames       int
+	PreviousMACs []string
+}
+
+// ARPAlert describes a suspicious ARP event
+type ARPAlert struct {
+	Timestamp   time.Time
+	FrameID     int64
+	Type        string
+	IP          string
+	MAC         string
+	Description string
+}
+
+// ARPAnalyzer builds an IP to MAC binding table and detects ARP spoofing indicators
+type ARPAnalyzer struct {
+	bindings   map[string]*ARPBinding
+	gateways   map[string]string // Configured gateway IP to expected MAC, empty when learned
+	trusted    map[string]string // Gateway IP to the MAC currently trusted for it
+	gratuitous map[string][]time.Time
+	storming   map[string]bool
+	alerts     []ARPAlert
+}
+
+// NewARPAnalyzer creates a new ARP analyzer
+func NewARPAnalyzer() *ARPAnalyzer {
+	return &ARPAnalyzer{
+		bindings:   make(map[string]*ARPBinding),
+		gateways:   make(map[string]string),
+		trusted:    make(map[string]string),
+		gratuitous: make(map[string][]time.Time),
+		storming:   make(map[string]bool),
+	}
+}
+
+// ParseGateways parses a comma separated list of gateway IPs, each optionally
+// followed by its expected MAC (e.g. "192.168.1.1=aa:bb:cc:dd:ee:ff,10.0.0.1")
+func ParseGateways(spec string) (map[string]string, error) {
+	gateways := make(map[string]string)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		ip := net.ParseIP(strings.TrimSpace(parts[0]))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid gateway IP %q", parts[0])
+		}
+
+		var mac string
+		if len(parts) == 2 {
+			hw, err := net.ParseMAC(strings.TrimSpace(parts[1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid gateway MAC %q: %v", parts[1], err)
+			}
+			mac = hw.String()
+		}
+
+		gateways[ip.String()] = mac
+	}
+
+	return gateways, nil
+}
+
+// SetGateway declares a gateway IP and, optionally, the MAC expected to own it.
+// When mac is empty the first MAC seen claiming the IP is trusted.
+func (aa *ARPAnalyzer) SetGateway(ip string, mac string) {
+	aa.gateways[ip] = mac
+	aa.trusted[ip] = mac
+}
+
+// Reset discards the binding table and alerts, keeping the configured gateways
+func (aa *ARPAnalyzer) Reset() {
+	aa.bindings = make(map[string]*ARPBinding)
+	aa.trusted = make(map[string]string, len(aa.gateways))
+	for ip, mac := range aa.gateways {
+		aa.trusted[ip] = mac
+	}
+	aa.gratuitous = make(map[string][]time.Time)
+	aa.storming = make(map[string]bool)
+	aa.alerts = nil
+}
+
+// AnalyzeARP updates the binding table with an ARP frame and checks it for spoofing indicators
+func (aa *ARPAnalyzer) AnalyzeARP(frame *models.Frame) {
+	arp := frame.ARP
+	if arp == nil {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	arpInfo := map[string]interface{}{
+		"Operation":  arp.OperationName(),
+		"SenderMAC":  arp.SenderMAC,
+		"SenderIP":   arp.SenderIP,
+		"TargetMAC":  arp.TargetMAC,
+		"TargetIP":   arp.TargetIP,
+		"Gratuitous": arp.Gratuitous,
+	}
+
+	switch {
+	case arp.Gratuitous:
+		arpInfo["Context"] = fmt.Sprintf("%s is announcing that it owns %s", arp.SenderMAC, arp.SenderIP)
+	case arp.Operation == 1:
+		arpInfo["Context"] = fmt.Sprintf("Who has %s? Tell %s", arp.TargetIP, arp.SenderIP)
+	case arp.Operation == 2:
+		arpInfo["Context"] = fmt.Sprintf("%s is at %s", arp.SenderIP, arp.SenderMAC)
+	}
+
+	var frameAlerts []string
+	raise := func(alertType string, description string) {
+		aa.alerts = append(aa.alerts, ARPAlert{
+			Timestamp:   frame.Timestamp,
+			FrameID:     frame.ID,
+			Type:        alertType,
+			IP:          arp.SenderIP,
+			MAC:         arp.SenderMAC,
+			Description: description,
+		})
+		frameAlerts = append(frameAlerts, fmt.Sprintf("%s: %s", alertType, description))
+	}
+
+	// ARP probes use 0.0.0.0 as sender and do not claim any binding
+	if arp.SenderIP != "" && arp.SenderIP != "0.0.0.0" {
+		aa.checkGateway(arp, raise)
+		aa.updateBinding(frame, raise)
+	}
+
+	if arp.Gratuitous {
+		aa.checkGratuitousStorm(frame, raise)
+	}
+
+	if len(frameAlerts) > 0 {
+		arpInfo["Alerts"] = frameAlerts
+	}
+	frame.AnalysisResults["ARP"] = arpInfo
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		summary = fmt.Sprintf("%s | ARP %s", summary, arpInfo["Context"])
+		if len(frameAlerts) > 0 {
+			summary += " [ALERT]"
+		}
+		frame.AnalysisResults["Summary"] = summary
+	}
+}
+
+// updateBinding records the sender binding and flags changes of MAC for a known IP
+func (aa *ARPAnalyzer) updateBinding(frame *models.Frame, raise func(string, string)) {
+	arp := frame.ARP
+
+	binding, ok := aa.bindings[arp.SenderIP]
+	if !ok {
+		aa.bindings[arp.SenderIP] = &ARPBinding{
+			IP:        arp.SenderIP,
+			MAC:       arp.SenderMAC,
+			FirstSeen: frame.Timestamp,
+			LastSeen:  frame.Timestamp,
+			Frames:    1,
+		}
+		return
+	}
+
+	binding.LastSeen = frame.Timestamp
+	binding.Frames++
+
+	if binding.MAC != arp.SenderMAC {
+		raise(ARPAlertConflict, fmt.Sprintf("%s moved from %s to %s", arp.SenderIP, binding.MAC, arp.SenderMAC))
+		binding.PreviousMACs = append(binding.PreviousMACs, binding.MAC)
+		binding.MAC = arp.SenderMAC
+	}
+}
+
+// checkGateway flags a MAC other than the trusted one claiming a gateway IP
+func (aa *ARPAnalyzer) checkGateway(arp *models.ARPInfo, raise func(string, string)) {
+	expected, isGateway := aa.trusted[arp.SenderIP]
+	if !isGateway {
+		return
+	}
+
+	// Trust the first MAC seen when the gateway MAC was not configured
+	if expected == "" {
+		aa.trusted[arp.SenderIP] = arp.SenderMAC
+		return
+	}
+
+	if expected != arp.SenderMAC {
+		raise(ARPAlertGatewayClaim, fmt.Sprintf("%s claims gateway %s owned by %s", arp.SenderMAC, arp.SenderIP, expected))
+	}
+}
+
+// checkGratuitousStorm flags senders emitting too many gratuitous ARPs
+func (aa *ARPAnalyzer) checkGratuitousStorm(frame *models.Frame, raise func(string, string)) {
+	mac := frame.ARP.SenderMAC
+
+	// Keep only the gratuitous ARPs inside the window
+	recent := aa.gratuitous[mac][:0]
+	for _, timestamp := range aa.gratuitous[mac] {
+		if frame.Timestamp.Sub(timestamp) < gratuitousARPWindow {
+			recent = append(recent, timestamp)
+		}
+	}
+	recent = append(recent, frame.Timestamp)
+	aa.gratuitous[mac] = recent
+
+	// Raise a single alert per storm
+	if len(recent) >= gratuitousARPStormThreshold {
+		if !aa.storming[mac] {
+			aa.storming[mac] = true
+			raise(ARPAlertGratuitousStorm, fmt.Sprintf("%s sent %d gratuitous ARPs in %s", mac, len(recent), gratuitousARPWindow))
+		}
+	} else {
+		aa.storming[mac] = false
+	}
+}
+
+// Bindings returns the IP to MAC binding table sorted by IP
+func (aa *ARPAnalyzer) Bindings() []*ARPBinding {
+	bindings := make([]*ARPBinding, 0, len(aa.bindings))
+	for _, binding := range aa.bindings {
+		bindings = append(bindings, binding)
+	}
+
+	sort.Slice(bindings, func(i, j int) bool {
+		a, b := net.ParseIP(bindings[i].IP).To4(), net.ParseIP(bindings[j].IP).To4()
+		if a != nil && b != nil {
+			return string(a) < string(b)
+		}
+		return bindings[i].IP < bindings[j].IP
+	})
+	return bindings
+}
+
+// Alerts returns the alerts raised so far in capture order
+func (aa *ARPAnalyzer) Alerts() []ARPAlert {
+	alerts := make([]ARPAlert, len(aa.alerts))
+	copy(alerts, aa.alerts)
+	return alerts
+}
+
+// Gateways returns the gateway IPs and the MAC trusted for each of them
+func (aa *ARPAnalyzer) Gateways() map[string]string {
+	gateways := make(map[string]string, len(aa.trusted))
+	for ip, mac := range aa.trusted {
+		gateways[ip] = mac
+	}
+	return gateways
+}
@@ -0,0 +1,343 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// maxConversationEntries bounds the number of conversations and endpoints kept per level
+const maxConversationEntries = 65536
+
+// ConversationLevel selects the protocol layer that identifies conversations and endpoints
+type ConversationLevel int
+
+const (
+	// LevelLink groups traffic by MAC addresses (802.11 SA/DA within a BSSID)
+	LevelLink ConversationLevel = iota
+	// LevelNetwork groups traffic by IP addresses
+	LevelNetwork
+	// LevelTransport groups traffic by protocol, IP addresses and ports
+	LevelTransport
+)
+
+// ConversationLevels lists the levels in display order
+var ConversationLevels = []ConversationLevel{LevelLink, LevelNetwork, LevelTransport}
+
+// String returns the short name of the level
+func (l ConversationLevel) String() string {
+	switch l {
+	case LevelLink:
+		return "L2"
+	case LevelNetwork:
+		return "L3"
+	case LevelTransport:
+		return "L4"
+	default:
+		return "?"
+	}
+}
+
+// TableOrder selects how conversation and endpoint tables are sorted
+type TableOrder int
+
+const (
+	// SortByBytes sorts by total bytes, largest first
+	SortByBytes TableOrder = iota
+	// SortByFrames sorts by total frames, most first
+	SortByFrames
+	// SortByDuration sorts by time between the first and last frame, longest first
+	SortByDuration
+	// SortByStart sorts by the time of the first frame, earliest first
+	SortByStart
+	// SortByAddress sorts by address
+	SortByAddress
+)
+
+// Conversation contains the traffic exchanged between two addresses. A is the
+// source of the first frame seen.
+type Conversation struct {
+	Level     ConversationLevel
+	Protocol  string // Ethernet, 802.11, IPv4, IPv6, TCP or UDP
+	AddressA  string
+	AddressB  string
+	BSSID     string // 802.11 conversations only
+	FramesAB  int
+	FramesBA  int
+	BytesAB   int
+	BytesBA   int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Frames returns the frames sent in both directions
+func (c *Conversation) Frames() int {
+	return c.FramesAB + c.FramesBA
+}
+
+// Bytes returns the bytes sent in both directions
+func (c *Conversation) Bytes() int {
+	return c.BytesAB + c.BytesBA
+}
+
+// Duration returns the time between the first and last frame
+func (c *Conversation) Duration() time.Duration {
+	return c.LastSeen.Sub(c.FirstSeen)
+}
+
+// RateAB returns the average rate from A to B in bits per second
+func (c *Conversation) RateAB() float64 {
+	return bitRate(c.BytesAB, c.Duration())
+}
+
+// RateBA returns the average rate from B to A in bits per second
+func (c *Conversation) RateBA() float64 {
+	return bitRate(c.BytesBA, c.Duration())
+}
+
+// Endpoint contains the traffic sent and received by a single address
+type Endpoint struct {
+	Level     ConversationLevel
+	Protocol  string
+	Address   string
+	TxFrames  int
+	RxFrames  int
+	TxBytes   int
+	RxBytes   int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Frames returns the frames sent and received
+func (e *Endpoint) Frames() int {
+	return e.TxFrames + e.RxFrames
+}
+
+// Bytes returns the bytes sent and received
+func (e *Endpoint) Bytes() int {
+	return e.TxBytes + e.RxBytes
+}
+
+// Duration returns the time between the first and last frame
+func (e *Endpoint) Duration() time.Duration {
+	return e.LastSeen.Sub(e.FirstSeen)
+}
+
+// conversationTable holds the conversations and endpoints of one level
+type conversationTable struct {
+	conversations map[string]*Conversation
+	endpoints     map[string]*Endpoint
+}
+
+// ConversationAnalyzer builds conversation and endpoint tables at layers 2, 3 and 4
+type ConversationAnalyzer struct {
+	tables  map[ConversationLevel]*conversationTable
+	dropped int
+}
+
+// NewConversationAnalyzer creates a new conversation analyzer
+func NewConversationAnalyzer() *ConversationAnalyzer {
+	ca := &ConversationAnalyzer{}
+	ca.Reset()
+	return ca
+}
+
+// Reset discards all conversations and endpoints
+func (ca *ConversationAnalyzer) Reset() {
+	ca.tables = make(map[ConversationLevel]*conversationTable)
+	for _, level := range ConversationLevels {
+		ca.tables[level] = &conversationTable{
+			conversations: make(map[string]*Conversation),
+			endpoints:     make(map[string]*Endpoint),
+		}
+	}
+	ca.dropped = 0
+}
+
+// AnalyzeConversation accounts a frame in the tables of every level it has addresses for
+func (ca *ConversationAnalyzer) AnalyzeConversation(frame *models.Frame) {
+	// Layer 2, 802.11 conversations are kept apart per BSSID
+	if src, dst := frame.LinkAddresses(); src != "" || dst != "" {
+		protocol, bssid := "Ethernet", ""
+		if frame.FrameType != models.EthernetFrame {
+			protocol, bssid = "802.11", frame.BSSID()
+		}
+		ca.account(LevelLink, protocol, src, dst, bssid, frame)
+	}
+
+	// Layer 3
+	if src, dst := frame.SourceIP(), frame.DestinationIP(); src != "" {
+		protocol := "IPv4"
+		if frame.IPv6 != nil {
+			protocol = "IPv6"
+		}
+		ca.account(LevelNetwork, protocol, src, dst, "", frame)
+	}
+
+	// Layer 4
+	if _, _, ok := frame.Ports(); ok {
+		src, dst := frame.Endpoints()
+		ca.account(LevelTransport, frame.TransportProtocol(), src, dst, "", frame)
+	}
+}
+
+// account updates the conversation between src and dst and both endpoints.
+// Either address may be empty, e.g. 802.11 ACKs have no transmitter address,
+// in which case only the endpoint of the other address is updated.
+func (ca *ConversationAnalyzer) account(level ConversationLevel, protocol, src, dst, bssid string, frame *models.Frame) {
+	table := ca.tables[level]
+
+	if src != "" {
+		if endpoint := ca.endpoint(table, level, protocol, src, frame); endpoint != nil {
+			endpoint.TxFrames++
+			endpoint.TxBytes += frame.Length
+		}
+	}
+	if dst != "" && dst != src {
+		if endpoint := ca.endpoint(table, level, protocol, dst, frame); endpoint != nil {
+			endpoint.RxFrames++
+			endpoint.RxBytes += frame.Length
+		}
+	}
+
+	if src == "" || dst == "" {
+		return
+	}
+
+	key := protocol + "|" + bssid + "|" + streamKey(src, dst)
+	conv, ok := table.conversations[key]
+	if !ok {
+		if len(table.conversations) >= maxConversationEntries {
+			ca.dropped++
+			return
+		}
+		conv = &Conversation{
+			Level:     level,
+			Protocol:  protocol,
+			AddressA:  src,
+			AddressB:  dst,
+			BSSID:     bssid,
+			FirstSeen: frame.Timestamp,
+		}
+		table.conversations[key] = conv
+	}
+
+	if src == conv.AddressA {
+		conv.FramesAB++
+		conv.BytesAB += frame.Length
+	} else {
+		conv.FramesBA++
+		conv.BytesBA += frame.Length
+	}
+	if frame.Timestamp.After(conv.LastSeen) {
+		conv.LastSeen = frame.Timestamp
+	}
+}
+
+// endpoint returns the endpoint entry for an address, creating it if needed.
+// It returns nil when the table is full.
+func (ca *ConversationAnalyzer) endpoint(table *conversationTable, level ConversationLevel, protocol, address string, frame *models.Frame) *Endpoint {
+	key := protocol + "|" + address
+	endpoint, ok := table.endpoints[key]
+	if !ok {
+		if len(table.endpoints) >= maxConversationEntries {
+			ca.dropped++
+			return nil
+		}
+		endpoint = &Endpoint{
+			Level:     level,
+			Protocol:  protocol,
+			Address:   address,
+			FirstSeen: frame.Timestamp,
+		}
+		table.endpoints[key] = endpoint
+	}
+
+	if frame.Timestamp.After(endpoint.LastSeen) {
+		endpoint.LastSeen = frame.Timestamp
+	}
+	return endpoint
+}
+
+// Conversations returns a copy of the conversations of a level sorted by the given order
+func (ca *ConversationAnalyzer) Conversations(level ConversationLevel, order TableOrder) []Conversation {
+	table, ok := ca.tables[level]
+	if !ok {
+		return nil
+	}
+
+	conversations := make([]Conversation, 0, len(table.conversations))
+	for _, conv := range table.conversations {
+		conversations = append(conversations, *conv)
+	}
+
+	sort.Slice(conversations, func(i, j int) bool {
+		a, b := &conversations[i], &conversations[j]
+		switch {
+		case order == SortByBytes && a.Bytes() != b.Bytes():
+			return a.Bytes() > b.Bytes()
+		case order == SortByFrames && a.Frames() != b.Frames():
+			return a.Frames() > b.Frames()
+		case order == SortByDuration && a.Duration() != b.Duration():
+			return a.Duration() > b.Duration()
+		case order == SortByStart && !a.FirstSeen.Equal(b.FirstSeen):
+			return a.FirstSeen.Before(b.FirstSeen)
+		}
+		if a.AddressA != b.AddressA {
+			return a.AddressA < b.AddressA
+		}
+		if a.AddressB != b.AddressB {
+			return a.AddressB < b.AddressB
+		}
+		return a.Protocol < b.Protocol
+	})
+
+	return conversations
+}
+
+// Endpoints returns a copy of the endpoints of a level sorted by the given order
+func (ca *ConversationAnalyzer) Endpoints(level ConversationLevel, order TableOrder) []Endpoint {
+	table, ok := ca.tables[level]
+	if !ok {
+		return nil
+	}
+
+	endpoints := make([]Endpoint, 0, len(table.endpoints))
+	for _, endpoint := range table.endpoints {
+		endpoints = append(endpoints, *endpoint)
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		a, b := &endpoints[i], &endpoints[j]
+		switch {
+		case order == SortByBytes && a.Bytes() != b.Bytes():
+			return a.Bytes() > b.Bytes()
+		case order == SortByFrames && a.Frames() != b.Frames():
+			return a.Frames() > b.Frames()
+		case order == SortByDuration && a.Duration() != b.Duration():
+			return a.Duration() > b.Duration()
+		case order == SortByStart && !a.FirstSeen.Equal(b.FirstSeen):
+			return a.FirstSeen.Before(b.FirstSeen)
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Protocol < b.Protocol
+	})
+
+	return endpoints
+}
+
+// Dropped returns how many frames were not accounted in a full table
+func (ca *ConversationAnalyzer) Dropped() int {
+	return ca.dropped
+}
+
+// bitRate returns the average rate in bits per second of bytes sent over duration
+func bitRate(bytes int, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(bytes) * 8 / duration.Seconds()
+}
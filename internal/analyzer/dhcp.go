@@ -0,0 +1,365 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// dhcpStarvationWindow is the period over which DISCOVERs from distinct clients are counted
+	dhcpStarvationWindow = 10 * time.Second
+	// dhcpStarvationThreshold is the number of distinct clients per window considered a starvation attack
+	dhcpStarvationThreshold = 20
+)
+
+// DHCP alert types
+const (
+	DHCPAlertMultipleServers = "Multiple DHCP servers"
+	DHCPAlertStarvation      = "DHCP starvation"
+)
+
+// DHCPLeaseEvent is a single DHCP message in a client's lease timeline
+type DHCPLeaseEvent struct {
+	Timestamp   time.Time
+	FrameID     int64
+	MessageType string
+	IP          string // Requested or assigned IP, if any
+	Server      string
+	LeaseTime   uint32
+}
+
+// DHCPClient is the lease state of a DHCP client on a VLAN
+type DHCPClient struct {
+	VLAN        uint16
+	Version     int
+	ClientID    string // Client MAC, or DUID when no MAC could be derived
+	Hostname    string
+	VendorClass string
+	CircuitID   string
+	RemoteID    string
+	IP          string // Currently leased IP
+	Server      string // Server that granted the current lease
+	LeaseTime   uint32
+	LeasedAt    time.Time
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	Events      []DHCPLeaseEvent
+}
+
+// DHCPServer is a DHCP server seen answering on a VLAN
+type DHCPServer struct {
+	VLAN      uint16
+	Version   int
+	ServerID  string
+	MAC       string
+	Offers    int // Offers and Advertises
+	Acks      int // Acks and Replies
+	Naks      int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// DHCPAlert describes a suspicious DHCP pattern on a VLAN
+type DHCPAlert struct {
+	Timestamp   time.Time
+	FrameID     int64
+	VLAN        uint16
+	Type        string
+	Description string
+}
+
+// dhcpDiscover is a DISCOVER or SOLICIT counted for starvation detection
+type dhcpDiscover struct {
+	timestamp time.Time
+	client    string
+}
+
+// DHCPAnalyzer tracks DHCP leases per client and detects rogue servers and starvation per VLAN
+type DHCPAnalyzer struct {
+	clients   map[string]*DHCPClient
+	servers   map[string]*DHCPServer
+	discovers map[uint16][]dhcpDiscover
+	starving  map[uint16]bool
+	alerts    []DHCPAlert
+}
+
+// NewDHCPAnalyzer creates a new DHCP analyzer
+func NewDHCPAnalyzer() *DHCPAnalyzer {
+	return &DHCPAnalyzer{
+		clients:   make(map[string]*DHCPClient),
+		servers:   make(map[string]*DHCPServer),
+		discovers: make(map[uint16][]dhcpDiscover),
+		starving:  make(map[uint16]bool),
+	}
+}
+
+// Reset discards the lease timelines, servers and alerts
+func (da *DHCPAnalyzer) Reset() {
+	da.clients = make(map[string]*DHCPClient)
+	da.servers = make(map[string]*DHCPServer)
+	da.discovers = make(map[uint16][]dhcpDiscover)
+	da.starving = make(map[uint16]bool)
+	da.alerts = nil
+}
+
+// AnalyzeDHCP updates the lease timeline and server table with a DHCP frame
+func (da *DHCPAnalyzer) AnalyzeDHCP(frame *models.Frame) {
+	dhcp := frame.DHCP
+	if dhcp == nil {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	vlan := frame.VLANID()
+	dhcpInfo := map[string]interface{}{
+		"Version":       dhcp.Version,
+		"MessageType":   dhcp.MessageType,
+		"TransactionID": fmt.Sprintf("0x%08x", dhcp.TransactionID),
+		"VLAN":          vlan,
+	}
+
+	var frameAlerts []string
+	raise := func(alertType string, description string) {
+		da.alerts = append(da.alerts, DHCPAlert{
+			Timestamp:   frame.Timestamp,
+			FrameID:     frame.ID,
+			VLAN:        vlan,
+			Type:        alertType,
+			Description: description,
+		})
+		frameAlerts = append(frameAlerts, fmt.Sprintf("%s: %s", alertType, description))
+	}
+
+	if dhcp.IsServerMessage() {
+		da.updateServer(frame, vlan, raise)
+	}
+
+	if client := da.updateClient(frame, vlan); client != nil {
+		dhcpInfo["Client"] = client.ClientID
+		if client.IP != "" {
+			dhcpInfo["LeasedIP"] = client.IP
+		}
+	}
+
+	if dhcp.MessageType == "Discover" || dhcp.MessageType == "Solicit" {
+		da.checkStarvation(frame, vlan, raise)
+	}
+
+	if len(frameAlerts) > 0 {
+		dhcpInfo["Alerts"] = frameAlerts
+	}
+	frame.AnalysisResults["DHCP"] = dhcpInfo
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		summary = fmt.Sprintf("%s | DHCP %s", summary, dhcp.MessageType)
+		if len(frameAlerts) > 0 {
+			summary += " [ALERT]"
+		}
+		frame.AnalysisResults["Summary"] = summary
+	}
+}
+
+// updateServer records the answering server and flags a second server on the same VLAN
+func (da *DHCPAnalyzer) updateServer(frame *models.Frame, vlan uint16, raise func(string, string)) {
+	dhcp := frame.DHCP
+
+	serverID := dhcp.ServerIdentifier
+	if serverID == "" {
+		serverID = frame.SourceIP()
+	}
+	if serverID == "" {
+		serverID = frame.SourceMAC
+	}
+
+	key := fmt.Sprintf("%d|%d|%s", vlan, dhcp.Version, serverID)
+	server, ok := da.servers[key]
+	if !ok {
+		server = &DHCPServer{
+			VLAN:      vlan,
+			Version:   dhcp.Version,
+			ServerID:  serverID,
+			MAC:       frame.SourceMAC,
+			FirstSeen: frame.Timestamp,
+		}
+		da.servers[key] = server
+
+		var others []string
+		for _, other := range da.servers {
+			if other != server && other.VLAN == vlan && other.Version == dhcp.Version {
+				others = append(others, other.ServerID)
+			}
+		}
+		if len(others) > 0 {
+			sort.Strings(others)
+			raise(DHCPAlertMultipleServers, fmt.Sprintf("%s (%s) answering on VLAN %d alongside %v", serverID, frame.SourceMAC, vlan, others))
+		}
+	}
+	server.LastSeen = frame.Timestamp
+
+	switch dhcp.MessageType {
+	case "Offer", "Advertise":
+		server.Offers++
+	case "Ack", "Reply":
+		server.Acks++
+	case "Nak":
+		server.Naks++
+	}
+}
+
+// updateClient appends the message to the client's lease timeline and tracks its lease
+func (da *DHCPAnalyzer) updateClient(frame *models.Frame, vlan uint16) *DHCPClient {
+	dhcp := frame.DHCP
+
+	clientID := dhcp.ClientMAC
+	if clientID == "" {
+		clientID = dhcp.ClientIdentifier
+	}
+	if clientID == "" {
+		return nil
+	}
+
+	key := fmt.Sprintf("%d|%d|%s", vlan, dhcp.Version, clientID)
+	client, ok := da.clients[key]
+	if !ok {
+		client = &DHCPClient{
+			VLAN:      vlan,
+			Version:   dhcp.Version,
+			ClientID:  clientID,
+			FirstSeen: frame.Timestamp,
+		}
+		da.clients[key] = client
+	}
+	client.LastSeen = frame.Timestamp
+
+	if dhcp.Hostname != "" {
+		client.Hostname = dhcp.Hostname
+	}
+	if dhcp.VendorClass != "" {
+		client.VendorClass = dhcp.VendorClass
+	}
+	if dhcp.CircuitID != "" {
+		client.CircuitID = dhcp.CircuitID
+	}
+	if dhcp.RemoteID != "" {
+		client.RemoteID = dhcp.RemoteID
+	}
+
+	event := DHCPLeaseEvent{
+		Timestamp:   frame.Timestamp,
+		FrameID:     frame.ID,
+		MessageType: dhcp.MessageType,
+		IP:          dhcpEventIP(dhcp),
+		LeaseTime:   dhcp.LeaseTime,
+	}
+	if dhcp.IsServerMessage() {
+		event.Server = dhcp.ServerIdentifier
+		if event.Server == "" {
+			event.Server = frame.SourceIP()
+		}
+	}
+	client.Events = append(client.Events, event)
+
+	switch dhcp.MessageType {
+	case "Ack", "Reply":
+		if event.IP != "" {
+			client.IP = event.IP
+			client.Server = event.Server
+			client.LeaseTime = dhcp.LeaseTime
+			client.LeasedAt = frame.Timestamp
+		}
+	case "Release", "Decline", "Nak":
+		client.IP = ""
+		client.Server = ""
+		client.LeaseTime = 0
+	}
+
+	return client
+}
+
+// dhcpEventIP returns the IP a DHCP message requests or assigns
+func dhcpEventIP(dhcp *models.DHCPInfo) string {
+	for _, ip := range []string{dhcp.YourIP, dhcp.RequestedIP, dhcp.ClientIP} {
+		if ip != "" && ip != "0.0.0.0" && ip != "<nil>" {
+			return ip
+		}
+	}
+	return ""
+}
+
+// checkStarvation flags many distinct clients discovering on a VLAN within the window
+func (da *DHCPAnalyzer) checkStarvation(frame *models.Frame, vlan uint16, raise func(string, string)) {
+	client := frame.DHCP.ClientMAC
+	if client == "" {
+		client = frame.DHCP.ClientIdentifier
+	}
+
+	// Keep only the discovers inside the window
+	recent := da.discovers[vlan][:0]
+	for _, discover := range da.discovers[vlan] {
+		if frame.Timestamp.Sub(discover.timestamp) < dhcpStarvationWindow {
+			recent = append(recent, discover)
+		}
+	}
+	recent = append(recent, dhcpDiscover{timestamp: frame.Timestamp, client: client})
+	da.discovers[vlan] = recent
+
+	clients := make(map[string]bool)
+	for _, discover := range recent {
+		clients[discover.client] = true
+	}
+
+	// Raise a single alert per attack
+	if len(clients) >= dhcpStarvationThreshold {
+		if !da.starving[vlan] {
+			da.starving[vlan] = true
+			raise(DHCPAlertStarvation, fmt.Sprintf("%d distinct clients sent DISCOVERs on VLAN %d in %s", len(clients), vlan, dhcpStarvationWindow))
+		}
+	} else {
+		da.starving[vlan] = false
+	}
+}
+
+// Clients returns the DHCP clients sorted by VLAN and client ID
+func (da *DHCPAnalyzer) Clients() []*DHCPClient {
+	clients := make([]*DHCPClient, 0, len(da.clients))
+	for _, client := range da.clients {
+		clients = append(clients, client)
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].VLAN != clients[j].VLAN {
+			return clients[i].VLAN < clients[j].VLAN
+		}
+		return clients[i].ClientID < clients[j].ClientID
+	})
+	return clients
+}
+
+// Servers returns the DHCP servers sorted by VLAN and server ID
+func (da *DHCPAnalyzer) Servers() []*DHCPServer {
+	servers := make([]*DHCPServer, 0, len(da.servers))
+	for _, server := range da.servers {
+		servers = append(servers, server)
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		if servers[i].VLAN != servers[j].VLAN {
+			return servers[i].VLAN < servers[j].VLAN
+		}
+		return servers[i].ServerID < servers[j].ServerID
+	})
+	return servers
+}
+
+// Alerts returns the alerts raised so far in capture order
+func (da *DHCPAnalyzer) Alerts() []DHCPAlert {
+	alerts := make([]DHCPAlert, len(da.alerts))
+	copy(alerts, da.alerts)
+	return alerts
+}
@@ -0,0 +1,345 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// maxDNSTransactions bounds the number of completed transactions kept for display
+	maxDNSTransactions = 1000
+	// maxBoundedDNSPending bounds the queries a bounded analyzer keeps waiting
+	// for their response, the oldest are counted as unanswered and dropped beyond it
+	maxBoundedDNSPending = 10000
+)
+
+// DNSTransaction is a query correlated with its response
+type DNSTransaction struct {
+	Protocol      string
+	ID            uint16
+	Client        string
+	Server        string
+	Name          string
+	Type          string
+	QueryFrame    int64
+	ResponseFrame int64
+	QueryTime     time.Time
+	Latency       time.Duration
+	ResponseCode  string
+	Answers       []string
+}
+
+// DNSClientStats summarizes the name resolution activity of a client
+type DNSClientStats struct {
+	Client    string
+	Queries   int
+	Responses int
+	NXDomain  int
+	Names     map[string]int // Queried names and how often they were queried
+}
+
+// DNSStats summarizes the name resolution activity of the capture
+type DNSStats struct {
+	Queries        int
+	Responses      int
+	Unanswered     int
+	Unmatched      int // Responses without a matching query
+	NXDomain       int
+	ResponseCodes  map[string]int
+	QueryTypes     map[string]int
+	Protocols      map[string]int
+	MinLatency     time.Duration
+	MaxLatency     time.Duration
+	AverageLatency time.Duration
+}
+
+// NXDomainRate returns the fraction of responses that were NXDOMAIN
+func (s DNSStats) NXDomainRate() float64 {
+	if s.Responses == 0 {
+		return 0
+	}
+	return float64(s.NXDomain) / float64(s.Responses)
+}
+
+// pendingDNSQuery is a query awaiting its response
+type pendingDNSQuery struct {
+	frameID   int64
+	timestamp time.Time
+	clientIP  string
+	client    string
+	server    string
+	name      string
+	qtype     string
+}
+
+// DNSAnalyzer correlates DNS, mDNS and LLMNR queries with their responses
+type DNSAnalyzer struct {
+	pending      map[string]*pendingDNSQuery
+	transactions []DNSTransaction
+	clients      map[string]*DNSClientStats
+	stats        DNSStats
+	latencySum   time.Duration
+	latencyCount int
+	dropped      int  // Pending queries dropped by a bounded analyzer
+	bounded      bool // Cap the pending queries, see SetBounded
+}
+
+// NewDNSAnalyzer creates a new DNS analyzer
+func NewDNSAnalyzer() *DNSAnalyzer {
+	da := &DNSAnalyzer{}
+	da.Reset()
+	return da
+}
+
+// Reset discards the pending queries, transactions and statistics
+func (da *DNSAnalyzer) Reset() {
+	da.pending = make(map[string]*pendingDNSQuery)
+	da.transactions = nil
+	da.clients = make(map[string]*DNSClientStats)
+	da.stats = DNSStats{
+		ResponseCodes: make(map[string]int),
+		QueryTypes:    make(map[string]int),
+		Protocols:     make(map[string]int),
+	}
+	da.latencySum = 0
+	da.latencyCount = 0
+	da.dropped = 0
+}
+
+// SetBounded caps the queries kept waiting for their response, so long
+// unattended captures use bounded memory
+func (da *DNSAnalyzer) SetBounded(bounded bool) {
+	da.bounded = bounded
+}
+
+// AnalyzeDNS records a query or correlates a response with its query
+func (da *DNSAnalyzer) AnalyzeDNS(frame *models.Frame) {
+	dns := frame.DNS
+	if dns == nil {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	name, qtype := dnsQuestion(dns)
+	dnsInfo := map[string]interface{}{
+		"Protocol": dns.Protocol,
+		"ID":       fmt.Sprintf("0x%04x", dns.ID),
+		"Name":     name,
+		"Type":     qtype,
+	}
+
+	var description string
+	if !dns.Response {
+		da.recordQuery(frame, name, qtype)
+		description = fmt.Sprintf("%s query %s %s", dns.Protocol, qtype, name)
+	} else {
+		description = fmt.Sprintf("%s response %s %s", dns.Protocol, dns.ResponseCodeName, name)
+		dnsInfo["ResponseCode"] = dns.ResponseCodeName
+		dnsInfo["Answers"] = dnsAnswers(dns)
+
+		if transaction := da.recordResponse(frame, name, qtype); transaction != nil {
+			dnsInfo["QueryFrame"] = transaction.QueryFrame
+			dnsInfo["Latency"] = transaction.Latency.String()
+			description += fmt.Sprintf(" (%s)", transaction.Latency)
+		}
+	}
+
+	frame.AnalysisResults["DNS"] = dnsInfo
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | %s", summary, description)
+	}
+}
+
+// recordQuery registers a query so its response can be correlated
+func (da *DNSAnalyzer) recordQuery(frame *models.Frame, name string, qtype string) {
+	dns := frame.DNS
+	client, server := frame.Endpoints()
+
+	da.stats.Queries++
+	da.stats.Protocols[dns.Protocol]++
+	if qtype != "" {
+		da.stats.QueryTypes[qtype]++
+	}
+
+	clientStats := da.client(frame.SourceIP())
+	clientStats.Queries++
+	if name != "" {
+		clientStats.Names[name]++
+	}
+
+	if da.bounded && len(da.pending) >= maxBoundedDNSPending {
+		da.dropPending(len(da.pending) - maxBoundedDNSPending*9/10)
+	}
+
+	// A retransmitted query replaces the pending one
+	da.pending[dnsTransactionKey(dns, client, server, name)] = &pendingDNSQuery{
+		frameID:   frame.ID,
+		timestamp: frame.Timestamp,
+		clientIP:  frame.SourceIP(),
+		client:    client,
+		server:    server,
+		name:      name,
+		qtype:     qtype,
+	}
+}
+
+// recordResponse matches a response with its pending query
+func (da *DNSAnalyzer) recordResponse(frame *models.Frame, name string, qtype string) *DNSTransaction {
+	dns := frame.DNS
+	server, client := frame.Endpoints()
+
+	da.stats.Responses++
+	da.stats.ResponseCodes[dns.ResponseCodeName]++
+	if dns.ResponseCodeName == "NXDOMAIN" {
+		da.stats.NXDomain++
+	}
+
+	key := dnsTransactionKey(dns, client, server, name)
+	query, ok := da.pending[key]
+	if !ok {
+		da.stats.Unmatched++
+		return nil
+	}
+	delete(da.pending, key)
+
+	// Responses may be multicast, so they are accounted to the querying client
+	clientStats := da.client(query.clientIP)
+	clientStats.Responses++
+	if dns.ResponseCodeName == "NXDOMAIN" {
+		clientStats.NXDomain++
+	}
+
+	transaction := DNSTransaction{
+		Protocol:      dns.Protocol,
+		ID:            dns.ID,
+		Client:        query.client,
+		Server:        query.server,
+		Name:          query.name,
+		Type:          query.qtype,
+		QueryFrame:    query.frameID,
+		ResponseFrame: frame.ID,
+		QueryTime:     query.timestamp,
+		Latency:       frame.Timestamp.Sub(query.timestamp),
+		ResponseCode:  dns.ResponseCodeName,
+		Answers:       dnsAnswers(dns),
+	}
+
+	da.latencySum += transaction.Latency
+	da.latencyCount++
+	if da.latencyCount == 1 || transaction.Latency < da.stats.MinLatency {
+		da.stats.MinLatency = transaction.Latency
+	}
+	if transaction.Latency > da.stats.MaxLatency {
+		da.stats.MaxLatency = transaction.Latency
+	}
+
+	da.transactions = append(da.transactions, transaction)
+	if len(da.transactions) > maxDNSTransactions {
+		da.transactions = da.transactions[len(da.transactions)-maxDNSTransactions:]
+	}
+
+	return &transaction
+}
+
+// client returns the statistics of a client, creating them if needed
+func (da *DNSAnalyzer) client(ip string) *DNSClientStats {
+	stats, ok := da.clients[ip]
+	if !ok {
+		stats = &DNSClientStats{
+			Client: ip,
+			Names:  make(map[string]int),
+		}
+		da.clients[ip] = stats
+	}
+	return stats
+}
+
+// dnsTransactionKey identifies a query and its response. mDNS responses are
+// multicast and usually carry ID 0, so they are matched by name instead of
+// endpoints, and LLMNR queries are multicast so only the client is known.
+func dnsTransactionKey(dns *models.DNSInfo, client string, server string, name string) string {
+	switch dns.Protocol {
+	case "mDNS":
+		return fmt.Sprintf("mDNS|%d|%s", dns.ID, strings.ToLower(name))
+	case "LLMNR":
+		return fmt.Sprintf("LLMNR|%d|%s", dns.ID, client)
+	}
+	return fmt.Sprintf("%s|%d|%s|%s", dns.Protocol, dns.ID, client, server)
+}
+
+// dnsQuestion returns the first queried name and type, falling back to the
+// first answer for mDNS responses without a question section
+func dnsQuestion(dns *models.DNSInfo) (string, string) {
+	if len(dns.Questions) > 0 {
+		return dns.Questions[0].Name, dns.Questions[0].Type
+	}
+	if len(dns.Answers) > 0 {
+		return dns.Answers[0].Name, dns.Answers[0].Type
+	}
+	return "", ""
+}
+
+// dropPending forgets the n oldest pending queries
+func (da *DNSAnalyzer) dropPending(n int) {
+	keys := make([]string, 0, len(da.pending))
+	for key := range da.pending {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return da.pending[keys[i]].timestamp.Before(da.pending[keys[j]].timestamp)
+	})
+	for _, key := range keys[:n] {
+		delete(da.pending, key)
+	}
+	da.dropped += n
+}
+
+// dnsAnswers formats the answer section of a response
+func dnsAnswers(dns *models.DNSInfo) []string {
+	answers := make([]string, 0, len(dns.Answers))
+	for _, answer := range dns.Answers {
+		answers = append(answers, fmt.Sprintf("%s %s", answer.Type, answer.Data))
+	}
+	return answers
+}
+
+// Stats returns the aggregate DNS statistics
+func (da *DNSAnalyzer) Stats() DNSStats {
+	stats := da.stats
+	stats.Unanswered = len(da.pending) + da.dropped
+	if da.latencyCount > 0 {
+		stats.AverageLatency = da.latencySum / time.Duration(da.latencyCount)
+	}
+	return stats
+}
+
+// Transactions returns the most recent correlated transactions in capture order
+func (da *DNSAnalyzer) Transactions() []DNSTransaction {
+	transactions := make([]DNSTransaction, len(da.transactions))
+	copy(transactions, da.transactions)
+	return transactions
+}
+
+// Clients returns the per-client statistics sorted by number of queries
+func (da *DNSAnalyzer) Clients() []*DNSClientStats {
+	clients := make([]*DNSClientStats, 0, len(da.clients))
+	for _, client := range da.clients {
+		clients = append(clients, client)
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Queries != clients[j].Queries {
+			return clients[i].Queries > clients[j].Queries
+		}
+		return clients[i].Client < clients[j].Client
+	})
+	return clients
+}
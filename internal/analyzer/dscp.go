@@ -0,0 +1,243 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// DSCPMapping maps DSCP code points to the expected 802.11 user priority or 802.1p PCP
+type DSCPMapping map[int]int
+
+// DefaultDSCPMapping returns the DSCP to user priority mapping recommended by RFC 8325.
+// Code points that are not listed map to UP 0.
+func DefaultDSCPMapping() DSCPMapping {
+	return DSCPMapping{
+		0:  0, // CS0
+		8:  1, // CS1
+		10: 0, // AF11
+		12: 0, // AF12
+		14: 0, // AF13
+		16: 0, // CS2
+		18: 3, // AF21
+		20: 3, // AF22
+		22: 3, // AF23
+		24: 4, // CS3
+		26: 4, // AF31
+		28: 4, // AF32
+		30: 4, // AF33
+		32: 4, // CS4
+		34: 4, // AF41
+		36: 4, // AF42
+		38: 4, // AF43
+		40: 5, // CS5
+		44: 6, // VOICE-ADMIT
+		46: 6, // EF
+		48: 7, // CS6
+		56: 0, // CS7
+	}
+}
+
+// ParseDSCPMapping parses a comma separated list of dscp=priority overrides
+// (e.g. "46=6,34=5") applied on top of the default mapping
+func ParseDSCPMapping(spec string) (DSCPMapping, error) {
+	mapping := DefaultDSCPMapping()
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid DSCP mapping entry %q, expected dscp=priority", entry)
+		}
+
+		dscp, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || dscp < 0 || dscp > 63 {
+			return nil, fmt.Errorf("invalid DSCP value %q in mapping entry %q", parts[0], entry)
+		}
+
+		priority, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || priority < 0 || priority > 7 {
+			return nil, fmt.Errorf("invalid priority %q in mapping entry %q", parts[1], entry)
+		}
+
+		mapping[dscp] = priority
+	}
+
+	return mapping, nil
+}
+
+// Expected returns the priority expected for a DSCP code point
+func (m DSCPMapping) Expected(dscp int) int {
+	return m[dscp]
+}
+
+// DSCPAuditEntry counts the frames seen with a DSCP and layer 2 priority combination
+type DSCPAuditEntry struct {
+	DSCP     int
+	Source   string // "802.11 UP" or "802.1p"
+	Priority int
+	Expected int
+	Frames   int
+}
+
+// Mismatch reports whether the layer 2 priority differs from the expected one
+func (e *DSCPAuditEntry) Mismatch() bool {
+	return e.Priority != e.Expected
+}
+
+// dscpAuditKey identifies a DSCP audit entry
+type dscpAuditKey struct {
+	dscp     int
+	source   string
+	priority int
+}
+
+// DSCPAnalyzer checks IP DSCP markings against the layer 2 priority of the same frame
+type DSCPAnalyzer struct {
+	mapping       DSCPMapping
+	entries       map[dscpAuditKey]*DSCPAuditEntry
+	unprioritized int // IP frames without a layer 2 priority to compare with
+}
+
+// NewDSCPAnalyzer creates a new DSCP analyzer using the given mapping
+func NewDSCPAnalyzer(mapping DSCPMapping) *DSCPAnalyzer {
+	return &DSCPAnalyzer{
+		mapping: mapping,
+		entries: make(map[dscpAuditKey]*DSCPAuditEntry),
+	}
+}
+
+// SetMapping replaces the DSCP to priority mapping
+func (da *DSCPAnalyzer) SetMapping(mapping DSCPMapping) {
+	da.mapping = mapping
+}
+
+// Mapping returns the DSCP to priority mapping in use
+func (da *DSCPAnalyzer) Mapping() DSCPMapping {
+	return da.mapping
+}
+
+// Reset discards all accumulated statistics
+func (da *DSCPAnalyzer) Reset() {
+	da.entries = make(map[dscpAuditKey]*DSCPAuditEntry)
+	da.unprioritized = 0
+}
+
+// AnalyzeDSCP decodes the DSCP of an IP frame and compares it with its layer 2 priority
+func (da *DSCPAnalyzer) AnalyzeDSCP(frame *models.Frame) {
+	dscp, ok := decodeDSCP(frame)
+	if !ok {
+		return
+	}
+
+	dscpInfo := map[string]interface{}{
+		"DSCP": dscp,
+		"Name": DSCPName(dscp),
+	}
+
+	// Find the layer 2 priority carried by the same frame
+	var source string
+	var priority int
+	if frame.QoS != nil {
+		source = "802.11 UP"
+		priority = frame.QoS.TID
+	} else if len(frame.VLANTags) > 0 {
+		// Switches queue on the outermost tag
+		source = "802.1p"
+		priority = int(frame.VLANTags[0].Priority)
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	if source == "" {
+		da.unprioritized++
+		frame.AnalysisResults["DSCP"] = dscpInfo
+		return
+	}
+
+	expected := da.mapping.Expected(dscp)
+	dscpInfo["PrioritySource"] = source
+	dscpInfo["Priority"] = priority
+	dscpInfo["ExpectedPriority"] = expected
+	dscpInfo["Mismatch"] = priority != expected
+	frame.AnalysisResults["DSCP"] = dscpInfo
+
+	key := dscpAuditKey{dscp: dscp, source: source, priority: priority}
+	entry, ok := da.entries[key]
+	if !ok {
+		entry = &DSCPAuditEntry{DSCP: dscp, Source: source, Priority: priority, Expected: expected}
+		da.entries[key] = entry
+	}
+	entry.Frames++
+
+	if priority != expected {
+		if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+			frame.AnalysisResults["Summary"] = fmt.Sprintf("%s [DSCP %s ≠ %s %d]",
+				summary, DSCPName(dscp), source, priority)
+		}
+	}
+}
+
+// Entries returns the audit entries sorted by DSCP, source and priority
+func (da *DSCPAnalyzer) Entries() []*DSCPAuditEntry {
+	entries := make([]*DSCPAuditEntry, 0, len(da.entries))
+	for _, entry := range da.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.DSCP != b.DSCP {
+			return a.DSCP < b.DSCP
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Priority < b.Priority
+	})
+	return entries
+}
+
+// Unprioritized returns the number of IP frames without a layer 2 priority
+func (da *DSCPAnalyzer) Unprioritized() int {
+	return da.unprioritized
+}
+
+// decodeDSCP returns the DSCP from the IPv4 or IPv6 header of a frame
+func decodeDSCP(frame *models.Frame) (int, bool) {
+	switch {
+	case frame.IPv4 != nil:
+		return int(frame.IPv4.DSCP), true
+	case frame.IPv6 != nil:
+		return int(frame.IPv6.DSCP), true
+	default:
+		return 0, false
+	}
+}
+
+// DSCPName returns the standard name of a DSCP code point
+func DSCPName(dscp int) string {
+	switch dscp {
+	case 0:
+		return "CS0"
+	case 8, 16, 24, 32, 40, 48, 56:
+		return fmt.Sprintf("CS%d", dscp/8)
+	case 10, 12, 14, 18, 20, 22, 26, 28, 30, 34, 36, 38:
+		return fmt.Sprintf("AF%d%d", dscp/8, (dscp%8)/2)
+	case 44:
+		return "VOICE-ADMIT"
+	case 46:
+		return "EF"
+	default:
+		return strconv.Itoa(dscp)
+	}
+}
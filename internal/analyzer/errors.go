@@ -0,0 +1,186 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// Frame size limits, including the 4-byte FCS
+const (
+	// minEthernetFrameSize is the shortest valid Ethernet frame
+	minEthernetFrameSize = 64
+	// maxEthernetFrameSize is the longest valid untagged Ethernet frame, each VLAN tag adds 4 bytes
+	maxEthernetFrameSize = 1518
+	// minWLANFrameSize is the length of the shortest 802.11 frames (ACK and CTS)
+	minWLANFrameSize = 14
+)
+
+// Error frame types
+const (
+	FrameErrorBadFCS = "Bad FCS"
+	FrameErrorRunt   = "Runt"
+	FrameErrorGiant  = "Giant"
+)
+
+// ErrorStats summarizes the error frames of the capture
+type ErrorStats struct {
+	Checked     int // Frames captured with their FCS, whose size is also checked
+	ErrorFrames int
+	BadFCS      int
+	Runts       int
+	Giants      int
+}
+
+// ErrorSource contains the error counters of a transmitter
+type ErrorSource struct {
+	Address     string
+	Frames      int // Frames checked from this source
+	ErrorFrames int
+	BadFCS      int
+	Runts       int
+	Giants      int
+	LastError   time.Time
+	LastFrameID int64
+}
+
+// ErrorRate returns the percentage of checked frames from the source that were errored
+func (es *ErrorSource) ErrorRate() float64 {
+	if es.Frames == 0 {
+		return 0
+	}
+	return float64(es.ErrorFrames) * 100 / float64(es.Frames)
+}
+
+// ErrorAnalyzer validates the FCS and size of frames and counts error frames per source
+type ErrorAnalyzer struct {
+	stats   ErrorStats
+	sources map[string]*ErrorSource
+}
+
+// NewErrorAnalyzer creates a new error frame analyzer
+func NewErrorAnalyzer() *ErrorAnalyzer {
+	return &ErrorAnalyzer{
+		sources: make(map[string]*ErrorSource),
+	}
+}
+
+// Reset discards the error counters
+func (ea *ErrorAnalyzer) Reset() {
+	ea.stats = ErrorStats{}
+	ea.sources = make(map[string]*ErrorSource)
+}
+
+// AnalyzeErrors classifies a frame as bad FCS, runt or giant. Sizes are only
+// checked on frames captured with their FCS, since hosts capture outgoing frames
+// before padding and after segmentation offload.
+func (ea *ErrorAnalyzer) AnalyzeErrors(frame *models.Frame) {
+	badFCS := frame.RadioTap != nil && frame.RadioTap.BadFCS
+	if frame.FCS == nil && !badFCS {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	var frameErrors []string
+	if fcs := frame.FCS; fcs != nil {
+		ea.stats.Checked++
+		if !fcs.Valid {
+			badFCS = true
+		}
+
+		if frame.FrameType == models.EthernetFrame {
+			maxSize := maxEthernetFrameSize + 4*len(frame.VLANTags)
+			switch {
+			case fcs.Length < minEthernetFrameSize:
+				frameErrors = append(frameErrors, FrameErrorRunt)
+			case fcs.Length > maxSize:
+				frameErrors = append(frameErrors, FrameErrorGiant)
+			}
+		} else if fcs.Length < minWLANFrameSize {
+			frameErrors = append(frameErrors, FrameErrorRunt)
+		}
+	}
+	if badFCS {
+		frameErrors = append([]string{FrameErrorBadFCS}, frameErrors...)
+	}
+
+	// Control frames such as ACK and CTS only carry the receiver address
+	address := frame.SourceMAC
+	if frame.FrameType != models.EthernetFrame && frame.Address2 != "" {
+		address = frame.Address2
+	}
+	source, ok := ea.sources[address]
+	if !ok {
+		source = &ErrorSource{Address: address}
+		ea.sources[address] = source
+	}
+	if frame.FCS != nil {
+		source.Frames++
+
+		fcsInfo := map[string]interface{}{
+			"Value": fmt.Sprintf("0x%08x", frame.FCS.Value),
+			"Valid": frame.FCS.Valid,
+		}
+		if !frame.FCS.Valid {
+			fcsInfo["Computed"] = fmt.Sprintf("0x%08x", frame.FCS.Computed)
+		}
+		frame.AnalysisResults["FCS"] = fcsInfo
+	}
+
+	if len(frameErrors) == 0 {
+		return
+	}
+
+	ea.stats.ErrorFrames++
+	source.ErrorFrames++
+	source.LastError = frame.Timestamp
+	source.LastFrameID = frame.ID
+	for _, kind := range frameErrors {
+		switch kind {
+		case FrameErrorBadFCS:
+			ea.stats.BadFCS++
+			source.BadFCS++
+		case FrameErrorRunt:
+			ea.stats.Runts++
+			source.Runts++
+		case FrameErrorGiant:
+			ea.stats.Giants++
+			source.Giants++
+		}
+	}
+
+	frame.AnalysisResults["FrameErrors"] = frameErrors
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | Error frame: %s", summary, strings.Join(frameErrors, ", "))
+	}
+}
+
+// Stats returns the error frame totals
+func (ea *ErrorAnalyzer) Stats() ErrorStats {
+	return ea.stats
+}
+
+// Sources returns the sources that sent error frames, most errors first
+func (ea *ErrorAnalyzer) Sources() []ErrorSource {
+	sources := make([]ErrorSource, 0, len(ea.sources))
+	for _, source := range ea.sources {
+		if source.ErrorFrames > 0 {
+			sources = append(sources, *source)
+		}
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].ErrorFrames != sources[j].ErrorFrames {
+			return sources[i].ErrorFrames > sources[j].ErrorFrames
+		}
+		return sources[i].Address < sources[j].Address
+	})
+
+	return sources
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// ProtocolNode is a protocol in the protocol hierarchy with the frames that
+// carried it. Percentages are relative to every frame of the capture.
+type ProtocolNode struct {
+	Protocol     string          `json:"protocol"`
+	Frames       int             `json:"frames"`
+	Bytes        int             `json:"bytes"`
+	FramePercent float64         `json:"frame_percent"`
+	BytePercent  float64         `json:"byte_percent"`
+	Children     []*ProtocolNode `json:"children,omitempty"`
+}
+
+// protocolCounter accumulates the frames seen for a protocol under its parent
+type protocolCounter struct {
+	frames   int
+	bytes    int
+	children map[string]*protocolCounter
+}
+
+// HierarchyAnalyzer builds the protocol hierarchy of the capture from the protocol stack of each frame
+type HierarchyAnalyzer struct {
+	root *protocolCounter
+}
+
+// NewHierarchyAnalyzer creates a new protocol hierarchy analyzer
+func NewHierarchyAnalyzer() *HierarchyAnalyzer {
+	ha := &HierarchyAnalyzer{}
+	ha.Reset()
+	return ha
+}
+
+// Reset discards the protocol hierarchy
+func (ha *HierarchyAnalyzer) Reset() {
+	ha.root = &protocolCounter{children: make(map[string]*protocolCounter)}
+}
+
+// AnalyzeHierarchy accounts a frame under each protocol of its stack
+func (ha *HierarchyAnalyzer) AnalyzeHierarchy(frame *models.Frame) {
+	node := ha.root
+	node.frames++
+	node.bytes += frame.Length
+
+	for _, protocol := range frame.ProtocolStack() {
+		child, ok := node.children[protocol]
+		if !ok {
+			child = &protocolCounter{children: make(map[string]*protocolCounter)}
+			node.children[protocol] = child
+		}
+		child.frames++
+		child.bytes += frame.Length
+		node = child
+	}
+}
+
+// Hierarchy returns a snapshot of the protocol hierarchy. The root node counts
+// every frame and its children are the link layer protocols. Children are
+// sorted by bytes, largest first.
+func (ha *HierarchyAnalyzer) Hierarchy() *ProtocolNode {
+	return ha.snapshot("Frame", ha.root, ha.root)
+}
+
+// snapshot converts a counter and its descendants to protocol nodes
+func (ha *HierarchyAnalyzer) snapshot(protocol string, counter *protocolCounter, total *protocolCounter) *ProtocolNode {
+	node := &ProtocolNode{
+		Protocol: protocol,
+		Frames:   counter.frames,
+		Bytes:    counter.bytes,
+	}
+	if total.frames > 0 {
+		node.FramePercent = float64(counter.frames) * 100 / float64(total.frames)
+	}
+	if total.bytes > 0 {
+		node.BytePercent = float64(counter.bytes) * 100 / float64(total.bytes)
+	}
+
+	for name, child := range counter.children {
+		node.Children = append(node.Children, ha.snapshot(name, child, total))
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Protocol < b.Protocol
+	})
+
+	return node
+}
@@ -0,0 +1,547 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// maxHTTPHeaderBytes bounds the header block buffered for a message
+	maxHTTPHeaderBytes = 64 << 10
+	// maxHTTPTransactions bounds the number of transactions kept for display and export
+	maxHTTPTransactions = 10000
+)
+
+// HTTPHeader is a header field of an HTTP message
+type HTTPHeader struct {
+	Name  string
+	Value string
+}
+
+// HTTPTransaction is an HTTP/1.x request paired with its response. Times are
+// those of the first and last byte of each message; the response fields are
+// empty while the request is unanswered.
+type HTTPTransaction struct {
+	StreamID int
+	Client   string
+	Server   string
+
+	Method            string // Empty when only the response was captured
+	URI               string
+	Host              string
+	RequestVersion    string
+	RequestHeaders    []HTTPHeader
+	RequestHeaderSize int
+	RequestBodySize   int
+	RequestStart      time.Time
+	RequestEnd        time.Time
+	RequestFrame      int64
+
+	Status             int
+	Reason             string
+	ResponseVersion    string
+	ResponseHeaders    []HTTPHeader
+	ResponseHeaderSize int
+	ResponseBodySize   int
+	ContentType        string
+	ResponseStart      time.Time
+	ResponseEnd        time.Time
+	ResponseFrame      int64
+
+	Complete bool // The whole response body was seen
+}
+
+// Answered reports whether the response of the transaction was seen
+func (t *HTTPTransaction) Answered() bool {
+	return t.Status != 0
+}
+
+// ResponseTime returns the time the server took to answer, from the end of the
+// request to the start of the response
+func (t *HTTPTransaction) ResponseTime() time.Duration {
+	if !t.Answered() || t.RequestEnd.IsZero() {
+		return 0
+	}
+	return t.ResponseStart.Sub(t.RequestEnd)
+}
+
+// Start returns the time of the first captured byte of the transaction
+func (t *HTTPTransaction) Start() time.Time {
+	if !t.RequestStart.IsZero() {
+		return t.RequestStart
+	}
+	return t.ResponseStart
+}
+
+// httpState is the part of a message an HTTP direction is reading
+type httpState int
+
+const (
+	httpStateHeaders    httpState = iota
+	httpStateBody                 // Body with a Content-Length
+	httpStateChunkSize            // Chunk size line of a chunked body
+	httpStateChunkData            // Chunk data
+	httpStateChunkCRLF            // Line break after the chunk data
+	httpStateTrailers             // Trailer fields after the last chunk
+	httpStateUntilClose           // Response body delimited by the connection close
+)
+
+// httpDirection parses the messages sent in one direction of a connection
+type httpDirection struct {
+	state     httpState
+	requests  bool   // The direction sends requests, set by its first message
+	known     bool   // The role of the direction is known
+	buf       []byte // Header block or chunk line not yet complete
+	remaining int    // Bytes left of the body or chunk
+	current   *HTTPTransaction
+	done      bool // The data is not HTTP, bytes were lost or the protocol changed
+}
+
+// httpConnection pairs the requests and responses of a TCP stream
+type httpConnection struct {
+	client  httpDirection
+	server  httpDirection
+	pending []*HTTPTransaction // Requests waiting for their response, in order
+}
+
+// HTTPAnalyzer parses HTTP/1.x messages from reassembled TCP streams and pairs
+// requests with their responses
+type HTTPAnalyzer struct {
+	connections  map[int]*httpConnection // Keyed by TCP stream ID
+	transactions []*HTTPTransaction
+	dropped      int
+}
+
+// NewHTTPAnalyzer creates a new HTTP analyzer
+func NewHTTPAnalyzer() *HTTPAnalyzer {
+	ha := &HTTPAnalyzer{}
+	ha.Reset()
+	return ha
+}
+
+// Reset discards the tracked connections and transactions
+func (ha *HTTPAnalyzer) Reset() {
+	ha.connections = make(map[int]*httpConnection)
+	ha.transactions = nil
+	ha.dropped = 0
+}
+
+// ReleaseStream forgets the HTTP state of a dropped stream, implementing StreamConsumer
+func (ha *HTTPAnalyzer) ReleaseStream(stream *TCPStream) {
+	delete(ha.connections, stream.ID)
+}
+
+// ConsumeStream parses the HTTP messages in the reassembled payload of a TCP
+// stream, implementing StreamConsumer
+func (ha *HTTPAnalyzer) ConsumeStream(stream *TCPStream, chunk StreamChunk, frame *models.Frame) {
+	conn, ok := ha.connections[stream.ID]
+	if !ok {
+		conn = &httpConnection{}
+		ha.connections[stream.ID] = conn
+	}
+
+	dir := &conn.server
+	if chunk.FromClient {
+		dir = &conn.client
+	}
+	if dir.done {
+		return
+	}
+
+	// Messages cannot be delimited once bytes are missing
+	if chunk.Skipped > 0 {
+		dir.stop()
+		return
+	}
+
+	end := chunk.Timestamp
+	if frame != nil {
+		end = frame.Timestamp
+	}
+
+	data := chunk.Data
+	for len(data) > 0 && !dir.done {
+		switch dir.state {
+		case httpStateHeaders:
+			dir.buf = append(dir.buf, data...)
+			headerEnd := bytes.Index(dir.buf, []byte("\r\n\r\n"))
+			if headerEnd < 0 {
+				if len(dir.buf) > maxHTTPHeaderBytes || !looksLikeHTTP(dir.buf) {
+					dir.stop()
+				}
+				return
+			}
+			header := dir.buf[:headerEnd+4]
+			data = dir.buf[headerEnd+4:]
+			dir.buf = nil
+			ha.handleMessage(conn, dir, stream, chunk, header, frame)
+			if dir.current != nil && dir.state == httpStateHeaders {
+				ha.endMessage(dir, end)
+			}
+
+		case httpStateBody, httpStateChunkData:
+			n := min(len(data), dir.remaining)
+			dir.addBody(n)
+			dir.remaining -= n
+			data = data[n:]
+			if dir.remaining > 0 {
+				break
+			}
+			if dir.state == httpStateBody {
+				ha.endMessage(dir, end)
+			} else {
+				dir.state = httpStateChunkCRLF
+				dir.remaining = 2
+			}
+
+		case httpStateChunkCRLF:
+			n := min(len(data), dir.remaining)
+			dir.remaining -= n
+			data = data[n:]
+			if dir.remaining == 0 {
+				dir.state = httpStateChunkSize
+			}
+
+		case httpStateChunkSize:
+			dir.buf = append(dir.buf, data...)
+			lineEnd := bytes.Index(dir.buf, []byte("\r\n"))
+			if lineEnd < 0 {
+				if len(dir.buf) > maxHTTPHeaderBytes {
+					dir.stop()
+				}
+				return
+			}
+			line := string(dir.buf[:lineEnd])
+			data = dir.buf[lineEnd+2:]
+			dir.buf = nil
+
+			// Chunk extensions follow the size after a semicolon
+			if i := strings.IndexByte(line, ';'); i >= 0 {
+				line = line[:i]
+			}
+			size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 32)
+			switch {
+			case err != nil || size < 0:
+				dir.stop()
+			case size == 0:
+				dir.state = httpStateTrailers
+			default:
+				dir.state = httpStateChunkData
+				dir.remaining = int(size)
+			}
+
+		case httpStateTrailers:
+			dir.buf = append(dir.buf, data...)
+			trailerEnd := 2
+			if !bytes.HasPrefix(dir.buf, []byte("\r\n")) {
+				trailerEnd = bytes.Index(dir.buf, []byte("\r\n\r\n"))
+				if trailerEnd < 0 {
+					if len(dir.buf) > maxHTTPHeaderBytes {
+						dir.stop()
+					}
+					return
+				}
+				trailerEnd += 4
+			}
+			data = dir.buf[trailerEnd:]
+			dir.buf = nil
+			ha.endMessage(dir, end)
+
+		case httpStateUntilClose:
+			dir.addBody(len(data))
+			dir.current.ResponseEnd = end
+			data = nil
+		}
+	}
+}
+
+// looksLikeHTTP reports whether a partial header block can start with the
+// start line of an HTTP/1.x request or response
+func looksLikeHTTP(data []byte) bool {
+	version := []byte("HTTP/1.")
+	if n := min(len(data), len(version)); bytes.Equal(data[:n], version[:n]) {
+		return true
+	}
+
+	// Otherwise it starts with a request method
+	method, _, _ := bytes.Cut(data, []byte(" "))
+	return isHTTPMethod(string(method))
+}
+
+// handleMessage parses the header block of a message and pairs it with its
+// transaction, setting the state for its body
+func (ha *HTTPAnalyzer) handleMessage(conn *httpConnection, dir *httpDirection, stream *TCPStream, chunk StreamChunk, header []byte, frame *models.Frame) {
+	lines := strings.Split(string(header[:len(header)-4]), "\r\n")
+	startLine := strings.SplitN(lines[0], " ", 3)
+	if len(startLine) < 2 {
+		dir.stop()
+		return
+	}
+
+	response := strings.HasPrefix(startLine[0], "HTTP/1.")
+	if response {
+		if _, err := strconv.Atoi(startLine[1]); err != nil || len(startLine[1]) != 3 {
+			dir.stop()
+			return
+		}
+	} else if len(startLine) != 3 || !strings.HasPrefix(startLine[2], "HTTP/1.") || !isHTTPMethod(startLine[0]) {
+		dir.stop()
+		return
+	}
+
+	// A direction either sends requests or responses
+	if !dir.known {
+		dir.known = true
+		dir.requests = !response
+	} else if dir.requests == response {
+		dir.stop()
+		return
+	}
+
+	headers := make([]HTTPHeader, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		headers = append(headers, HTTPHeader{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+
+	chunkEnd := chunk.Timestamp
+	if frame != nil {
+		chunkEnd = frame.Timestamp
+	}
+
+	var description string
+	info := make(map[string]interface{})
+	if response {
+		status, _ := strconv.Atoi(startLine[1])
+		reason := ""
+		if len(startLine) == 3 {
+			reason = startLine[2]
+		}
+
+		// Interim responses do not answer the request
+		if status >= 100 && status < 200 {
+			if status == 101 {
+				// The connection switches to another protocol
+				conn.client.stop()
+				conn.server.stop()
+			}
+			ha.annotate(frame, fmt.Sprintf("HTTP %d %s", status, reason), map[string]interface{}{"Status": status})
+			return
+		}
+
+		var transaction *HTTPTransaction
+		if len(conn.pending) > 0 {
+			transaction = conn.pending[0]
+			conn.pending = conn.pending[1:]
+		} else {
+			transaction = ha.newTransaction(stream, !chunk.FromClient)
+		}
+		transaction.Status = status
+		transaction.Reason = reason
+		transaction.ResponseVersion = startLine[0]
+		transaction.ResponseHeaders = headers
+		transaction.ResponseHeaderSize = len(header)
+		transaction.ContentType = HTTPHeaderValue(headers, "Content-Type")
+		transaction.ResponseStart = chunk.Timestamp
+		transaction.ResponseEnd = chunkEnd
+		transaction.ResponseFrame = chunk.FrameID
+		dir.current = transaction
+
+		switch {
+		case transaction.Method == "HEAD" || status == 204 || status == 304:
+			dir.state = httpStateHeaders
+		case transaction.Method == "CONNECT" && status < 300:
+			// The connection becomes a tunnel
+			conn.client.stop()
+			conn.server.stop()
+		default:
+			dir.setBody(headers, true)
+		}
+
+		info["Status"] = status
+		info["Reason"] = reason
+		info["ContentType"] = transaction.ContentType
+		description = fmt.Sprintf("HTTP %d %s", status, reason)
+		if delay := transaction.ResponseTime(); delay > 0 {
+			info["ResponseTime"] = delay.String()
+			description += fmt.Sprintf(" (%.1f ms)", float64(delay.Microseconds())/1000)
+		}
+	} else {
+		transaction := ha.newTransaction(stream, chunk.FromClient)
+		transaction.Method = startLine[0]
+		transaction.URI = startLine[1]
+		transaction.RequestVersion = startLine[2]
+		transaction.Host = HTTPHeaderValue(headers, "Host")
+		transaction.RequestHeaders = headers
+		transaction.RequestHeaderSize = len(header)
+		transaction.RequestStart = chunk.Timestamp
+		transaction.RequestEnd = chunkEnd
+		transaction.RequestFrame = chunk.FrameID
+		conn.pending = append(conn.pending, transaction)
+		dir.current = transaction
+		dir.setBody(headers, false)
+
+		info["Method"] = transaction.Method
+		info["URI"] = transaction.URI
+		info["Host"] = transaction.Host
+		description = fmt.Sprintf("HTTP %s %s", transaction.Method, transaction.URI)
+	}
+
+	ha.annotate(frame, description, info)
+}
+
+// newTransaction creates a transaction on a stream, recording it while there
+// is room. fromClient tells whether the stream's client sent the request.
+func (ha *HTTPAnalyzer) newTransaction(stream *TCPStream, fromClient bool) *HTTPTransaction {
+	transaction := &HTTPTransaction{
+		StreamID: stream.ID,
+		Client:   stream.Client,
+		Server:   stream.Server,
+	}
+	if !fromClient {
+		transaction.Client, transaction.Server = stream.Server, stream.Client
+	}
+
+	if len(ha.transactions) < maxHTTPTransactions {
+		ha.transactions = append(ha.transactions, transaction)
+	} else {
+		ha.dropped++
+	}
+	return transaction
+}
+
+// endMessage completes the message being read by a direction
+func (ha *HTTPAnalyzer) endMessage(dir *httpDirection, end time.Time) {
+	if transaction := dir.current; transaction != nil {
+		if dir.requests {
+			transaction.RequestEnd = end
+		} else {
+			transaction.ResponseEnd = end
+			transaction.Complete = true
+		}
+	}
+	dir.current = nil
+	dir.state = httpStateHeaders
+}
+
+// annotate describes an HTTP message on the frame that completed its header
+func (ha *HTTPAnalyzer) annotate(frame *models.Frame, description string, info map[string]interface{}) {
+	if frame == nil {
+		return
+	}
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	// A frame may complete several pipelined messages
+	httpInfo, ok := frame.AnalysisResults["HTTP"].(map[string]interface{})
+	if !ok {
+		httpInfo = make(map[string]interface{})
+		frame.AnalysisResults["HTTP"] = httpInfo
+	}
+	messages, _ := httpInfo["Messages"].([]string)
+	httpInfo["Messages"] = append(messages, description)
+	for key, value := range info {
+		httpInfo[key] = value
+	}
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | %s", summary, description)
+	}
+}
+
+// setBody sets the state for the body that follows a header block
+func (d *httpDirection) setBody(headers []HTTPHeader, response bool) {
+	if strings.Contains(strings.ToLower(HTTPHeaderValue(headers, "Transfer-Encoding")), "chunked") {
+		d.state = httpStateChunkSize
+		return
+	}
+
+	if value := HTTPHeaderValue(headers, "Content-Length"); value != "" {
+		length, err := strconv.Atoi(value)
+		if err != nil || length < 0 {
+			d.stop()
+			return
+		}
+		if length > 0 {
+			d.state = httpStateBody
+			d.remaining = length
+			return
+		}
+	} else if response {
+		d.state = httpStateUntilClose
+		return
+	}
+
+	// Without a body the message ends with its header, as the caller checks
+	d.state = httpStateHeaders
+}
+
+// addBody accounts n body bytes to the message being read
+func (d *httpDirection) addBody(n int) {
+	if d.current == nil {
+		return
+	}
+	if d.requests {
+		d.current.RequestBodySize += n
+	} else {
+		d.current.ResponseBodySize += n
+	}
+}
+
+// stop ends the parsing of a direction
+func (d *httpDirection) stop() {
+	d.done = true
+	d.buf = nil
+	d.current = nil
+}
+
+// isHTTPMethod reports whether s is a method token of uppercase letters
+func isHTTPMethod(s string) bool {
+	if s == "" || len(s) > 16 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// HTTPHeaderValue returns the value of the first header with the given name
+func HTTPHeaderValue(headers []HTTPHeader, name string) string {
+	for _, header := range headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+	return ""
+}
+
+// Transactions returns a copy of the HTTP transactions ordered by their first byte
+func (ha *HTTPAnalyzer) Transactions() []HTTPTransaction {
+	transactions := make([]HTTPTransaction, 0, len(ha.transactions))
+	for _, transaction := range ha.transactions {
+		transactions = append(transactions, *transaction)
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Start().Before(transactions[j].Start())
+	})
+	return transactions
+}
+
+// Dropped returns the number of transactions not kept because the table was full
+func (ha *HTTPAnalyzer) Dropped() int {
+	return ha.dropped
+}
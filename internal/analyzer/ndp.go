@@ -0,0 +1,396 @@
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// dadProbeWindow is how long after a duplicate address detection probe an
+// advertisement of the probed address counts as a conflict
+const dadProbeWindow = 5 * time.Second
+
+// Neighbor Discovery alert types
+const (
+	NDAlertRogueRouter = "Unexpected router"
+	NDAlertDADConflict = "Duplicate address"
+)
+
+// NDNeighbor is an IPv6 to MAC binding learned from Neighbor Discovery traffic
+type NDNeighbor struct {
+	IP           string
+	MAC          string
+	Router       bool
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	Frames       int
+	PreviousMACs []string
+}
+
+// NDRouter is a router sending Router Advertisements and the configuration it announces
+type NDRouter struct {
+	IP             string
+	MAC            string
+	FirstSeen      time.Time
+	LastSeen       time.Time
+	Advertisements int
+	Lifetime       uint16 // Router lifetime of the last advertisement, in seconds
+	Preference     string
+	Managed        bool
+	OtherConfig    bool
+	MTU            uint32
+	Prefixes       []string
+	RDNSS          []string
+	Expected       bool // Listed as an allowed router, or the first router seen when none is listed
+}
+
+// NDAlert describes a suspicious Neighbor Discovery event
+type NDAlert struct {
+	Timestamp   time.Time
+	FrameID     int64
+	Type        string
+	IP          string
+	MAC         string
+	Description string
+}
+
+// dadProbe is a duplicate address detection probe waiting for a conflicting advertisement
+type dadProbe struct {
+	mac       string
+	timestamp time.Time
+	alerted   bool
+}
+
+// NDAnalyzer builds the IPv6 neighbor cache and router list from ICMPv6
+// Neighbor Discovery and detects rogue routers and duplicate addresses
+type NDAnalyzer struct {
+	neighbors       map[string]*NDNeighbor
+	routers         map[string]*NDRouter
+	expectedRouters map[string]bool // Allowed router IPs and MACs
+	probes          map[string]*dadProbe
+	alerts          []NDAlert
+}
+
+// NewNDAnalyzer creates a new Neighbor Discovery analyzer
+func NewNDAnalyzer() *NDAnalyzer {
+	return &NDAnalyzer{
+		neighbors:       make(map[string]*NDNeighbor),
+		routers:         make(map[string]*NDRouter),
+		expectedRouters: make(map[string]bool),
+		probes:          make(map[string]*dadProbe),
+	}
+}
+
+// ParseRouters parses a comma separated list of IPv6 addresses and MAC
+// addresses of the routers allowed to send Router Advertisements
+func ParseRouters(spec string) ([]string, error) {
+	var routers []string
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(entry); ip != nil && ip.To4() == nil {
+			routers = append(routers, ip.String())
+			continue
+		}
+		hw, err := net.ParseMAC(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid router %q: not an IPv6 or MAC address", entry)
+		}
+		routers = append(routers, hw.String())
+	}
+
+	return routers, nil
+}
+
+// SetExpectedRouter declares the IPv6 address or MAC of a router allowed to send
+// Router Advertisements. When no router is configured, the first router seen is
+// trusted.
+func (na *NDAnalyzer) SetExpectedRouter(router string) {
+	na.expectedRouters[router] = true
+}
+
+// Reset discards the neighbor cache, routers and alerts, keeping the expected routers
+func (na *NDAnalyzer) Reset() {
+	na.neighbors = make(map[string]*NDNeighbor)
+	na.routers = make(map[string]*NDRouter)
+	na.probes = make(map[string]*dadProbe)
+	na.alerts = nil
+}
+
+// AnalyzeND updates the neighbor cache and routers with a Neighbor Discovery
+// message and checks it for rogue routers and address conflicts
+func (na *NDAnalyzer) AnalyzeND(frame *models.Frame) {
+	if frame.ICMP == nil || frame.ICMP.ND == nil {
+		return
+	}
+	nd := frame.ICMP.ND
+	source := frame.SourceIP()
+	sourceMAC, _ := frame.LinkAddresses()
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	ndInfo := map[string]interface{}{
+		"Type": frame.ICMP.TypeName,
+	}
+
+	var frameAlerts []string
+	raise := func(alertType string, ip string, mac string, description string) {
+		na.alerts = append(na.alerts, NDAlert{
+			Timestamp:   frame.Timestamp,
+			FrameID:     frame.ID,
+			Type:        alertType,
+			IP:          ip,
+			MAC:         mac,
+			Description: description,
+		})
+		frameAlerts = append(frameAlerts, fmt.Sprintf("%s: %s", alertType, description))
+	}
+
+	switch frame.ICMP.Type {
+	case models.NDRouterSolicitation:
+		ndInfo["Context"] = fmt.Sprintf("Router solicitation from %s", source)
+		if nd.SourceLinkAddress != "" {
+			na.learn(frame, source, nd.SourceLinkAddress, false)
+		}
+
+	case models.NDRouterAdvertisement:
+		mac := nd.SourceLinkAddress
+		if mac == "" {
+			mac = sourceMAC
+		}
+		router := na.updateRouter(frame, source, mac, raise)
+		na.learn(frame, source, mac, true)
+
+		ndInfo["RouterLifetime"] = nd.RouterLifetime
+		ndInfo["Preference"] = nd.RouterPreference
+		ndInfo["Prefixes"] = router.Prefixes
+		if len(nd.RDNSS) > 0 {
+			ndInfo["RDNSS"] = nd.RDNSS
+		}
+		ndInfo["Context"] = fmt.Sprintf("Router advertisement from %s, lifetime %ds%s",
+			source, nd.RouterLifetime, prefixClause(router.Prefixes, ", prefixes "))
+
+	case models.NDNeighborSolicitation:
+		ndInfo["Target"] = nd.TargetAddress
+		if frame.IsDADProbe() {
+			ndInfo["Context"] = fmt.Sprintf("Duplicate address detection for %s", nd.TargetAddress)
+			na.checkProbe(frame, nd.TargetAddress, sourceMAC, raise)
+			break
+		}
+		ndInfo["Context"] = fmt.Sprintf("Who has %s? Tell %s", nd.TargetAddress, source)
+		if nd.SourceLinkAddress != "" {
+			na.learn(frame, source, nd.SourceLinkAddress, false)
+		}
+
+	case models.NDNeighborAdvertisement:
+		mac := nd.TargetLinkAddress
+		if mac == "" {
+			mac = sourceMAC
+		}
+		ndInfo["Target"] = nd.TargetAddress
+		ndInfo["Flags"] = ndFlags(nd)
+		ndInfo["Context"] = fmt.Sprintf("%s is at %s", nd.TargetAddress, mac)
+		na.checkAdvertisement(frame, nd.TargetAddress, mac, raise)
+		na.learn(frame, nd.TargetAddress, mac, nd.Router)
+
+	case models.NDRedirect:
+		ndInfo["Target"] = nd.TargetAddress
+		ndInfo["Destination"] = nd.DestinationAddress
+		ndInfo["Context"] = fmt.Sprintf("Redirect to %s for %s", nd.TargetAddress, nd.DestinationAddress)
+		if nd.TargetLinkAddress != "" {
+			na.learn(frame, nd.TargetAddress, nd.TargetLinkAddress, false)
+		}
+	}
+
+	if len(frameAlerts) > 0 {
+		ndInfo["Alerts"] = frameAlerts
+	}
+	frame.AnalysisResults["ND"] = ndInfo
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		summary = fmt.Sprintf("%s | ND %s", summary, ndInfo["Context"])
+		if len(frameAlerts) > 0 {
+			summary += " [ALERT]"
+		}
+		frame.AnalysisResults["Summary"] = summary
+	}
+}
+
+// learn records an IPv6 to MAC binding in the neighbor cache
+func (na *NDAnalyzer) learn(frame *models.Frame, ip string, mac string, router bool) {
+	if ip == "" || ip == "::" || mac == "" {
+		return
+	}
+
+	neighbor, ok := na.neighbors[ip]
+	if !ok {
+		na.neighbors[ip] = &NDNeighbor{
+			IP:        ip,
+			MAC:       mac,
+			Router:    router,
+			FirstSeen: frame.Timestamp,
+			LastSeen:  frame.Timestamp,
+			Frames:    1,
+		}
+		return
+	}
+
+	neighbor.LastSeen = frame.Timestamp
+	neighbor.Frames++
+	neighbor.Router = neighbor.Router || router
+	if neighbor.MAC != mac {
+		neighbor.PreviousMACs = append(neighbor.PreviousMACs, neighbor.MAC)
+		neighbor.MAC = mac
+	}
+}
+
+// updateRouter records a Router Advertisement and flags routers that are not expected
+func (na *NDAnalyzer) updateRouter(frame *models.Frame, ip string, mac string, raise func(string, string, string, string)) *NDRouter {
+	nd := frame.ICMP.ND
+
+	router, ok := na.routers[ip]
+	if !ok {
+		router = &NDRouter{
+			IP:        ip,
+			MAC:       mac,
+			FirstSeen: frame.Timestamp,
+		}
+
+		// Without configured routers the first one seen is trusted
+		if len(na.expectedRouters) == 0 {
+			router.Expected = len(na.routers) == 0
+		} else {
+			router.Expected = na.expectedRouters[ip] || na.expectedRouters[mac]
+		}
+		na.routers[ip] = router
+
+		// A single alert is raised per router
+		if !router.Expected {
+			raise(NDAlertRogueRouter, ip, mac, fmt.Sprintf("%s (%s) is advertising itself as a router with lifetime %ds%s",
+				ip, mac, nd.RouterLifetime, prefixClause(ndPrefixes(nd), " and prefixes ")))
+		}
+	}
+
+	router.MAC = mac
+	router.LastSeen = frame.Timestamp
+	router.Advertisements++
+	router.Lifetime = nd.RouterLifetime
+	router.Preference = nd.RouterPreference
+	router.Managed = nd.Managed
+	router.OtherConfig = nd.OtherConfig
+	if nd.MTU != 0 {
+		router.MTU = nd.MTU
+	}
+	router.Prefixes = ndPrefixes(nd)
+	if len(nd.RDNSS) > 0 {
+		router.RDNSS = nd.RDNSS
+	}
+
+	return router
+}
+
+// checkProbe records a duplicate address detection probe and flags probes for
+// an address already bound to another MAC
+func (na *NDAnalyzer) checkProbe(frame *models.Frame, target string, mac string, raise func(string, string, string, string)) {
+	probe := &dadProbe{mac: mac, timestamp: frame.Timestamp}
+	na.probes[target] = probe
+
+	if neighbor, ok := na.neighbors[target]; ok && neighbor.MAC != mac {
+		probe.alerted = true
+		raise(NDAlertDADConflict, target, mac, fmt.Sprintf("%s probes for %s, already in use by %s", mac, target, neighbor.MAC))
+	}
+}
+
+// checkAdvertisement flags a neighbor advertisement that defends an address
+// another host has just probed for
+func (na *NDAnalyzer) checkAdvertisement(frame *models.Frame, target string, mac string, raise func(string, string, string, string)) {
+	probe, ok := na.probes[target]
+	if !ok {
+		return
+	}
+	if frame.Timestamp.Sub(probe.timestamp) > dadProbeWindow {
+		delete(na.probes, target)
+		return
+	}
+
+	if probe.mac != mac && !probe.alerted {
+		probe.alerted = true
+		raise(NDAlertDADConflict, target, mac, fmt.Sprintf("%s probed for %s, which %s advertises", probe.mac, target, mac))
+	}
+}
+
+// ndPrefixes returns the prefixes announced by a Router Advertisement
+func ndPrefixes(nd *models.NDInfo) []string {
+	prefixes := make([]string, 0, len(nd.Prefixes))
+	for _, prefix := range nd.Prefixes {
+		prefixes = append(prefixes, prefix.Prefix)
+	}
+	return prefixes
+}
+
+// prefixClause lists prefixes after an introduction, or returns an empty
+// string when a Router Advertisement announced none
+func prefixClause(prefixes []string, introduction string) string {
+	if len(prefixes) == 0 {
+		return ""
+	}
+	return introduction + strings.Join(prefixes, ", ")
+}
+
+// ndFlags returns the flags set on a Neighbor Advertisement, e.g. "R,S,O"
+func ndFlags(nd *models.NDInfo) string {
+	var flags []string
+	if nd.Router {
+		flags = append(flags, "R")
+	}
+	if nd.Solicited {
+		flags = append(flags, "S")
+	}
+	if nd.Override {
+		flags = append(flags, "O")
+	}
+	return strings.Join(flags, ",")
+}
+
+// Neighbors returns the neighbor cache sorted by IP
+func (na *NDAnalyzer) Neighbors() []*NDNeighbor {
+	neighbors := make([]*NDNeighbor, 0, len(na.neighbors))
+	for _, neighbor := range na.neighbors {
+		neighbors = append(neighbors, neighbor)
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		return string(net.ParseIP(neighbors[i].IP)) < string(net.ParseIP(neighbors[j].IP))
+	})
+	return neighbors
+}
+
+// Routers returns the routers sending Router Advertisements sorted by IP
+func (na *NDAnalyzer) Routers() []*NDRouter {
+	routers := make([]*NDRouter, 0, len(na.routers))
+	for _, router := range na.routers {
+		routers = append(routers, router)
+	}
+
+	sort.Slice(routers, func(i, j int) bool {
+		return string(net.ParseIP(routers[i].IP)) < string(net.ParseIP(routers[j].IP))
+	})
+	return routers
+}
+
+// Alerts returns the alerts raised so far in capture order
+func (na *NDAnalyzer) Alerts() []NDAlert {
+	alerts := make([]NDAlert, len(na.alerts))
+	copy(alerts, na.alerts)
+	return alerts
+}
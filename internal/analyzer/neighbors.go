@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// Neighbor is a device discovered through LLDP or CDP
+type Neighbor struct {
+	models.DiscoveryInfo
+	SourceMAC string
+	FrameVLAN uint16 // VLAN the advertisement was received on, 0 when untagged
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Frames    int
+}
+
+// NeighborAnalyzer builds the table of neighboring devices from LLDP and CDP advertisements
+type NeighborAnalyzer struct {
+	neighbors map[string]*Neighbor
+}
+
+// NewNeighborAnalyzer creates a new neighbor analyzer
+func NewNeighborAnalyzer() *NeighborAnalyzer {
+	return &NeighborAnalyzer{
+		neighbors: make(map[string]*Neighbor),
+	}
+}
+
+// Reset discards the discovered neighbors
+func (na *NeighborAnalyzer) Reset() {
+	na.neighbors = make(map[string]*Neighbor)
+}
+
+// AnalyzeDiscovery records the neighbor advertised by an LLDP or CDP frame
+func (na *NeighborAnalyzer) AnalyzeDiscovery(frame *models.Frame) {
+	discovery := frame.Discovery
+	if discovery == nil {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	// A device advertises each of its ports separately
+	key := fmt.Sprintf("%s|%s|%s", discovery.Protocol, discovery.ChassisID, discovery.PortID)
+	neighbor, ok := na.neighbors[key]
+	if !ok {
+		neighbor = &Neighbor{
+			FirstSeen: frame.Timestamp,
+		}
+		na.neighbors[key] = neighbor
+	}
+	neighbor.DiscoveryInfo = *discovery
+	neighbor.SourceMAC = frame.SourceMAC
+	neighbor.FrameVLAN = frame.VLANID()
+	neighbor.LastSeen = frame.Timestamp
+	neighbor.Frames++
+
+	name := discovery.SystemName
+	if name == "" {
+		name = discovery.ChassisID
+	}
+
+	discoveryInfo := map[string]interface{}{
+		"Protocol": discovery.Protocol,
+		"Device":   name,
+		"Port":     discovery.PortID,
+	}
+	if discovery.VLAN != 0 {
+		discoveryInfo["VLAN"] = discovery.VLAN
+	}
+	if len(discovery.ManagementAddresses) > 0 {
+		discoveryInfo["ManagementAddress"] = strings.Join(discovery.ManagementAddresses, ", ")
+	}
+	discoveryInfo["Context"] = fmt.Sprintf("This link is connected to port %s of %s", discovery.PortID, name)
+	frame.AnalysisResults["Discovery"] = discoveryInfo
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | %s %s port %s", summary, discovery.Protocol, name, discovery.PortID)
+	}
+}
+
+// Neighbors returns the discovered neighbors sorted by device name and port
+func (na *NeighborAnalyzer) Neighbors() []*Neighbor {
+	neighbors := make([]*Neighbor, 0, len(na.neighbors))
+	for _, neighbor := range na.neighbors {
+		neighbors = append(neighbors, neighbor)
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		a, b := neighbors[i], neighbors[j]
+		if a.SystemName != b.SystemName {
+			return a.SystemName < b.SystemName
+		}
+		if a.ChassisID != b.ChassisID {
+			return a.ChassisID < b.ChassisID
+		}
+		return a.PortID < b.PortID
+	})
+	return neighbors
+}
@@ -91,6 +91,22 @@ func getTrafficTypeByTID(tid int) string {
 	}
 }
 
+// getAccessCategory returns the WMM access category a traffic ID is mapped to
+func getAccessCategory(tid int) string {
+	switch tid {
+	case 1, 2:
+		return models.AccessCategoryBackground
+	case 0, 3:
+		return models.AccessCategoryBestEffort
+	case 4, 5:
+		return models.AccessCategoryVideo
+	case 6, 7:
+		return models.AccessCategoryVoice
+	default:
+		return ""
+	}
+}
+
 // getQoSPriorityExplanation returns an explanation of what a QoS priority level means
 func getQoSPriorityExplanation(priority int) string {
 	switch priority {
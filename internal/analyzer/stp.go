@@ -0,0 +1,314 @@
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// STP event types
+const (
+	STPEventRootChange     = "Root bridge change"
+	STPEventUnexpectedRoot = "Unexpected root bridge"
+	STPEventTopologyChange = "Topology change"
+	STPEventTCN            = "Topology change notification"
+)
+
+// STPEvent is a change in the spanning tree observed in the capture
+type STPEvent struct {
+	Timestamp   time.Time
+	FrameID     int64
+	VLAN        uint16
+	Type        string
+	Bridge      string
+	Description string
+	Alert       bool
+}
+
+// STPRoot is the root bridge currently elected for a VLAN
+type STPRoot struct {
+	VLAN    uint16
+	RootID  models.BridgeID
+	Since   time.Time
+	Changes int
+}
+
+// STPBridge is a bridge sending BPDUs on the captured link
+type STPBridge struct {
+	BridgeID        models.BridgeID
+	VLAN            uint16
+	Version         string
+	RootID          models.BridgeID
+	RootPathCost    uint32
+	PortID          uint16
+	PortRole        string
+	Learning        bool
+	Forwarding      bool
+	BPDUs           int
+	TCNs            int
+	TopologyChanges int
+	MSTRegion       string
+	MSTInstances    []models.MSTInstance
+	LastSeen        time.Time
+}
+
+// STPAnalyzer tracks spanning tree root bridges and topology changes
+type STPAnalyzer struct {
+	expectedRoots map[string]bool // Configured root bridge MACs
+	roots         map[uint16]*STPRoot
+	bridges       map[string]*STPBridge
+	changing      map[string]bool // Bridges currently flagging a topology change
+	events        []STPEvent
+}
+
+// NewSTPAnalyzer creates a new spanning tree analyzer
+func NewSTPAnalyzer() *STPAnalyzer {
+	return &STPAnalyzer{
+		expectedRoots: make(map[string]bool),
+		roots:         make(map[uint16]*STPRoot),
+		bridges:       make(map[string]*STPBridge),
+		changing:      make(map[string]bool),
+	}
+}
+
+// ParseBridgeMACs parses a comma separated list of bridge MAC addresses
+func ParseBridgeMACs(spec string) ([]string, error) {
+	var macs []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		mac, err := net.ParseMAC(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bridge MAC %q: %v", entry, err)
+		}
+		macs = append(macs, mac.String())
+	}
+	return macs, nil
+}
+
+// SetExpectedRoot declares a bridge MAC allowed to be the root bridge. When no
+// root is configured, the first root seen on each VLAN is trusted.
+func (sa *STPAnalyzer) SetExpectedRoot(mac string) {
+	sa.expectedRoots[mac] = true
+}
+
+// Reset discards the roots, bridges and events, keeping the expected roots
+func (sa *STPAnalyzer) Reset() {
+	sa.roots = make(map[uint16]*STPRoot)
+	sa.bridges = make(map[string]*STPBridge)
+	sa.changing = make(map[string]bool)
+	sa.events = nil
+}
+
+// AnalyzeBPDU updates the spanning tree state with a BPDU
+func (sa *STPAnalyzer) AnalyzeBPDU(frame *models.Frame) {
+	stp := frame.STP
+	if stp == nil {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	vlan := stp.PVSTVLAN
+	if vlan == 0 {
+		vlan = frame.VLANID()
+	}
+
+	stpInfo := map[string]interface{}{
+		"Version": stp.VersionName,
+		"Type":    stp.BPDUType,
+		"VLAN":    vlan,
+	}
+
+	var frameEvents []string
+	frameAlert := false
+	record := func(eventType string, bridge string, description string, alert bool) {
+		sa.events = append(sa.events, STPEvent{
+			Timestamp:   frame.Timestamp,
+			FrameID:     frame.ID,
+			VLAN:        vlan,
+			Type:        eventType,
+			Bridge:      bridge,
+			Description: description,
+			Alert:       alert,
+		})
+		frameEvents = append(frameEvents, fmt.Sprintf("%s: %s", eventType, description))
+		frameAlert = frameAlert || alert
+	}
+
+	var description string
+	if stp.BPDUType == "TCN" {
+		// TCN BPDUs carry no bridge ID, the sender is only known by its MAC
+		bridge := sa.bridge(frame.SourceMAC, vlan)
+		bridge.TCNs++
+		bridge.LastSeen = frame.Timestamp
+		record(STPEventTCN, frame.SourceMAC, fmt.Sprintf("%s reported a topology change", frame.SourceMAC), false)
+		description = "STP TCN"
+	} else {
+		sa.updateBridge(frame, vlan, record)
+		sa.updateRoot(frame, vlan, record)
+
+		stpInfo["Root"] = stp.RootID.String()
+		stpInfo["RootPathCost"] = stp.RootPathCost
+		stpInfo["Bridge"] = stpSender(stp).String()
+		if stp.PortRole != "" {
+			stpInfo["PortRole"] = stp.PortRole
+		}
+		description = fmt.Sprintf("%s root %s cost %d", stp.VersionName, stp.RootID, stp.RootPathCost)
+		if stp.TopologyChange {
+			description += " TC"
+		}
+	}
+
+	if len(frameEvents) > 0 {
+		stpInfo["Events"] = frameEvents
+	}
+	frame.AnalysisResults["STP"] = stpInfo
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		summary = fmt.Sprintf("%s | %s", summary, description)
+		if frameAlert {
+			summary += " [ALERT]"
+		}
+		frame.AnalysisResults["Summary"] = summary
+	}
+}
+
+// updateBridge records the sending bridge and counts its topology changes
+func (sa *STPAnalyzer) updateBridge(frame *models.Frame, vlan uint16, record func(string, string, string, bool)) {
+	stp := frame.STP
+	sender := stpSender(stp)
+
+	bridge := sa.bridge(sender.MAC, vlan)
+	bridge.BridgeID = sender
+	bridge.Version = stp.VersionName
+	bridge.RootID = stp.RootID
+	bridge.RootPathCost = stp.RootPathCost
+	bridge.PortID = stp.PortID
+	bridge.PortRole = stp.PortRole
+	bridge.Learning = stp.Learning
+	bridge.Forwarding = stp.Forwarding
+	bridge.BPDUs++
+	bridge.LastSeen = frame.Timestamp
+	if stp.MST != nil {
+		bridge.MSTRegion = fmt.Sprintf("%s rev %d", stp.MST.ConfigName, stp.MST.Revision)
+		bridge.MSTInstances = stp.MST.Instances
+	}
+
+	// The TC flag stays set for a while, count each change once
+	key := bridgeKey(sender.MAC, vlan)
+	if stp.TopologyChange && !sa.changing[key] {
+		bridge.TopologyChanges++
+		record(STPEventTopologyChange, sender.String(), fmt.Sprintf("%s is propagating a topology change", sender), false)
+	}
+	sa.changing[key] = stp.TopologyChange
+}
+
+// updateRoot tracks the root bridge of the VLAN and flags unexpected roots
+func (sa *STPAnalyzer) updateRoot(frame *models.Frame, vlan uint16, record func(string, string, string, bool)) {
+	rootID := frame.STP.RootID
+
+	root, ok := sa.roots[vlan]
+	if !ok {
+		sa.roots[vlan] = &STPRoot{
+			VLAN:   vlan,
+			RootID: rootID,
+			Since:  frame.Timestamp,
+		}
+		if len(sa.expectedRoots) > 0 && !sa.expectedRoots[rootID.MAC] {
+			record(STPEventUnexpectedRoot, rootID.String(), fmt.Sprintf("%s is root of VLAN %d but is not an expected root", rootID, vlan), true)
+		}
+		return
+	}
+
+	if root.RootID == rootID {
+		return
+	}
+
+	previous := root.RootID
+	root.RootID = rootID
+	root.Since = frame.Timestamp
+	root.Changes++
+
+	// Without configured roots any change of root is unexpected
+	unexpected := len(sa.expectedRoots) == 0 || !sa.expectedRoots[rootID.MAC]
+	eventType := STPEventRootChange
+	if unexpected {
+		eventType = STPEventUnexpectedRoot
+	}
+	record(eventType, rootID.String(), fmt.Sprintf("root of VLAN %d moved from %s to %s (advertised by %s)", vlan, previous, rootID, frame.SourceMAC), unexpected)
+}
+
+// bridge returns the bridge entry for a MAC and VLAN, creating it if needed
+func (sa *STPAnalyzer) bridge(mac string, vlan uint16) *STPBridge {
+	key := bridgeKey(mac, vlan)
+	bridge, ok := sa.bridges[key]
+	if !ok {
+		bridge = &STPBridge{
+			BridgeID: models.BridgeID{MAC: mac},
+			VLAN:     vlan,
+		}
+		sa.bridges[key] = bridge
+	}
+	return bridge
+}
+
+// bridgeKey identifies a bridge on a VLAN
+func bridgeKey(mac string, vlan uint16) string {
+	return mac + "|" + strconv.Itoa(int(vlan))
+}
+
+// stpSender returns the ID of the bridge that sent a BPDU. In MST BPDUs the
+// bridge ID field holds the CIST regional root and the sender is the CIST bridge.
+func stpSender(stp *models.STPInfo) models.BridgeID {
+	if stp.MST != nil {
+		return stp.MST.CISTBridgeID
+	}
+	return stp.BridgeID
+}
+
+// Roots returns the root bridge of each VLAN sorted by VLAN
+func (sa *STPAnalyzer) Roots() []STPRoot {
+	roots := make([]STPRoot, 0, len(sa.roots))
+	for _, root := range sa.roots {
+		roots = append(roots, *root)
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].VLAN < roots[j].VLAN
+	})
+	return roots
+}
+
+// Bridges returns the bridges sending BPDUs sorted by VLAN and bridge ID
+func (sa *STPAnalyzer) Bridges() []*STPBridge {
+	bridges := make([]*STPBridge, 0, len(sa.bridges))
+	for _, bridge := range sa.bridges {
+		bridges = append(bridges, bridge)
+	}
+
+	sort.Slice(bridges, func(i, j int) bool {
+		if bridges[i].VLAN != bridges[j].VLAN {
+			return bridges[i].VLAN < bridges[j].VLAN
+		}
+		return bridges[i].BridgeID.String() < bridges[j].BridgeID.String()
+	})
+	return bridges
+}
+
+// Events returns the spanning tree events in capture order
+func (sa *STPAnalyzer) Events() []STPEvent {
+	events := make([]STPEvent, len(sa.events))
+	copy(events, sa.events)
+	return events
+}
@@ -0,0 +1,385 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/reassembly"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// maxStreamBytes bounds the payload kept for each TCP stream, later bytes are only counted
+	maxStreamBytes = 1 << 20
+	// streamIdleTimeout is the inactivity after which the reassembler releases a connection
+	streamIdleTimeout = 2 * time.Minute
+	// maxBoundedStreams bounds the streams kept by a bounded analyzer, the least
+	// recently active are released beyond it
+	maxBoundedStreams = 1000
+	// maxBoundedStreamBytes bounds the payload kept for each stream by a bounded analyzer
+	maxBoundedStreamBytes = 64 << 10
+)
+
+// StreamChunk is a run of reassembled payload sent in one direction
+type StreamChunk struct {
+	FromClient bool
+	FrameID    int64 // Frame that carried the first byte of the chunk
+	Timestamp  time.Time
+	Skipped    int // Bytes lost before the chunk, -1 when the start of the stream was not captured
+	Data       []byte
+}
+
+// TCPStream is a reassembled TCP connection. The client is the endpoint that sent
+// the first captured segment, usually the SYN.
+type TCPStream struct {
+	ID          int
+	Client      string // ip:port
+	Server      string // ip:port
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	Frames      int
+	ClientBytes int // Reassembled payload bytes sent by the client
+	ServerBytes int
+	Chunks      []StreamChunk
+	Truncated   bool // Payload beyond the stream limit was counted but not kept
+	Closed      bool
+
+	stored   int  // Payload bytes kept in Chunks
+	released bool // Dropped by a bounded analyzer
+}
+
+// Payload returns the reassembled bytes of one direction, or both interleaved
+// in capture order when client and server are both true
+func (s *TCPStream) Payload(client, server bool) []byte {
+	var data []byte
+	for _, chunk := range s.Chunks {
+		if (chunk.FromClient && client) || (!chunk.FromClient && server) {
+			data = append(data, chunk.Data...)
+		}
+	}
+	return data
+}
+
+// StreamConsumer receives the payload of TCP streams as it is reassembled, for
+// application protocols whose messages span several segments
+type StreamConsumer interface {
+	// ConsumeStream is called with each run of in-order payload of a direction,
+	// whose Data is only valid during the call. frame is the frame whose segment
+	// completed the data, nil when the data was released by a flush.
+	ConsumeStream(stream *TCPStream, chunk StreamChunk, frame *models.Frame)
+	// ReleaseStream is called when a bounded analyzer drops a stream, which
+	// receives no more payload
+	ReleaseStream(stream *TCPStream)
+}
+
+// StreamAnalyzer reassembles TCP connections with gopacket's reassembly package
+type StreamAnalyzer struct {
+	assembler *reassembly.Assembler
+	streams   map[int]*TCPStream
+	byKey     map[string]*TCPStream
+	nextID    int
+	bounded   bool // Cap the streams and their payload, see SetBounded
+	consumers []StreamConsumer
+	current   *TCPStream    // Stream of the segment being assembled, picked up by New
+	source    string        // Source endpoint of the segment being assembled
+	frame     *models.Frame // Frame of the segment being assembled
+	lastSeen  time.Time
+	lastFlush time.Time
+}
+
+// NewStreamAnalyzer creates a new TCP stream analyzer
+func NewStreamAnalyzer() *StreamAnalyzer {
+	sa := &StreamAnalyzer{}
+	sa.Reset()
+	return sa
+}
+
+// Reset discards the reassembled streams
+func (sa *StreamAnalyzer) Reset() {
+	sa.assembler = reassembly.NewAssembler(reassembly.NewStreamPool(sa))
+	sa.streams = make(map[int]*TCPStream)
+	sa.byKey = make(map[string]*TCPStream)
+	sa.nextID = 0
+	sa.current = nil
+	sa.lastSeen = time.Time{}
+	sa.lastFlush = time.Time{}
+}
+
+// SetBounded caps the streams kept and the payload of each one, releasing the
+// least recently active streams, so long unattended captures use bounded memory
+func (sa *StreamAnalyzer) SetBounded(bounded bool) {
+	sa.bounded = bounded
+}
+
+// AddConsumer registers a consumer of the reassembled payload
+func (sa *StreamAnalyzer) AddConsumer(consumer StreamConsumer) {
+	sa.consumers = append(sa.consumers, consumer)
+}
+
+// AnalyzeStream feeds a TCP segment to the reassembler and tags the frame with its stream
+func (sa *StreamAnalyzer) AnalyzeStream(frame *models.Frame) {
+	netFlow, tcp, ok := decodeTCPSegment(frame)
+	if !ok {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	src, dst := frame.Endpoints()
+	key := streamKey(src, dst)
+
+	// A SYN on a closed connection starts a new stream with the same endpoints
+	stream, ok := sa.byKey[key]
+	if !ok || (stream.Closed && tcp.SYN && !tcp.ACK) {
+		stream = sa.newStream(src, dst, frame.Timestamp)
+		sa.byKey[key] = stream
+	}
+	stream.Frames++
+	stream.LastSeen = frame.Timestamp
+	if frame.Timestamp.After(sa.lastSeen) {
+		sa.lastSeen = frame.Timestamp
+	}
+
+	ctx := &streamContext{
+		CaptureInfo: gopacket.CaptureInfo{
+			Timestamp:     frame.Timestamp,
+			CaptureLength: len(frame.RawData),
+			Length:        frame.Length,
+			AncillaryData: []interface{}{frame.ID},
+		},
+	}
+	sa.current, sa.source, sa.frame = stream, src, frame
+	sa.assembler.AssembleWithContext(netFlow, tcp, ctx)
+	sa.current, sa.frame = nil, nil
+
+	// Release idle connections so the reassembler does not hold them forever
+	if sa.lastFlush.IsZero() {
+		sa.lastFlush = frame.Timestamp
+	} else if frame.Timestamp.Sub(sa.lastFlush) > streamIdleTimeout {
+		sa.assembler.FlushCloseOlderThan(frame.Timestamp.Add(-streamIdleTimeout))
+		sa.lastFlush = frame.Timestamp
+	}
+
+	frame.AnalysisResults["TCPStream"] = stream.ID
+}
+
+// New creates the reassembly stream of a new connection, implementing reassembly.StreamFactory
+func (sa *StreamAnalyzer) New(netFlow, tcpFlow gopacket.Flow, tcp *layers.TCP, ac reassembly.AssemblerContext) reassembly.Stream {
+	stream := sa.current
+	if stream == nil {
+		// Only reachable if the assembler is fed outside of AnalyzeStream
+		stream = sa.newStream("", "", time.Time{})
+	}
+
+	// A connection released while idle restarts with whichever side speaks first
+	return &reassemblyStream{analyzer: sa, stream: stream, client: sa.source, reversed: stream.Client != "" && sa.source != stream.Client}
+}
+
+// newStream adds a stream, first releasing the least recently active streams
+// when a bounded analyzer is full
+func (sa *StreamAnalyzer) newStream(client, server string, timestamp time.Time) *TCPStream {
+	if sa.bounded && len(sa.streams) >= maxBoundedStreams {
+		sa.release(len(sa.streams) - maxBoundedStreams*9/10)
+	}
+
+	stream := &TCPStream{
+		ID:        sa.nextID,
+		Client:    client,
+		Server:    server,
+		FirstSeen: timestamp,
+	}
+	sa.nextID++
+	sa.streams[stream.ID] = stream
+	return stream
+}
+
+// release drops the n least recently active streams, closed streams first
+func (sa *StreamAnalyzer) release(n int) {
+	streams := make([]*TCPStream, 0, len(sa.streams))
+	for _, stream := range sa.streams {
+		streams = append(streams, stream)
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		if streams[i].Closed != streams[j].Closed {
+			return streams[i].Closed
+		}
+		return streams[i].LastSeen.Before(streams[j].LastSeen)
+	})
+
+	for _, stream := range streams[:n] {
+		stream.released = true
+		stream.Chunks = nil
+		delete(sa.streams, stream.ID)
+		if key := streamKey(stream.Client, stream.Server); sa.byKey[key] == stream {
+			delete(sa.byKey, key)
+		}
+		for _, consumer := range sa.consumers {
+			consumer.ReleaseStream(stream)
+		}
+	}
+}
+
+// Streams returns the reassembled streams ordered by ID
+func (sa *StreamAnalyzer) Streams() []*TCPStream {
+	streams := make([]*TCPStream, 0, len(sa.streams))
+	for _, stream := range sa.streams {
+		streams = append(streams, stream)
+	}
+	sort.Slice(streams, func(i, j int) bool { return streams[i].ID < streams[j].ID })
+	return streams
+}
+
+// Stream returns a stream by ID
+func (sa *StreamAnalyzer) Stream(id int) (*TCPStream, bool) {
+	stream, ok := sa.streams[id]
+	return stream, ok
+}
+
+// Flush delivers the data every connection buffers behind missing segments,
+// once no more segments will arrive
+func (sa *StreamAnalyzer) Flush() {
+	// Segments that never arrived would otherwise hold back the data behind them
+	sa.assembler.FlushWithOptions(reassembly.FlushOptions{T: sa.lastSeen.Add(time.Second)})
+}
+
+// StreamOf returns the stream a frame belongs to, or its innermost tunneled frame
+func (sa *StreamAnalyzer) StreamOf(frame *models.Frame) (*TCPStream, bool) {
+	for _, f := range frame.Frames() {
+		if id, ok := f.AnalysisResults["TCPStream"].(int); ok {
+			return sa.Stream(id)
+		}
+	}
+	return nil, false
+}
+
+// streamContext passes the capture info of a frame, with its ID, to the reassembler
+type streamContext struct {
+	gopacket.CaptureInfo
+}
+
+// GetCaptureInfo implements reassembly.AssemblerContext
+func (c *streamContext) GetCaptureInfo() gopacket.CaptureInfo {
+	return c.CaptureInfo
+}
+
+// reassemblyStream receives the reassembled data of a connection
+type reassemblyStream struct {
+	analyzer *StreamAnalyzer
+	stream   *TCPStream
+	client   string // Endpoint the reassembler takes as client
+	reversed bool   // The reassembler's client is the stream's server
+}
+
+// Accept takes every segment, also when the connection started before the capture
+func (rs *reassemblyStream) Accept(tcp *layers.TCP, ci gopacket.CaptureInfo, dir reassembly.TCPFlowDirection, nextSeq reassembly.Sequence, start *bool, ac reassembly.AssemblerContext) bool {
+	*start = true
+	return true
+}
+
+// ReassembledSG appends the in-order data of a direction to the stream
+func (rs *reassemblyStream) ReassembledSG(sg reassembly.ScatterGather, ac reassembly.AssemblerContext) {
+	length, _ := sg.Lengths()
+	if length == 0 {
+		return
+	}
+
+	// A connection whose stream was released continues in the stream that
+	// replaced it
+	if rs.stream.released {
+		current := rs.analyzer.current
+		if current == nil || current.released {
+			return
+		}
+		rs.stream, rs.reversed = current, rs.client != current.Client
+	}
+
+	dir, _, _, skip := sg.Info()
+	fromClient := (dir == reassembly.TCPDirClientToServer) != rs.reversed
+	stream := rs.stream
+	if fromClient {
+		stream.ClientBytes += length
+	} else {
+		stream.ServerBytes += length
+	}
+
+	ci := sg.CaptureInfo(0)
+	chunk := StreamChunk{
+		FromClient: fromClient,
+		Timestamp:  ci.Timestamp,
+		Skipped:    skip,
+	}
+	if len(ci.AncillaryData) > 0 {
+		chunk.FrameID, _ = ci.AncillaryData[0].(int64)
+	}
+
+	// Consumers see every byte, also beyond what the stream keeps
+	if consumers := rs.analyzer.consumers; len(consumers) > 0 {
+		consumed := chunk
+		consumed.Data = sg.Fetch(length)
+		for _, consumer := range consumers {
+			consumer.ConsumeStream(stream, consumed, rs.analyzer.frame)
+		}
+	}
+
+	limit := maxStreamBytes
+	if rs.analyzer.bounded {
+		limit = maxBoundedStreamBytes
+	}
+	if stream.stored+length > limit {
+		stream.Truncated = true
+		length = limit - stream.stored
+		if length <= 0 {
+			return
+		}
+	}
+	chunk.Data = append([]byte(nil), sg.Fetch(length)...)
+	stream.stored += length
+
+	// Consecutive data in the same direction extends the previous chunk
+	if n := len(stream.Chunks); n > 0 && skip == 0 && stream.Chunks[n-1].FromClient == fromClient {
+		stream.Chunks[n-1].Data = append(stream.Chunks[n-1].Data, chunk.Data...)
+		return
+	}
+	stream.Chunks = append(stream.Chunks, chunk)
+}
+
+// ReassemblyComplete marks the stream closed once both directions ended
+func (rs *reassemblyStream) ReassemblyComplete(ac reassembly.AssemblerContext) bool {
+	rs.stream.Closed = true
+	return true
+}
+
+// decodeTCPSegment decodes the network flow and TCP header of a frame from its raw data,
+// which also works for frames loaded from disk without their original packet
+func decodeTCPSegment(frame *models.Frame) (gopacket.Flow, *layers.TCP, bool) {
+	if frame.TCP == nil || frame.NetworkOffset < 0 || frame.NetworkOffset >= len(frame.RawData) {
+		return gopacket.Flow{}, nil, false
+	}
+
+	firstLayer := layers.LayerTypeIPv4
+	if frame.IPv4 == nil {
+		firstLayer = layers.LayerTypeIPv6
+	}
+
+	packet := gopacket.NewPacket(frame.RawData[frame.NetworkOffset:], firstLayer, gopacket.DecodeOptions{NoCopy: true, Lazy: true})
+	network := packet.NetworkLayer()
+	tcp, ok := packet.Layer(layers.LayerTypeTCP).(*layers.TCP)
+	if network == nil || !ok {
+		return gopacket.Flow{}, nil, false
+	}
+
+	return network.NetworkFlow(), tcp, true
+}
+
+// streamKey identifies a connection regardless of the direction of the segment
+func streamKey(src, dst string) string {
+	if src > dst {
+		src, dst = dst, src
+	}
+	return fmt.Sprintf("%s|%s", src, dst)
+}
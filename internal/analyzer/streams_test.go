@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/internal/parser"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// tcpSegmentFrame builds and parses an Ethernet frame carrying a TCP segment
+func tcpSegmentFrame(t *testing.T, id int64, timestamp time.Time, client bool, port uint16, seq uint32, syn bool, payload []byte) *models.Frame {
+	t.Helper()
+	src, dst := net.IP{10, 0, 0, 1}, net.IP{10, 0, 0, 2}
+	srcPort, dstPort := layers.TCPPort(port), layers.TCPPort(80)
+	if !client {
+		src, dst, srcPort, dstPort = dst, src, dstPort, srcPort
+	}
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0, 1, 2, 3, 4, 5},
+		DstMAC:       net.HardwareAddr{0, 1, 2, 3, 4, 6},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: src, DstIP: dst}
+	tcp := &layers.TCP{SrcPort: srcPort, DstPort: dstPort, Seq: seq, SYN: syn, ACK: !syn, PSH: len(payload) > 0, Window: 65535}
+	tcp.SetNetworkLayerForChecksum(ip)
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, ethernet, ip, tcp, gopacket.Payload(payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	frame := &models.Frame{
+		ID:             id,
+		Timestamp:      timestamp,
+		RawData:        data,
+		Length:         len(data),
+		OriginalPacket: gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default),
+	}
+	parser.NewFrameParser().ParseFrame(frame)
+	return frame
+}
+
+func TestBoundedStreams(t *testing.T) {
+	fa := NewFrameAnalyzer()
+	fa.SetBounded(true)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	id := int64(0)
+	send := func(timestamp time.Time, client bool, port uint16, seq uint32, syn bool, payload []byte) *models.Frame {
+		id++
+		frame := tcpSegmentFrame(t, id, timestamp, client, port, seq, syn, payload)
+		fa.AnalyzeFrame(frame)
+		return frame
+	}
+
+	// A long lived connection whose stream becomes the least recently active
+	request := []byte("GET / HTTP/1.1\r\nHost: example\r\n\r\n")
+	send(start, true, 40000, 1000, true, nil)
+	send(start, true, 40000, 1001, false, request)
+
+	// Later connections push it out of the analyzer
+	for i := 0; i < maxBoundedStreams; i++ {
+		timestamp := start.Add(time.Duration(i+1) * time.Millisecond)
+		port := uint16(41000 + i)
+		send(timestamp, true, port, 5000, true, nil)
+		send(timestamp, true, port, 5001, false, request)
+	}
+
+	streams := fa.Streams().Streams()
+	if len(streams) > maxBoundedStreams || streams[0].ID == 0 {
+		t.Fatalf("bounded analyzer keeps %d streams, the first with ID %d", len(streams), streams[0].ID)
+	}
+	if len(fa.HTTP().connections) > maxBoundedStreams || len(fa.TLS().connections) > maxBoundedStreams {
+		t.Fatalf("consumers keep %d HTTP and %d TLS connections", len(fa.HTTP().connections), len(fa.TLS().connections))
+	}
+	if _, ok := fa.Streams().Stream(0); ok {
+		t.Fatal("released stream is still returned")
+	}
+
+	// The connection continues in a new stream
+	more := []byte("GET /next HTTP/1.1\r\nHost: example\r\n\r\n")
+	frame := send(start.Add(2*time.Second), true, 40000, 1001+uint32(len(request)), false, more)
+	stream, ok := fa.Streams().StreamOf(frame)
+	if !ok || stream.ID == 0 {
+		t.Fatalf("continued connection has no new stream: %v", stream)
+	}
+	if payload := stream.Payload(true, false); !bytes.Equal(payload, more) {
+		t.Fatalf("new stream payload = %q, want %q", payload, more)
+	}
+
+	// Payload beyond the bounded limit is counted but not kept
+	chunk := bytes.Repeat([]byte{'x'}, 1400)
+	seq := uint32(9001)
+	send(start.Add(3*time.Second), true, 50000, 9000, true, nil)
+	var last *models.Frame
+	for i := 0; i < 100; i++ {
+		last = send(start.Add(3*time.Second), true, 50000, seq, false, chunk)
+		seq += uint32(len(chunk))
+	}
+	stream, ok = fa.Streams().StreamOf(last)
+	if !ok || !stream.Truncated || stream.ClientBytes != 100*len(chunk) || len(stream.Payload(true, true)) != maxBoundedStreamBytes {
+		t.Fatalf("stream counted %d bytes and kept %d", stream.ClientBytes, len(stream.Payload(true, true)))
+	}
+}
+
+func TestBoundedTCPConnections(t *testing.T) {
+	ta := NewTCPAnalyzer()
+	ta.SetBounded(true)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i <= maxBoundedConnections; i++ {
+		timestamp := start.Add(time.Duration(i) * time.Millisecond)
+		ta.AnalyzeTCP(tcpSegmentFrame(t, int64(i+1), timestamp, true, uint16(10000+i), 1000, true, nil))
+	}
+
+	if len(ta.connections) > maxBoundedConnections || len(ta.states) != len(ta.connections) {
+		t.Fatalf("bounded analyzer keeps %d connections and %d states", len(ta.connections), len(ta.states))
+	}
+	for _, conn := range ta.Connections() {
+		if conn.Client == "10.0.0.1:10000" {
+			t.Fatal("least recently active connection was kept")
+		}
+	}
+}
+
+func TestTCPRetransmissionOfAcknowledgedData(t *testing.T) {
+	ta := NewTCPAnalyzer()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	id := int64(0)
+	send := func(offset time.Duration, client bool, seq, ack uint32, syn bool, payload []byte) *models.Frame {
+		id++
+		frame := tcpSegmentFrame(t, id, start.Add(offset), client, 40000, seq, syn, payload)
+		frame.TCP.Ack = ack
+		frame.TCP.Flags.ACK = ack != 0
+		ta.AnalyzeTCP(frame)
+		return frame
+	}
+
+	data := bytes.Repeat([]byte{'x'}, 100)
+	send(0, true, 1000, 0, true, nil)
+	send(time.Millisecond, false, 5000, 1001, true, nil)
+	send(1500*time.Microsecond, true, 1001, 5001, false, nil)
+	send(2*time.Millisecond, true, 1001, 5001, false, data)
+	send(2500*time.Microsecond, false, 5001, 1101, false, nil)
+
+	// Sent again right after the server acknowledged it, well within the
+	// out-of-order threshold
+	frame := send(3*time.Millisecond, true, 1001, 5001, false, data)
+	analysis, _ := frame.AnalysisResults["TCPAnalysis"].(map[string]interface{})
+	findings, _ := analysis["Findings"].([]string)
+	if len(findings) != 1 || findings[0] != TCPFindingSpuriousRetransmission {
+		t.Fatalf("findings = %v, want %q", findings, TCPFindingSpuriousRetransmission)
+	}
+	conn := ta.Connections()[0]
+	if conn.Retransmissions != 1 || conn.OutOfOrder != 0 {
+		t.Fatalf("connection counted %d retransmissions and %d out-of-order segments", conn.Retransmissions, conn.OutOfOrder)
+	}
+}
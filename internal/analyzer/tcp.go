@@ -0,0 +1,428 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// defaultOutOfOrderThreshold separates out-of-order segments from retransmissions
+	// until the connection has an RTT sample
+	defaultOutOfOrderThreshold = 3 * time.Millisecond
+	// maxTrackedSegments bounds the unacknowledged segments kept per direction
+	maxTrackedSegments = 256
+	// maxBoundedConnections bounds the connections kept by a bounded analyzer,
+	// the least recently active are dropped beyond it
+	maxBoundedConnections = 10000
+	// tcpOptionWindowScale is the TCP option kind of the window scale option
+	tcpOptionWindowScale = 3
+)
+
+// TCP expert findings
+const (
+	TCPFindingRetransmission         = "Retransmission"
+	TCPFindingFastRetransmission     = "Fast retransmission"
+	TCPFindingSpuriousRetransmission = "Spurious retransmission"
+	TCPFindingOutOfOrder             = "Out-of-order"
+	TCPFindingLostSegment            = "Previous segment not captured"
+	TCPFindingDupAck                 = "Duplicate ACK"
+	TCPFindingZeroWindow             = "Zero window"
+	TCPFindingWindowFull             = "Window full"
+	TCPFindingKeepAlive              = "Keep-alive"
+)
+
+// TCPConnection contains the expert analysis counters of a TCP connection
+type TCPConnection struct {
+	Client              string // ip:port of the endpoint that sent the SYN or the first segment
+	Server              string
+	FirstSeen           time.Time
+	LastSeen            time.Time
+	Frames              int
+	Retransmissions     int // Including fast and spurious retransmissions
+	FastRetransmissions int
+	OutOfOrder          int
+	LostSegments        int
+	DupAcks             int
+	ZeroWindows         int
+	WindowFull          int
+	RTTSamples          int
+	MinRTT              time.Duration
+	MaxRTT              time.Duration
+	TotalRTT            time.Duration
+	HandshakeLatency    time.Duration // From the SYN to the client's ACK of the SYN-ACK, 0 when not seen
+}
+
+// AverageRTT returns the mean of the RTT samples
+func (c *TCPConnection) AverageRTT() time.Duration {
+	if c.RTTSamples == 0 {
+		return 0
+	}
+	return c.TotalRTT / time.Duration(c.RTTSamples)
+}
+
+// Problems returns the number of findings that indicate loss or stalls
+func (c *TCPConnection) Problems() int {
+	return c.Retransmissions + c.OutOfOrder + c.LostSegments + c.DupAcks + c.ZeroWindows + c.WindowFull
+}
+
+// tcpSegment is a segment waiting to be acknowledged
+type tcpSegment struct {
+	seq           uint32
+	end           uint32
+	timestamp     time.Time
+	retransmitted bool
+}
+
+// tcpHalf is the state of one direction of a connection
+type tcpHalf struct {
+	seen        bool
+	nextSeq     uint32    // Sequence number following the highest byte sent
+	lastAdvance time.Time // When nextSeq last moved forward
+	ackSeen     bool
+	lastAck     uint32
+	lastWindow  uint16
+	dupAcks     int
+	windowScale int // Shift announced in the SYN, -1 when absent
+	segments    []tcpSegment
+}
+
+// tcpState is the analysis state of a connection
+type tcpState struct {
+	conn      *TCPConnection
+	client    tcpHalf
+	server    tcpHalf
+	synTime   time.Time
+	clientISN uint32
+	synAck    bool
+	handshake bool // The handshake latency was measured
+}
+
+// TCPAnalyzer performs TCP expert analysis: retransmissions, duplicate ACKs,
+// out-of-order segments, window problems, RTT and handshake latency
+type TCPAnalyzer struct {
+	states      map[string]*tcpState
+	connections []*TCPConnection
+	bounded     bool // Cap the connections, see SetBounded
+}
+
+// NewTCPAnalyzer creates a new TCP analyzer
+func NewTCPAnalyzer() *TCPAnalyzer {
+	return &TCPAnalyzer{
+		states: make(map[string]*tcpState),
+	}
+}
+
+// Reset discards the connection state and statistics
+func (ta *TCPAnalyzer) Reset() {
+	ta.states = make(map[string]*tcpState)
+	ta.connections = nil
+}
+
+// SetBounded caps the connections kept, dropping the least recently active
+// ones, so long unattended captures use bounded memory
+func (ta *TCPAnalyzer) SetBounded(bounded bool) {
+	ta.bounded = bounded
+}
+
+// AnalyzeTCP analyzes a TCP segment against the state of its connection
+func (ta *TCPAnalyzer) AnalyzeTCP(frame *models.Frame) {
+	tcp := frame.TCP
+	if tcp == nil {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	src, dst := frame.Endpoints()
+	key := streamKey(src, dst)
+	flags := tcp.Flags
+
+	// A new SYN starts a new connection over the same endpoints
+	state, ok := ta.states[key]
+	if !ok || (flags.SYN && !flags.ACK && (state.synTime.IsZero() || tcp.Seq != state.clientISN)) {
+		if ta.bounded && len(ta.connections) >= maxBoundedConnections {
+			ta.drop(len(ta.connections) - maxBoundedConnections*9/10)
+		}
+		state = &tcpState{
+			conn: &TCPConnection{
+				Client:    src,
+				Server:    dst,
+				FirstSeen: frame.Timestamp,
+			},
+			client: tcpHalf{windowScale: -1},
+			server: tcpHalf{windowScale: -1},
+		}
+		ta.states[key] = state
+		ta.connections = append(ta.connections, state.conn)
+	}
+	conn := state.conn
+	conn.Frames++
+	conn.LastSeen = frame.Timestamp
+
+	half, reverse := &state.client, &state.server
+	if src != conn.Client {
+		half, reverse = &state.server, &state.client
+	}
+
+	var findings []string
+	var rtt time.Duration
+	handshake := false
+	now := frame.Timestamp
+
+	segLen := uint32(tcp.PayloadLength)
+	if flags.SYN {
+		segLen++
+		half.windowScale = windowScaleOption(tcp)
+		if !flags.ACK {
+			if state.synTime.IsZero() {
+				state.synTime = now
+				state.clientISN = tcp.Seq
+			}
+		} else {
+			state.synAck = true
+		}
+	}
+	if flags.FIN {
+		segLen++
+	}
+	end := tcp.Seq + segLen
+
+	// Sequence analysis of the segment
+	if !half.seen {
+		half.seen = true
+		half.nextSeq = end
+		half.lastAdvance = now
+		half.track(tcpSegment{seq: tcp.Seq, end: end, timestamp: now})
+	} else if !flags.RST {
+		switch {
+		case segLen <= 1 && tcp.PayloadLength <= 1 && !flags.FIN && tcp.Seq == half.nextSeq-1:
+			findings = append(findings, TCPFindingKeepAlive)
+		case segLen == 0:
+			// Pure ACKs do not consume sequence space
+		case seqAfter(tcp.Seq, half.nextSeq):
+			findings = append(findings, TCPFindingLostSegment)
+			conn.LostSegments++
+			half.advance(end, now)
+			half.track(tcpSegment{seq: tcp.Seq, end: end, timestamp: now})
+		case seqBefore(tcp.Seq, half.nextSeq):
+			sent := half.markRetransmitted(tcp.Seq)
+			switch {
+			case reverse.dupAcks >= 2 && reverse.lastAck == tcp.Seq:
+				findings = append(findings, TCPFindingFastRetransmission)
+				conn.FastRetransmissions++
+				conn.Retransmissions++
+			case reverse.ackSeen && !seqAfter(end, reverse.lastAck):
+				// The peer already acknowledged the whole segment, which was
+				// dropped from the tracked segments, so it is not out of order
+				findings = append(findings, TCPFindingSpuriousRetransmission)
+				conn.Retransmissions++
+			case !sent && now.Sub(half.lastAdvance) < state.outOfOrderThreshold():
+				findings = append(findings, TCPFindingOutOfOrder)
+				conn.OutOfOrder++
+			default:
+				findings = append(findings, TCPFindingRetransmission)
+				conn.Retransmissions++
+			}
+			if seqAfter(end, half.nextSeq) {
+				half.advance(end, now)
+			}
+		default:
+			half.advance(end, now)
+			half.track(tcpSegment{seq: tcp.Seq, end: end, timestamp: now})
+		}
+	}
+
+	// Window analysis
+	if tcp.Window == 0 && !flags.SYN && !flags.FIN && !flags.RST {
+		findings = append(findings, TCPFindingZeroWindow)
+		conn.ZeroWindows++
+	}
+	if tcp.PayloadLength > 0 && reverse.ackSeen && end == reverse.lastAck+state.scaledWindow(reverse) {
+		findings = append(findings, TCPFindingWindowFull)
+		conn.WindowFull++
+	}
+
+	// Acknowledgement analysis
+	if flags.ACK {
+		pure := tcp.PayloadLength == 0 && !flags.SYN && !flags.FIN && !flags.RST
+		switch {
+		case half.ackSeen && pure && tcp.Ack == half.lastAck && tcp.Window == half.lastWindow &&
+			reverse.seen && seqBefore(tcp.Ack, reverse.nextSeq):
+			half.dupAcks++
+			findings = append(findings, fmt.Sprintf("%s #%d", TCPFindingDupAck, half.dupAcks))
+			conn.DupAcks++
+		case tcp.Ack != half.lastAck:
+			half.dupAcks = 0
+		}
+
+		// Karn's algorithm: only segments sent once yield RTT samples
+		if segment, ok := reverse.acknowledge(tcp.Ack); ok && !segment.retransmitted {
+			rtt = now.Sub(segment.timestamp)
+			conn.addRTT(rtt)
+		}
+
+		// The client's ACK of the SYN-ACK completes the handshake
+		if !state.handshake && state.synAck && !state.synTime.IsZero() && half == &state.client && !flags.SYN {
+			state.handshake = true
+			handshake = true
+			conn.HandshakeLatency = now.Sub(state.synTime)
+		}
+
+		half.ackSeen = true
+		half.lastAck = tcp.Ack
+		half.lastWindow = tcp.Window
+	}
+
+	if len(findings) == 0 && rtt == 0 && !handshake {
+		return
+	}
+
+	tcpInfo := map[string]interface{}{}
+	if len(findings) > 0 {
+		tcpInfo["Findings"] = findings
+	}
+	if rtt > 0 {
+		tcpInfo["RTT"] = rtt.String()
+	}
+	if handshake {
+		tcpInfo["HandshakeLatency"] = conn.HandshakeLatency.String()
+	}
+	frame.AnalysisResults["TCPAnalysis"] = tcpInfo
+
+	if len(findings) > 0 {
+		if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+			frame.AnalysisResults["Summary"] = fmt.Sprintf("%s [%s]", summary, strings.Join(findings, ", "))
+		}
+	}
+}
+
+// Connections returns the analyzed connections, those with the most problems first
+func (ta *TCPAnalyzer) Connections() []TCPConnection {
+	connections := make([]TCPConnection, 0, len(ta.connections))
+	for _, conn := range ta.connections {
+		connections = append(connections, *conn)
+	}
+
+	sort.SliceStable(connections, func(i, j int) bool {
+		return connections[i].Problems() > connections[j].Problems()
+	})
+
+	return connections
+}
+
+// drop forgets the n least recently active connections
+func (ta *TCPAnalyzer) drop(n int) {
+	sort.SliceStable(ta.connections, func(i, j int) bool {
+		return ta.connections[i].LastSeen.Before(ta.connections[j].LastSeen)
+	})
+	for _, conn := range ta.connections[:n] {
+		if key := streamKey(conn.Client, conn.Server); ta.states[key] != nil && ta.states[key].conn == conn {
+			delete(ta.states, key)
+		}
+	}
+	ta.connections = append([]*TCPConnection(nil), ta.connections[n:]...)
+}
+
+// addRTT accounts an RTT sample
+func (c *TCPConnection) addRTT(rtt time.Duration) {
+	if c.RTTSamples == 0 || rtt < c.MinRTT {
+		c.MinRTT = rtt
+	}
+	if rtt > c.MaxRTT {
+		c.MaxRTT = rtt
+	}
+	c.RTTSamples++
+	c.TotalRTT += rtt
+}
+
+// outOfOrderThreshold returns how late a segment may fill a gap and still count as out-of-order
+func (s *tcpState) outOfOrderThreshold() time.Duration {
+	if s.conn.RTTSamples > 0 && s.conn.MinRTT > defaultOutOfOrderThreshold {
+		return s.conn.MinRTT
+	}
+	return defaultOutOfOrderThreshold
+}
+
+// scaledWindow returns the receive window last advertised by a direction
+func (s *tcpState) scaledWindow(half *tcpHalf) uint32 {
+	window := uint32(half.lastWindow)
+	// Scaling is only in effect when both sides announced it
+	if s.client.windowScale >= 0 && s.server.windowScale >= 0 {
+		window <<= uint(half.windowScale)
+	}
+	return window
+}
+
+// advance moves the next expected sequence number forward
+func (h *tcpHalf) advance(end uint32, now time.Time) {
+	h.nextSeq = end
+	h.lastAdvance = now
+}
+
+// track records a segment waiting for its acknowledgement
+func (h *tcpHalf) track(segment tcpSegment) {
+	if segment.end == segment.seq {
+		return
+	}
+	if len(h.segments) >= maxTrackedSegments {
+		h.segments = h.segments[1:]
+	}
+	h.segments = append(h.segments, segment)
+}
+
+// markRetransmitted flags the tracked segment starting at seq and reports whether it was sent before
+func (h *tcpHalf) markRetransmitted(seq uint32) bool {
+	for i := range h.segments {
+		if h.segments[i].seq == seq {
+			h.segments[i].retransmitted = true
+			return true
+		}
+	}
+	return false
+}
+
+// acknowledge removes the segments covered by ack and returns the one it acknowledges exactly
+func (h *tcpHalf) acknowledge(ack uint32) (tcpSegment, bool) {
+	var acked tcpSegment
+	found := false
+
+	remaining := h.segments[:0]
+	for _, segment := range h.segments {
+		if seqAfter(segment.end, ack) {
+			remaining = append(remaining, segment)
+			continue
+		}
+		if segment.end == ack {
+			acked, found = segment, true
+		}
+	}
+	h.segments = remaining
+
+	return acked, found
+}
+
+// windowScaleOption returns the shift of the window scale option, or -1 when absent
+func windowScaleOption(tcp *models.TCPInfo) int {
+	for _, option := range tcp.Options {
+		if option.Kind == tcpOptionWindowScale && len(option.Data) == 1 {
+			return int(option.Data[0])
+		}
+	}
+	return -1
+}
+
+// seqBefore reports whether sequence number a precedes b, accounting for wraparound
+func seqBefore(a, b uint32) bool {
+	return int32(a-b) < 0
+}
+
+// seqAfter reports whether sequence number a follows b, accounting for wraparound
+func seqAfter(a, b uint32) bool {
+	return int32(a-b) > 0
+}
@@ -0,0 +1,454 @@
+package analyzer
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianarchila/gocapture/internal/parser"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// maxTLSHandshakeBuffer bounds the handshake bytes buffered per direction
+	maxTLSHandshakeBuffer = 256 << 10
+	// maxTLSSessions bounds the number of sessions kept for display
+	maxTLSSessions = 10000
+)
+
+// TLS extension types that JA4 leaves out of the extension hash
+const (
+	tlsExtServerName = 0x0000
+	tlsExtALPN       = 0x0010
+)
+
+// TLSSession summarizes the handshake of a TLS connection
+type TLSSession struct {
+	StreamID         int
+	Client           string
+	Server           string
+	FirstSeen        time.Time
+	SNI              string
+	OfferedVersion   string   // Highest version offered by the client
+	OfferedALPN      []string // Application protocols offered by the client
+	Version          string   // Version selected by the server
+	CipherSuite      string
+	ALPN             string // Application protocol selected by the server
+	JA3              string
+	JA3Hash          string
+	JA4              string
+	JA3S             string
+	JA3SHash         string
+	Certificates     []models.TLSCertificateInfo // Server chain, leaf first (TLS 1.2 and earlier)
+	ClientHelloFrame int64
+	ServerHelloFrame int64
+}
+
+// tlsDirection holds the handshake bytes of one direction of a connection
+type tlsDirection struct {
+	records   []byte // Bytes of a record not yet complete
+	handshake []byte // Bytes of a handshake message spanning several records
+	done      bool   // Encryption started or the data is not TLS
+}
+
+// tlsConnection tracks the TLS handshake of a TCP stream
+type tlsConnection struct {
+	session *TLSSession
+	client  tlsDirection
+	server  tlsDirection
+}
+
+// TLSAnalyzer decodes TLS handshakes from reassembled TCP streams and computes
+// the JA3, JA3S and JA4 fingerprints of each session
+type TLSAnalyzer struct {
+	connections map[int]*tlsConnection // Keyed by TCP stream ID
+	sessions    []*TLSSession
+}
+
+// NewTLSAnalyzer creates a new TLS analyzer
+func NewTLSAnalyzer() *TLSAnalyzer {
+	ta := &TLSAnalyzer{}
+	ta.Reset()
+	return ta
+}
+
+// Reset discards the tracked connections and sessions
+func (ta *TLSAnalyzer) Reset() {
+	ta.connections = make(map[int]*tlsConnection)
+	ta.sessions = nil
+}
+
+// AnalyzeRecords describes the TLS records that start in a frame
+func (ta *TLSAnalyzer) AnalyzeRecords(frame *models.Frame) {
+	if frame.TLS == nil {
+		return
+	}
+
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	records := make([]string, 0, len(frame.TLS.Records))
+	var types []string
+	encrypted := false
+	for _, record := range frame.TLS.Records {
+		name := models.TLSContentTypeName(record.ContentType)
+		if record.ContentType == models.TLSHandshake && encrypted {
+			name = "Encrypted Handshake Message"
+		}
+		encrypted = encrypted || record.ContentType == models.TLSChangeCipherSpec
+		records = append(records, fmt.Sprintf("%s (%s, %d bytes)", name, models.TLSVersionName(record.Version), record.Length))
+		if len(types) == 0 || types[len(types)-1] != name {
+			types = append(types, name)
+		}
+	}
+	frame.AnalysisResults["TLSRecords"] = records
+
+	// Handshake messages decoded from the stream already describe the frame
+	if _, ok := frame.AnalysisResults["TLS"]; ok {
+		return
+	}
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | TLS %s", summary, strings.Join(types, ", "))
+	}
+}
+
+// ReleaseStream forgets the TLS state of a dropped stream, implementing StreamConsumer
+func (ta *TLSAnalyzer) ReleaseStream(stream *TCPStream) {
+	delete(ta.connections, stream.ID)
+}
+
+// ConsumeStream decodes the handshake messages in the reassembled payload of a
+// TCP stream, implementing StreamConsumer
+func (ta *TLSAnalyzer) ConsumeStream(stream *TCPStream, chunk StreamChunk, frame *models.Frame) {
+	conn, ok := ta.connections[stream.ID]
+	if !ok {
+		conn = &tlsConnection{}
+		ta.connections[stream.ID] = conn
+	}
+
+	dir := &conn.server
+	if chunk.FromClient {
+		dir = &conn.client
+	}
+	if dir.done {
+		return
+	}
+
+	// Records cannot be delimited once bytes are missing
+	if chunk.Skipped > 0 {
+		dir.stop()
+		return
+	}
+
+	dir.records = append(dir.records, chunk.Data...)
+	records, payloads, consumed, valid := parser.SplitTLSRecords(dir.records)
+	for i, record := range records {
+		switch record.ContentType {
+		case models.TLSHandshake:
+			dir.handshake = append(dir.handshake, payloads[i]...)
+			handshakes, n := parser.ParseTLSHandshakes(dir.handshake)
+			dir.handshake = dir.handshake[n:]
+			for _, handshake := range handshakes {
+				ta.handleHandshake(conn, stream, chunk, &handshake, frame)
+			}
+		case models.TLSChangeCipherSpec, models.TLSApplicationData:
+			// Later handshake messages are encrypted
+			dir.done = true
+		}
+		if dir.done {
+			break
+		}
+	}
+	dir.records = append([]byte(nil), dir.records[consumed:]...)
+
+	if !valid || dir.done || len(dir.records)+len(dir.handshake) > maxTLSHandshakeBuffer {
+		dir.stop()
+	}
+}
+
+// stop ends the handshake decoding of a direction
+func (d *tlsDirection) stop() {
+	d.done = true
+	d.records = nil
+	d.handshake = nil
+}
+
+// handleHandshake updates the session of a connection with a handshake message
+// and describes the message on the frame that completed it
+func (ta *TLSAnalyzer) handleHandshake(conn *tlsConnection, stream *TCPStream, chunk StreamChunk, handshake *models.TLSHandshakeInfo, frame *models.Frame) {
+	var description string
+	info := make(map[string]interface{})
+
+	switch handshake.Type {
+	case models.TLSClientHello:
+		session := ta.session(conn, stream, chunk)
+		if session == nil {
+			return
+		}
+		session.SNI = handshake.SNI
+		session.OfferedVersion = models.TLSVersionName(handshake.NegotiatedVersion())
+		session.OfferedALPN = handshake.ALPN
+		session.JA3 = ja3(handshake)
+		session.JA3Hash = md5Hex(session.JA3)
+		session.JA4 = ja4(handshake)
+		session.ClientHelloFrame = chunk.FrameID
+
+		info["SNI"] = handshake.SNI
+		info["ALPN"] = handshake.ALPN
+		info["JA3"] = session.JA3Hash
+		info["JA4"] = session.JA4
+		description = "TLS Client Hello"
+		if handshake.SNI != "" {
+			description += " SNI=" + handshake.SNI
+		}
+	case models.TLSServerHello:
+		session := ta.session(conn, stream, chunk)
+		if session == nil {
+			return
+		}
+		session.Version = models.TLSVersionName(handshake.NegotiatedVersion())
+		if len(handshake.CipherSuites) > 0 {
+			session.CipherSuite = models.TLSCipherSuiteName(handshake.CipherSuites[0])
+		}
+		if len(handshake.ALPN) > 0 {
+			session.ALPN = handshake.ALPN[0]
+		}
+		session.JA3S = ja3s(handshake)
+		session.JA3SHash = md5Hex(session.JA3S)
+		session.ServerHelloFrame = chunk.FrameID
+
+		info["Version"] = session.Version
+		info["CipherSuite"] = session.CipherSuite
+		info["JA3S"] = session.JA3SHash
+		description = fmt.Sprintf("TLS Server Hello %s %s", session.Version, session.CipherSuite)
+	case models.TLSCertificate:
+		if conn.session != nil {
+			conn.session.Certificates = handshake.Certificates
+		}
+		description = fmt.Sprintf("TLS Certificate (%d)", len(handshake.Certificates))
+		if len(handshake.Certificates) > 0 {
+			leaf := handshake.Certificates[0]
+			info["Subject"] = leaf.Subject
+			info["Issuer"] = leaf.Issuer
+			info["NotAfter"] = leaf.NotAfter.Format("2006-01-02")
+			description += " " + leaf.Subject
+		}
+	default:
+		description = "TLS " + models.TLSHandshakeTypeName(handshake.Type)
+	}
+
+	if frame == nil {
+		return
+	}
+	if frame.AnalysisResults == nil {
+		frame.AnalysisResults = make(map[string]interface{})
+	}
+
+	// A frame may complete several handshake messages
+	tlsInfo, ok := frame.AnalysisResults["TLS"].(map[string]interface{})
+	if !ok {
+		tlsInfo = make(map[string]interface{})
+		frame.AnalysisResults["TLS"] = tlsInfo
+	}
+	messages, _ := tlsInfo["Handshakes"].([]string)
+	tlsInfo["Handshakes"] = append(messages, models.TLSHandshakeTypeName(handshake.Type))
+	for key, value := range info {
+		tlsInfo[key] = value
+	}
+
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok {
+		frame.AnalysisResults["Summary"] = fmt.Sprintf("%s | %s", summary, description)
+	}
+}
+
+// session returns the session of a connection, creating it with the endpoints
+// of the stream. The sender of the ClientHello is the client, even if the
+// stream saw the other endpoint first.
+func (ta *TLSAnalyzer) session(conn *tlsConnection, stream *TCPStream, chunk StreamChunk) *TLSSession {
+	if conn.session != nil {
+		return conn.session
+	}
+	if len(ta.sessions) >= maxTLSSessions {
+		return nil
+	}
+
+	conn.session = &TLSSession{
+		StreamID:  stream.ID,
+		Client:    stream.Client,
+		Server:    stream.Server,
+		FirstSeen: chunk.Timestamp,
+	}
+	if !chunk.FromClient {
+		conn.session.Client, conn.session.Server = stream.Server, stream.Client
+	}
+	ta.sessions = append(ta.sessions, conn.session)
+	return conn.session
+}
+
+// Sessions returns a copy of the TLS sessions ordered by their first handshake message
+func (ta *TLSAnalyzer) Sessions() []TLSSession {
+	sessions := make([]TLSSession, 0, len(ta.sessions))
+	for _, session := range ta.sessions {
+		sessions = append(sessions, *session)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].FirstSeen.Before(sessions[j].FirstSeen)
+	})
+	return sessions
+}
+
+// ja3 returns the JA3 string of a ClientHello: version, cipher suites,
+// extensions, supported groups and point formats, without GREASE values
+func ja3(hello *models.TLSHandshakeInfo) string {
+	formats := make([]uint16, 0, len(hello.ECPointFormats))
+	for _, format := range hello.ECPointFormats {
+		formats = append(formats, uint16(format))
+	}
+
+	return strings.Join([]string{
+		fmt.Sprint(hello.Version),
+		joinDecimal(hello.CipherSuites),
+		joinDecimal(hello.Extensions),
+		joinDecimal(hello.SupportedGroups),
+		joinDecimal(formats),
+	}, ",")
+}
+
+// ja3s returns the JA3S string of a ServerHello: version, cipher suite and extensions
+func ja3s(hello *models.TLSHandshakeInfo) string {
+	return strings.Join([]string{
+		fmt.Sprint(hello.Version),
+		joinDecimal(hello.CipherSuites),
+		joinDecimal(hello.Extensions),
+	}, ",")
+}
+
+// ja4 returns the JA4 fingerprint of a ClientHello received over TCP
+func ja4(hello *models.TLSHandshakeInfo) string {
+	sni := "i"
+	if hello.SNI != "" {
+		sni = "d"
+	}
+
+	ciphers := withoutGrease(hello.CipherSuites)
+	extensions := withoutGrease(hello.Extensions)
+
+	alpn := "00"
+	if len(hello.ALPN) > 0 && hello.ALPN[0] != "" {
+		alpn = ja4ALPN(hello.ALPN[0])
+	}
+
+	prefix := fmt.Sprintf("t%s%s%02d%02d%s", ja4Version(hello.NegotiatedVersion()), sni,
+		min(len(ciphers), 99), min(len(extensions), 99), alpn)
+
+	// Cipher suites sorted, then extensions sorted without SNI and ALPN followed
+	// by the signature algorithms in the order they were sent
+	sortedCiphers := append([]uint16(nil), ciphers...)
+	sort.Slice(sortedCiphers, func(i, j int) bool { return sortedCiphers[i] < sortedCiphers[j] })
+
+	var sortedExtensions []uint16
+	for _, ext := range extensions {
+		if ext != tlsExtServerName && ext != tlsExtALPN {
+			sortedExtensions = append(sortedExtensions, ext)
+		}
+	}
+	sort.Slice(sortedExtensions, func(i, j int) bool { return sortedExtensions[i] < sortedExtensions[j] })
+
+	extensionsString := joinHex(sortedExtensions)
+	if algorithms := withoutGrease(hello.SignatureAlgorithms); len(algorithms) > 0 {
+		extensionsString += "_" + joinHex(algorithms)
+	}
+
+	cipherHash := "000000000000"
+	if len(sortedCiphers) > 0 {
+		cipherHash = sha256Prefix(joinHex(sortedCiphers))
+	}
+	extensionHash := "000000000000"
+	if len(sortedExtensions) > 0 {
+		extensionHash = sha256Prefix(extensionsString)
+	}
+
+	return fmt.Sprintf("%s_%s_%s", prefix, cipherHash, extensionHash)
+}
+
+// ja4Version returns the two character JA4 code of a TLS version
+func ja4Version(version uint16) string {
+	switch version {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	default:
+		return "00"
+	}
+}
+
+// ja4ALPN returns the first and last characters of the first ALPN value, or of
+// its hex encoding when they are not alphanumeric
+func ja4ALPN(alpn string) string {
+	first, last := alpn[0], alpn[len(alpn)-1]
+	if isAlphanumeric(first) && isAlphanumeric(last) {
+		return string([]byte{first, last})
+	}
+
+	encoded := hex.EncodeToString([]byte(alpn))
+	return string([]byte{encoded[0], encoded[len(encoded)-1]})
+}
+
+// isAlphanumeric reports whether b is an ASCII letter or digit
+func isAlphanumeric(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// withoutGrease returns the values that are not GREASE
+func withoutGrease(values []uint16) []uint16 {
+	result := make([]uint16, 0, len(values))
+	for _, value := range values {
+		if !models.IsTLSGrease(value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// joinDecimal joins the non-GREASE values in decimal with dashes, as JA3 does
+func joinDecimal(values []uint16) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range withoutGrease(values) {
+		parts = append(parts, fmt.Sprint(value))
+	}
+	return strings.Join(parts, "-")
+}
+
+// joinHex joins values as four digit hex with commas, as JA4 does
+func joinHex(values []uint16) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, fmt.Sprintf("%04x", value))
+	}
+	return strings.Join(parts, ",")
+}
+
+// md5Hex returns the hex MD5 digest of s
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// sha256Prefix returns the first 12 hex digits of the SHA-256 digest of s
+func sha256Prefix(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,232 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// minFramesForWMMAudit is the number of frames an access category needs before it is audited
+	minFramesForWMMAudit = 20
+	// maxVoiceAverageSize is the largest average frame size expected for voice traffic
+	maxVoiceAverageSize = 400
+)
+
+// accessCategories lists the WMM access categories from lowest to highest priority
+var accessCategories = []string{
+	models.AccessCategoryBackground,
+	models.AccessCategoryBestEffort,
+	models.AccessCategoryVideo,
+	models.AccessCategoryVoice,
+}
+
+// WMMACStats contains the usage counters of one access category by a station
+type WMMACStats struct {
+	Frames     int
+	Bytes      int
+	TXOPFrames int // Frames carrying a non-zero TXOP value
+	MaxTXOP    int // Largest TXOP value seen, in units of 32μs
+}
+
+// AverageSize returns the average frame size in bytes
+func (s *WMMACStats) AverageSize() float64 {
+	if s.Frames == 0 {
+		return 0
+	}
+	return float64(s.Bytes) / float64(s.Frames)
+}
+
+// WMMStationStats contains the WMM usage of a single station
+type WMMStationStats struct {
+	Station     string
+	BSSID       string
+	ACs         map[string]*WMMACStats
+	ACKPolicies map[string]int
+}
+
+// WMMFinding describes a WMM compliance problem
+type WMMFinding struct {
+	Station        string // Empty for findings about an access point
+	BSSID          string
+	AccessCategory string
+	Description    string
+}
+
+// WMMAnalyzer tracks how stations use WMM access categories and compares it
+// with the parameters advertised by their access points
+type WMMAnalyzer struct {
+	stations   map[string]*WMMStationStats
+	advertised map[string]*models.WMMParameterSet
+}
+
+// NewWMMAnalyzer creates a new WMM analyzer
+func NewWMMAnalyzer() *WMMAnalyzer {
+	return &WMMAnalyzer{
+		stations:   make(map[string]*WMMStationStats),
+		advertised: make(map[string]*models.WMMParameterSet),
+	}
+}
+
+// Reset discards all accumulated statistics
+func (wa *WMMAnalyzer) Reset() {
+	wa.stations = make(map[string]*WMMStationStats)
+	wa.advertised = make(map[string]*models.WMMParameterSet)
+}
+
+// Observe accounts a WLAN frame in the WMM statistics
+func (wa *WMMAnalyzer) Observe(frame *models.Frame) {
+	// Keep the latest parameter set advertised by each access point
+	if frame.FrameType == models.WLANManagementFrame {
+		if managementInfo, ok := frame.AnalysisResults["ManagementInfo"].(map[string]interface{}); ok {
+			if wmm, ok := managementInfo["WMMParameters"].(*models.WMMParameterSet); ok {
+				wa.advertised[frame.BSSID()] = wmm
+			}
+		}
+		return
+	}
+
+	if frame.QoS == nil || frame.Address2 == "" {
+		return
+	}
+
+	stats, ok := wa.stations[frame.Address2]
+	if !ok {
+		stats = &WMMStationStats{
+			Station:     frame.Address2,
+			ACs:         make(map[string]*WMMACStats),
+			ACKPolicies: make(map[string]int),
+		}
+		wa.stations[frame.Address2] = stats
+	}
+	if bssid := frame.BSSID(); bssid != "" {
+		stats.BSSID = bssid
+	}
+
+	accessCategory := getAccessCategory(frame.QoS.TID)
+	acStats, ok := stats.ACs[accessCategory]
+	if !ok {
+		acStats = &WMMACStats{}
+		stats.ACs[accessCategory] = acStats
+	}
+
+	acStats.Frames++
+	acStats.Bytes += frame.Length
+	if frame.QoS.TXOP > 0 {
+		acStats.TXOPFrames++
+		if frame.QoS.TXOP > acStats.MaxTXOP {
+			acStats.MaxTXOP = frame.QoS.TXOP
+		}
+	}
+
+	stats.ACKPolicies[frame.QoS.ACKPolicy]++
+}
+
+// Stations returns the per-station statistics sorted by station address
+func (wa *WMMAnalyzer) Stations() []*WMMStationStats {
+	stations := make([]*WMMStationStats, 0, len(wa.stations))
+	for _, stats := range wa.stations {
+		stations = append(stations, stats)
+	}
+
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].Station < stations[j].Station
+	})
+	return stations
+}
+
+// AdvertisedParameters returns the WMM parameter set advertised by each BSSID
+func (wa *WMMAnalyzer) AdvertisedParameters() map[string]*models.WMMParameterSet {
+	advertised := make(map[string]*models.WMMParameterSet, len(wa.advertised))
+	for bssid, wmm := range wa.advertised {
+		advertised[bssid] = wmm
+	}
+	return advertised
+}
+
+// Findings audits the observed WMM usage and advertised parameters
+func (wa *WMMAnalyzer) Findings() []WMMFinding {
+	var findings []WMMFinding
+
+	// Audit the parameter sets advertised by access points
+	bssids := make([]string, 0, len(wa.advertised))
+	for bssid := range wa.advertised {
+		bssids = append(bssids, bssid)
+	}
+	sort.Strings(bssids)
+
+	for _, bssid := range bssids {
+		findings = append(findings, auditAdvertisedParameters(bssid, wa.advertised[bssid])...)
+	}
+
+	// Audit the observed station behaviour
+	for _, stats := range wa.Stations() {
+		advertised := wa.advertised[stats.BSSID]
+
+		for _, accessCategory := range accessCategories {
+			acStats, ok := stats.ACs[accessCategory]
+			if !ok {
+				continue
+			}
+
+			newFinding := func(format string, args ...interface{}) {
+				findings = append(findings, WMMFinding{
+					Station:        stats.Station,
+					BSSID:          stats.BSSID,
+					AccessCategory: accessCategory,
+					Description:    fmt.Sprintf(format, args...),
+				})
+			}
+
+			if accessCategory == models.AccessCategoryVoice && acStats.Frames >= minFramesForWMMAudit && acStats.AverageSize() > maxVoiceAverageSize {
+				newFinding("Bulk traffic marked as Voice (average frame size %.0f bytes over %d frames)",
+					acStats.AverageSize(), acStats.Frames)
+			}
+
+			if advertised == nil {
+				continue
+			}
+
+			params, ok := advertised.Parameters(accessCategory)
+			if !ok {
+				continue
+			}
+
+			if params.ACM {
+				newFinding("Access point requires admission control (ACM) for this access category")
+			}
+
+			if params.TXOPLimit > 0 && acStats.MaxTXOP > params.TXOPLimit {
+				newFinding("TXOP of %d μs exceeds the advertised limit of %d μs",
+					acStats.MaxTXOP*32, params.TXOPLimit*32)
+			}
+		}
+	}
+
+	return findings
+}
+
+// auditAdvertisedParameters checks that an access point prioritizes access categories in the expected order
+func auditAdvertisedParameters(bssid string, wmm *models.WMMParameterSet) []WMMFinding {
+	var findings []WMMFinding
+
+	for i := 1; i < len(accessCategories); i++ {
+		lower, okLower := wmm.Parameters(accessCategories[i-1])
+		higher, okHigher := wmm.Parameters(accessCategories[i])
+		if !okLower || !okHigher {
+			continue
+		}
+
+		if higher.AIFSN > lower.AIFSN || higher.ECWMin > lower.ECWMin {
+			findings = append(findings, WMMFinding{
+				BSSID:          bssid,
+				AccessCategory: higher.AccessCategory,
+				Description: fmt.Sprintf("Advertised parameters favor %s over %s (AIFSN %d/%d, ECWmin %d/%d)",
+					lower.AccessCategory, higher.AccessCategory, lower.AIFSN, higher.AIFSN, lower.ECWMin, higher.ECWMin),
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,110 @@
+package capture
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// snapLen is the maximum number of bytes captured from each packet
+const snapLen = 65535
+
+// FilterError is a capture filter that libpcap cannot compile for a link type
+type FilterError struct {
+	Expression string
+	LinkType   layers.LinkType
+	Err        error
+}
+
+// Error implements the error interface
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("invalid capture filter %q for link type %s: %v", e.Expression, e.LinkType, e.Err)
+}
+
+// Unwrap returns the error reported by libpcap
+func (e *FilterError) Unwrap() error {
+	return e.Err
+}
+
+// CompileFilter compiles a BPF expression for a link type. An empty expression
+// compiles to no instructions, which captures every packet.
+func CompileFilter(linkType layers.LinkType, expression string) ([]pcap.BPFInstruction, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, nil
+	}
+
+	instructions, err := pcap.CompileBPFFilter(linkType, snapLen, expression)
+	if err != nil {
+		return nil, &FilterError{Expression: expression, LinkType: linkType, Err: err}
+	}
+	return instructions, nil
+}
+
+// interfaceLinkType opens an interface briefly to find out its link type
+func interfaceLinkType(interfaceName string) (layers.LinkType, error) {
+	handle, err := pcap.OpenLive(interfaceName, snapLen, false, 100*time.Millisecond)
+	if err != nil {
+		return 0, fmt.Errorf("error opening interface %s: %v", interfaceName, err)
+	}
+	defer handle.Close()
+	return handle.LinkType(), nil
+}
+
+// IsWLANLinkType reports whether a link type carries 802.11 frames, where the
+// wlan, type and subtype filter primitives are available
+func IsWLANLinkType(linkType layers.LinkType) bool {
+	switch linkType {
+	case layers.LinkTypeIEEE802_11, layers.LinkTypeIEEE80211Radio, layers.LinkTypePrismHeader:
+		return true
+	default:
+		return false
+	}
+}
+
+// EAPOLFilter selects 802.1X authentication frames, including the WPA 4-way handshake
+func EAPOLFilter() string {
+	return "ether proto 0x888e"
+}
+
+// ManagementFilter selects 802.11 management frames
+func ManagementFilter() string {
+	return "type mgt"
+}
+
+// BSSIDFilter selects the 802.11 frames of a BSS. The address that holds the
+// BSSID depends on the direction of the frame.
+func BSSIDFilter(bssid string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(bssid))
+	if err != nil || len(hw) != 6 {
+		return "", fmt.Errorf("invalid BSSID %q", bssid)
+	}
+	return fmt.Sprintf("(dir nods and wlan addr3 %[1]s) or (dir tods and wlan addr1 %[1]s) or (dir fromds and wlan addr2 %[1]s)", hw), nil
+}
+
+// VLANFilter selects the Ethernet frames tagged with a VLAN ID
+func VLANFilter(vlan string) (string, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(vlan))
+	if err != nil || id < 0 || id > 4095 {
+		return "", fmt.Errorf("invalid VLAN ID %q, expected a number between 0 and 4095", vlan)
+	}
+	return fmt.Sprintf("vlan %d", id), nil
+}
+
+// CombineFilters joins two BPF expressions so that packets must match both
+func CombineFilters(first, second string) string {
+	first, second = strings.TrimSpace(first), strings.TrimSpace(second)
+	switch {
+	case first == "":
+		return second
+	case second == "":
+		return first
+	default:
+		return fmt.Sprintf("(%s) and (%s)", first, second)
+	}
+}
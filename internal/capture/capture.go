@@ -2,27 +2,43 @@ package capture
 
 import (
 	"fmt"
+	"strings"
 	"sync"
-	"time"
 
 	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
 	"github.com/julianarchila/gocapture/internal/parser"
 	"github.com/julianarchila/gocapture/pkg/models"
 )
 
+// FrameRecorder writes captured frames to disk from the capture loop, such as
+// storage.RotatingWriter. It is closed when a capture session ends and must
+// accept frames again afterwards.
+type FrameRecorder interface {
+	WriteFrame(frame *models.Frame) error
+	Frames() int
+	Filename() string
+	Close() error
+}
+
 // CaptureEngine handles network frame capture
 type CaptureEngine struct {
 	handle        *pcap.Handle
 	interfaceName string
 	promiscuous   bool
 	filter        string
+	linkType      layers.LinkType
+	linkKnown     bool                  // linkType was read from the interface
+	bpf           []pcap.BPFInstruction // filter compiled for linkType
 	isRunning     bool
 	frameChannel  chan *models.Frame
 	stopChannel   chan struct{}
 	frameCounter  int64
 	mutex         sync.Mutex
 	frameParser   *parser.FrameParser
+	recorder      FrameRecorder
+	recordErr     error // Error that stopped the recorder in the current session
 }
 
 // NewCaptureEngine creates a new capture engine
@@ -47,14 +63,25 @@ func NewCaptureEngine(interfaceName string, promiscuous bool, filter string) (*C
 
 	frameParser := parser.NewFrameParser()
 
-	return &CaptureEngine{
+	ce := &CaptureEngine{
 		interfaceName: interfaceName,
 		promiscuous:   promiscuous,
-		filter:        filter,
 		frameChannel:  make(chan *models.Frame, 1000), // Buffer for 1000 frames
 		stopChannel:   make(chan struct{}),
 		frameParser:   frameParser,
-	}, nil
+	}
+
+	// Compile the filter up front, so that errors are reported before capturing.
+	// Opening the interface needs capture privileges; without them the link
+	// type is unknown and the filter is compiled when the capture starts.
+	if linkType, err := interfaceLinkType(interfaceName); err == nil {
+		ce.linkType, ce.linkKnown = linkType, true
+	}
+	if err := ce.SetFilter(filter); err != nil {
+		return nil, err
+	}
+
+	return ce, nil
 }
 
 // Start begins the capture process
@@ -69,6 +96,7 @@ func (ce *CaptureEngine) Start() error {
 	// Reinitialize channels for new capture session
 	ce.frameChannel = make(chan *models.Frame, 1000) // Buffer for 1000 frames
 	ce.stopChannel = make(chan struct{})
+	ce.recordErr = nil
 
 	// Open the device for capturing
 	// snaplen: 65535 - maximum capture size
@@ -80,9 +108,20 @@ func (ce *CaptureEngine) Start() error {
 		return fmt.Errorf("error opening interface %s: %v", ce.interfaceName, err)
 	}
 
+	// Compile the filter if the link type was unknown or changed since it was
+	// compiled, e.g. because the interface was put in monitor mode
+	if linkType := ce.handle.LinkType(); !ce.linkKnown || linkType != ce.linkType {
+		bpf, err := CompileFilter(linkType, ce.filter)
+		if err != nil {
+			ce.handle.Close()
+			return err
+		}
+		ce.linkType, ce.linkKnown, ce.bpf = linkType, true, bpf
+	}
+
 	// Set BPF filter if specified
-	if ce.filter != "" {
-		if err := ce.handle.SetBPFFilter(ce.filter); err != nil {
+	if len(ce.bpf) > 0 {
+		if err := ce.handle.SetBPFInstructionFilter(ce.bpf); err != nil {
 			ce.handle.Close()
 			return fmt.Errorf("error setting BPF filter: %v", err)
 		}
@@ -124,6 +163,133 @@ func (ce *CaptureEngine) IsRunning() bool {
 	return ce.isRunning
 }
 
+// SetFCSMode sets whether captured Ethernet frames end with their FCS
+func (ce *CaptureEngine) SetFCSMode(mode parser.FCSMode) {
+	ce.frameParser.SetFCSMode(mode)
+}
+
+// SetFilter validates a BPF expression against the link type of the interface
+// and uses it from the next capture session. An empty expression removes the filter.
+func (ce *CaptureEngine) SetFilter(filter string) error {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+
+	if ce.isRunning {
+		return fmt.Errorf("cannot change the capture filter while capturing")
+	}
+
+	bpf, err := ce.compileFilter(filter)
+	if err != nil {
+		return err
+	}
+	ce.filter = strings.TrimSpace(filter)
+	ce.bpf = bpf
+	return nil
+}
+
+// ValidateFilter reports whether a BPF expression compiles for the link type of the interface
+func (ce *CaptureEngine) ValidateFilter(filter string) error {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+
+	_, err := ce.compileFilter(filter)
+	return err
+}
+
+// compileFilter compiles a BPF expression for the link type of the interface.
+// While the link type is unknown the expression only has to compile for
+// Ethernet or 802.11, and no instructions are returned until Start compiles it.
+func (ce *CaptureEngine) compileFilter(filter string) ([]pcap.BPFInstruction, error) {
+	if ce.linkKnown {
+		return CompileFilter(ce.linkType, filter)
+	}
+
+	_, err := CompileFilter(layers.LinkTypeEthernet, filter)
+	if err != nil {
+		if _, wlanErr := CompileFilter(layers.LinkTypeIEEE80211Radio, filter); wlanErr == nil {
+			err = nil
+		}
+	}
+	return nil, err
+}
+
+// GetFilter returns the BPF expression used by the capture
+func (ce *CaptureEngine) GetFilter() string {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	return ce.filter
+}
+
+// LinkType returns the link type of the interface, and false while it is
+// unknown because the interface could not be opened before capturing
+func (ce *CaptureEngine) LinkType() (layers.LinkType, bool) {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	return ce.linkType, ce.linkKnown
+}
+
+// SetRecorder sets the recorder that writes every captured frame to disk, or
+// removes it when nil. Frames are recorded as they are captured, so recording
+// does not depend on the UI keeping them in memory.
+func (ce *CaptureEngine) SetRecorder(recorder FrameRecorder) error {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+
+	if ce.isRunning {
+		return fmt.Errorf("cannot change the recorder while capturing")
+	}
+	ce.recorder = recorder
+	return nil
+}
+
+// Recorder returns the recorder of the engine, nil when frames are not recorded
+func (ce *CaptureEngine) Recorder() FrameRecorder {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	return ce.recorder
+}
+
+// RecordError returns the error that stopped recording in the current capture session
+func (ce *CaptureEngine) RecordError() error {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	return ce.recordErr
+}
+
+// record writes a frame with the recorder until writing fails
+func (ce *CaptureEngine) record(frame *models.Frame) {
+	ce.mutex.Lock()
+	recorder, failed := ce.recorder, ce.recordErr != nil
+	ce.mutex.Unlock()
+	if recorder == nil || failed {
+		return
+	}
+
+	if err := recorder.WriteFrame(frame); err != nil {
+		ce.mutex.Lock()
+		ce.recordErr = err
+		ce.mutex.Unlock()
+	}
+}
+
+// closeRecorder closes the file of the recorder at the end of a capture session
+func (ce *CaptureEngine) closeRecorder() {
+	ce.mutex.Lock()
+	recorder := ce.recorder
+	ce.mutex.Unlock()
+	if recorder == nil {
+		return
+	}
+
+	if err := recorder.Close(); err != nil {
+		ce.mutex.Lock()
+		if ce.recordErr == nil {
+			ce.recordErr = err
+		}
+		ce.mutex.Unlock()
+	}
+}
+
 // GetInterfaceName returns the name of the interface being captured
 func (ce *CaptureEngine) GetInterfaceName() string {
 	return ce.interfaceName
@@ -131,8 +297,10 @@ func (ce *CaptureEngine) GetInterfaceName() string {
 
 // captureFrames is the main capture loop
 func (ce *CaptureEngine) captureFrames() {
-	packetSource := gopacket.NewPacketSource(ce.handle, ce.handle.LinkType())
+	linkType := ce.handle.LinkType()
+	packetSource := gopacket.NewPacketSource(ce.handle, linkType)
 	packetChannel := packetSource.Packets()
+	defer ce.closeRecorder()
 
 	for {
 		select {
@@ -151,18 +319,23 @@ func (ce *CaptureEngine) captureFrames() {
 			frameID := ce.frameCounter
 			ce.mutex.Unlock()
 
-			// Create the base frame
+			// Create the base frame with the time libpcap captured the packet at
 			frame := &models.Frame{
 				ID:             frameID,
-				Timestamp:      time.Now(),
+				Timestamp:      packet.Metadata().Timestamp,
 				RawData:        packet.Data(),
 				Length:         len(packet.Data()),
+				OriginalLength: packet.Metadata().Length,
+				LinkType:       linkType,
 				OriginalPacket: packet,
 			}
 
 			// Parse the frame based on its type
 			ce.frameParser.ParseFrame(frame)
 
+			// Record the frame before the UI gets it
+			ce.record(frame)
+
 			// Send the frame to the channel
 			ce.frameChannel <- frame
 		}
@@ -0,0 +1,40 @@
+package capture
+
+import (
+	"fmt"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+	"github.com/julianarchila/gocapture/internal/parser"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// ReadCaptureFile reads and parses every frame of a pcap or pcapng file
+func ReadCaptureFile(path string, fcsMode parser.FCSMode) ([]*models.Frame, error) {
+	handle, err := pcap.OpenOffline(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening capture file %s: %v", path, err)
+	}
+	defer handle.Close()
+
+	frameParser := parser.NewFrameParser()
+	frameParser.SetFCSMode(fcsMode)
+
+	var frames []*models.Frame
+	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
+	for packet := range packetSource.Packets() {
+		frame := &models.Frame{
+			ID:             int64(len(frames) + 1),
+			Timestamp:      packet.Metadata().Timestamp,
+			RawData:        packet.Data(),
+			Length:         len(packet.Data()),
+			OriginalLength: packet.Metadata().Length,
+			LinkType:       handle.LinkType(),
+			OriginalPacket: packet,
+		}
+		frameParser.ParseFrame(frame)
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
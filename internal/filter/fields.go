@@ -0,0 +1,745 @@
+package filter
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// FieldType is the type of the values of a filter field, which decides the
+// operators it accepts and how the values compared with it are parsed
+type FieldType int
+
+const (
+	TypeProtocol FieldType = iota // Only tested for presence
+	TypeBool
+	TypeNumber
+	TypeString
+	TypeMAC
+	TypeIP
+	TypeBytes
+)
+
+// String returns the name of the field type used in error messages
+func (t FieldType) String() string {
+	switch t {
+	case TypeProtocol:
+		return "protocol"
+	case TypeBool:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeMAC:
+		return "MAC address"
+	case TypeIP:
+		return "IP address"
+	case TypeBytes:
+		return "byte sequence"
+	default:
+		return "unknown"
+	}
+}
+
+// Field is a named value of a frame that filters can test
+type Field struct {
+	Name        string
+	Type        FieldType
+	Description string
+
+	// extract returns the values of the field in a single frame, without its
+	// inner frames: int64, string, net.IP, []byte or bool depending on Type
+	extract func(frame *models.Frame) []interface{}
+}
+
+// values returns the values of the field in a frame and every frame it encapsulates
+func (f *Field) values(frame *models.Frame) []interface{} {
+	var values []interface{}
+	for _, current := range frame.Frames() {
+		values = append(values, f.extract(current)...)
+	}
+	return values
+}
+
+// registry holds the fields known to filters, keyed by name
+var registry = make(map[string]*Field)
+
+// register adds fields to the registry
+func register(fields ...*Field) {
+	for _, field := range fields {
+		registry[field.Name] = field
+	}
+}
+
+// Fields returns the fields known to filters sorted by name
+func Fields() []Field {
+	fields := make([]Field, 0, len(registry))
+	for _, field := range registry {
+		fields = append(fields, *field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// protocolField is present when present returns true
+func protocolField(name, description string, present func(*models.Frame) bool) *Field {
+	return &Field{Name: name, Type: TypeProtocol, Description: description, extract: func(frame *models.Frame) []interface{} {
+		if present(frame) {
+			return []interface{}{true}
+		}
+		return nil
+	}}
+}
+
+// boolField has a value when get reports ok
+func boolField(name, description string, get func(*models.Frame) (value bool, ok bool)) *Field {
+	return &Field{Name: name, Type: TypeBool, Description: description, extract: func(frame *models.Frame) []interface{} {
+		if value, ok := get(frame); ok {
+			return []interface{}{value}
+		}
+		return nil
+	}}
+}
+
+// numberField has the numbers returned by get
+func numberField(name, description string, get func(*models.Frame) []int64) *Field {
+	return &Field{Name: name, Type: TypeNumber, Description: description, extract: func(frame *models.Frame) []interface{} {
+		var values []interface{}
+		for _, value := range get(frame) {
+			values = append(values, value)
+		}
+		return values
+	}}
+}
+
+// stringField has the non-empty strings returned by get
+func stringField(name, description string, get func(*models.Frame) []string) *Field {
+	return &Field{Name: name, Type: TypeString, Description: description, extract: func(frame *models.Frame) []interface{} {
+		var values []interface{}
+		for _, value := range get(frame) {
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}}
+}
+
+// macField has the valid MAC addresses returned by get, normalized to lowercase
+func macField(name, description string, get func(*models.Frame) []string) *Field {
+	return &Field{Name: name, Type: TypeMAC, Description: description, extract: func(frame *models.Frame) []interface{} {
+		var values []interface{}
+		for _, value := range get(frame) {
+			if hw, err := net.ParseMAC(value); err == nil {
+				values = append(values, hw.String())
+			}
+		}
+		return values
+	}}
+}
+
+// ipField has the valid IP addresses returned by get
+func ipField(name, description string, get func(*models.Frame) []string) *Field {
+	return &Field{Name: name, Type: TypeIP, Description: description, extract: func(frame *models.Frame) []interface{} {
+		var values []interface{}
+		for _, value := range get(frame) {
+			if ip := net.ParseIP(value); ip != nil {
+				values = append(values, ip)
+			}
+		}
+		return values
+	}}
+}
+
+// bytesField has the byte sequence returned by get when it is not empty
+func bytesField(name, description string, get func(*models.Frame) []byte) *Field {
+	return &Field{Name: name, Type: TypeBytes, Description: description, extract: func(frame *models.Frame) []interface{} {
+		if value := get(frame); len(value) > 0 {
+			return []interface{}{value}
+		}
+		return nil
+	}}
+}
+
+// number wraps a single value, present when ok
+func number(value int64, ok bool) []int64 {
+	if !ok {
+		return nil
+	}
+	return []int64{value}
+}
+
+// frameTypeName returns the name of a frame type used by the frame.type field
+func frameTypeName(frameType models.FrameType) string {
+	switch frameType {
+	case models.EthernetFrame:
+		return "eth"
+	case models.WLANManagementFrame:
+		return "wlan_mgmt"
+	case models.WLANControlFrame:
+		return "wlan_ctrl"
+	case models.WLANDataFrame:
+		return "wlan_data"
+	default:
+		return ""
+	}
+}
+
+// frameControlValue returns a field of the 802.11 frame control
+func frameControlValue(frame *models.Frame, key string) (interface{}, bool) {
+	frameControl, ok := frame.FrameControl.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := frameControl[key]
+	return value, ok
+}
+
+// frameControlNumber returns a numeric field of the 802.11 frame control
+func frameControlNumber(frame *models.Frame, key string) []int64 {
+	value, _ := frameControlValue(frame, key)
+	switch v := value.(type) {
+	case uint16:
+		return []int64{int64(v)}
+	case uint8:
+		return []int64{int64(v)}
+	case int:
+		return []int64{int64(v)}
+	default:
+		return nil
+	}
+}
+
+// frameControlFlag returns a flag of the 802.11 frame control
+func frameControlFlag(frame *models.Frame, key string) (bool, bool) {
+	value, _ := frameControlValue(frame, key)
+	flag, ok := value.(bool)
+	return flag, ok
+}
+
+// analysisValue returns a value of a map stored in the analysis results of a frame
+func analysisValue(frame *models.Frame, result string, key string) (interface{}, bool) {
+	info, ok := frame.AnalysisResults[result].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := info[key]
+	return value, ok
+}
+
+// isEthernet reports whether the frame has an Ethernet header
+func isEthernet(frame *models.Frame) bool {
+	return frame.FrameType == models.EthernetFrame && frame.SourceMAC != ""
+}
+
+// isWLAN reports whether the frame is an 802.11 frame
+func isWLAN(frame *models.Frame) bool {
+	return frame.FrameType != models.EthernetFrame
+}
+
+func init() {
+	register(
+		// Frame
+		bytesField("frame", "Bytes of the frame", func(f *models.Frame) []byte { return f.RawData }),
+		numberField("frame.number", "Frame number", func(f *models.Frame) []int64 { return []int64{f.ID} }),
+		numberField("frame.len", "Frame length in bytes", func(f *models.Frame) []int64 { return []int64{int64(f.Length)} }),
+		stringField("frame.type", "Frame type: eth, wlan_mgmt, wlan_ctrl or wlan_data", func(f *models.Frame) []string {
+			return []string{frameTypeName(f.FrameType)}
+		}),
+		stringField("frame.protocols", "Protocol stack, e.g. eth:ipv4:tcp:tls", func(f *models.Frame) []string {
+			return []string{strings.ToLower(strings.Join(f.ProtocolStack(), ":"))}
+		}),
+		stringField("frame.summary", "Summary of the frame analysis", func(f *models.Frame) []string {
+			summary, _ := f.AnalysisResults["Summary"].(string)
+			return []string{summary}
+		}),
+		boolField("frame.fcs_bad", "The frame check sequence is wrong", func(f *models.Frame) (bool, bool) {
+			return f.FCS != nil && !f.FCS.Valid, f.FCS != nil
+		}),
+
+		// Ethernet and encapsulations
+		protocolField("eth", "Ethernet", isEthernet),
+		macField("eth.src", "Ethernet source", func(f *models.Frame) []string {
+			if !isEthernet(f) {
+				return nil
+			}
+			return []string{f.SourceMAC}
+		}),
+		macField("eth.dst", "Ethernet destination", func(f *models.Frame) []string {
+			if !isEthernet(f) {
+				return nil
+			}
+			return []string{f.DestinationMAC}
+		}),
+		macField("eth.addr", "Ethernet source or destination", func(f *models.Frame) []string {
+			if !isEthernet(f) {
+				return nil
+			}
+			return []string{f.SourceMAC, f.DestinationMAC}
+		}),
+		numberField("eth.type", "EtherType, also from the LLC/SNAP header of 802.11 data frames", func(f *models.Frame) []int64 {
+			return number(int64(f.EtherType), f.EtherType != 0)
+		}),
+		protocolField("vlan", "802.1Q/802.1ad tag", func(f *models.Frame) bool { return len(f.VLANTags) > 0 }),
+		numberField("vlan.id", "VLAN ID of any tag", func(f *models.Frame) []int64 {
+			var ids []int64
+			for _, tag := range f.VLANTags {
+				ids = append(ids, int64(tag.VID))
+			}
+			return ids
+		}),
+		numberField("vlan.priority", "Priority code point of any tag", func(f *models.Frame) []int64 {
+			var priorities []int64
+			for _, tag := range f.VLANTags {
+				priorities = append(priorities, int64(tag.Priority))
+			}
+			return priorities
+		}),
+		protocolField("mpls", "MPLS", func(f *models.Frame) bool { return len(f.MPLSLabels) > 0 }),
+		numberField("mpls.label", "Label of any MPLS stack entry", func(f *models.Frame) []int64 {
+			var labels []int64
+			for _, label := range f.MPLSLabels {
+				labels = append(labels, int64(label.Label))
+			}
+			return labels
+		}),
+		protocolField("pppoe", "PPPoE", func(f *models.Frame) bool { return f.PPPoE != nil }),
+		protocolField("eapol", "EAPOL (802.1X)", func(f *models.Frame) bool { return f.EtherType == 0x888e }),
+		protocolField("stp", "Spanning tree BPDU", func(f *models.Frame) bool { return f.STP != nil }),
+		protocolField("lldp", "LLDP", func(f *models.Frame) bool { return f.Discovery != nil && f.Discovery.Protocol == "LLDP" }),
+		protocolField("cdp", "CDP", func(f *models.Frame) bool { return f.Discovery != nil && f.Discovery.Protocol == "CDP" }),
+
+		// 802.11
+		protocolField("wlan", "802.11", isWLAN),
+		macField("wlan.ra", "Receiver address (address 1)", func(f *models.Frame) []string {
+			if !isWLAN(f) {
+				return nil
+			}
+			return []string{f.Address1}
+		}),
+		macField("wlan.ta", "Transmitter address (address 2)", func(f *models.Frame) []string {
+			if !isWLAN(f) {
+				return nil
+			}
+			return []string{f.Address2}
+		}),
+		macField("wlan.sa", "Source address", func(f *models.Frame) []string {
+			if !isWLAN(f) {
+				return nil
+			}
+			return []string{f.SourceMAC}
+		}),
+		macField("wlan.da", "Destination address", func(f *models.Frame) []string {
+			if !isWLAN(f) {
+				return nil
+			}
+			return []string{f.DestinationMAC}
+		}),
+		macField("wlan.bssid", "BSSID", func(f *models.Frame) []string { return []string{f.BSSID()} }),
+		macField("wlan.addr", "Any 802.11 address", func(f *models.Frame) []string {
+			if !isWLAN(f) {
+				return nil
+			}
+			return []string{f.Address1, f.Address2, f.Address3, f.Address4}
+		}),
+		numberField("wlan.fc.type", "Frame type: 0 management, 1 control, 2 data", func(f *models.Frame) []int64 {
+			return frameControlNumber(f, "Type")
+		}),
+		numberField("wlan.fc.subtype", "Frame subtype", func(f *models.Frame) []int64 {
+			return frameControlNumber(f, "Subtype")
+		}),
+		boolField("wlan.fc.retry", "Retry flag", func(f *models.Frame) (bool, bool) { return frameControlFlag(f, "Retry") }),
+		boolField("wlan.fc.protected", "Protected frame flag", func(f *models.Frame) (bool, bool) { return frameControlFlag(f, "Protected") }),
+		stringField("wlan.encryption", "Encryption: None, WEP, WPA, WPA2, WPA3 or Unknown", func(f *models.Frame) []string {
+			if f.Security == nil {
+				return nil
+			}
+			return []string{f.Security.EncryptionType}
+		}),
+		numberField("wlan.signal", "Antenna signal in dBm", func(f *models.Frame) []int64 {
+			if f.RadioTap == nil {
+				return nil
+			}
+			return []int64{int64(f.RadioTap.Signal)}
+		}),
+		numberField("wlan.freq", "Channel frequency in MHz", func(f *models.Frame) []int64 {
+			if f.RadioTap == nil || f.RadioTap.ChannelFreq == 0 {
+				return nil
+			}
+			return []int64{int64(f.RadioTap.ChannelFreq)}
+		}),
+		protocolField("qos", "QoS control field", func(f *models.Frame) bool { return f.QoS != nil }),
+		numberField("qos.tid", "Traffic identifier", func(f *models.Frame) []int64 {
+			if f.QoS == nil {
+				return nil
+			}
+			return []int64{int64(f.QoS.TID)}
+		}),
+		numberField("qos.priority", "User priority", func(f *models.Frame) []int64 {
+			if f.QoS == nil {
+				return nil
+			}
+			return []int64{int64(f.QoS.Priority)}
+		}),
+
+		// ARP
+		protocolField("arp", "ARP", func(f *models.Frame) bool { return f.ARP != nil }),
+		numberField("arp.opcode", "Operation: 1 request, 2 reply", func(f *models.Frame) []int64 {
+			if f.ARP == nil {
+				return nil
+			}
+			return []int64{int64(f.ARP.Operation)}
+		}),
+		macField("arp.src.hw", "Sender MAC address", func(f *models.Frame) []string {
+			return arpValue(f, func(a *models.ARPInfo) string { return a.SenderMAC })
+		}),
+		ipField("arp.src.ip", "Sender IP address", func(f *models.Frame) []string {
+			return arpValue(f, func(a *models.ARPInfo) string { return a.SenderIP })
+		}),
+		macField("arp.dst.hw", "Target MAC address", func(f *models.Frame) []string {
+			return arpValue(f, func(a *models.ARPInfo) string { return a.TargetMAC })
+		}),
+		ipField("arp.dst.ip", "Target IP address", func(f *models.Frame) []string {
+			return arpValue(f, func(a *models.ARPInfo) string { return a.TargetIP })
+		}),
+		boolField("arp.gratuitous", "Gratuitous ARP", func(f *models.Frame) (bool, bool) { return f.ARP != nil && f.ARP.Gratuitous, f.ARP != nil }),
+
+		// IPv4 and IPv6
+		protocolField("ip", "IPv4", func(f *models.Frame) bool { return f.IPv4 != nil }),
+		ipField("ip.src", "IPv4 source", func(f *models.Frame) []string {
+			return ipv4Value(f, func(ip *models.IPv4Info) string { return ip.SourceIP })
+		}),
+		ipField("ip.dst", "IPv4 destination", func(f *models.Frame) []string {
+			return ipv4Value(f, func(ip *models.IPv4Info) string { return ip.DestinationIP })
+		}),
+		ipField("ip.addr", "IPv4 source or destination", func(f *models.Frame) []string {
+			if f.IPv4 == nil {
+				return nil
+			}
+			return []string{f.IPv4.SourceIP, f.IPv4.DestinationIP}
+		}),
+		numberField("ip.ttl", "Time to live", func(f *models.Frame) []int64 {
+			return ipv4Number(f, func(ip *models.IPv4Info) int64 { return int64(ip.TTL) })
+		}),
+		numberField("ip.proto", "Protocol number", func(f *models.Frame) []int64 {
+			return ipv4Number(f, func(ip *models.IPv4Info) int64 { return int64(ip.Protocol) })
+		}),
+		numberField("ip.dscp", "DSCP", func(f *models.Frame) []int64 {
+			return ipv4Number(f, func(ip *models.IPv4Info) int64 { return int64(ip.DSCP) })
+		}),
+		numberField("ip.id", "Identification", func(f *models.Frame) []int64 {
+			return ipv4Number(f, func(ip *models.IPv4Info) int64 { return int64(ip.ID) })
+		}),
+		numberField("ip.len", "Total length", func(f *models.Frame) []int64 {
+			return ipv4Number(f, func(ip *models.IPv4Info) int64 { return int64(ip.TotalLength) })
+		}),
+		boolField("ip.flags.df", "Don't fragment flag", func(f *models.Frame) (bool, bool) { return f.IPv4 != nil && f.IPv4.DontFragment, f.IPv4 != nil }),
+		boolField("ip.flags.mf", "More fragments flag", func(f *models.Frame) (bool, bool) { return f.IPv4 != nil && f.IPv4.MoreFragments, f.IPv4 != nil }),
+		protocolField("ipv6", "IPv6", func(f *models.Frame) bool { return f.IPv6 != nil }),
+		ipField("ipv6.src", "IPv6 source", func(f *models.Frame) []string {
+			return ipv6Value(f, func(ip *models.IPv6Info) string { return ip.SourceIP })
+		}),
+		ipField("ipv6.dst", "IPv6 destination", func(f *models.Frame) []string {
+			return ipv6Value(f, func(ip *models.IPv6Info) string { return ip.DestinationIP })
+		}),
+		ipField("ipv6.addr", "IPv6 source or destination", func(f *models.Frame) []string {
+			if f.IPv6 == nil {
+				return nil
+			}
+			return []string{f.IPv6.SourceIP, f.IPv6.DestinationIP}
+		}),
+		numberField("ipv6.hlim", "Hop limit", func(f *models.Frame) []int64 {
+			return ipv6Number(f, func(ip *models.IPv6Info) int64 { return int64(ip.HopLimit) })
+		}),
+		numberField("ipv6.nxt", "Upper layer protocol after the extension headers", func(f *models.Frame) []int64 {
+			return ipv6Number(f, func(ip *models.IPv6Info) int64 { return int64(ip.Protocol) })
+		}),
+		numberField("ipv6.dscp", "DSCP", func(f *models.Frame) []int64 {
+			return ipv6Number(f, func(ip *models.IPv6Info) int64 { return int64(ip.DSCP) })
+		}),
+		numberField("ipv6.flow", "Flow label", func(f *models.Frame) []int64 {
+			return ipv6Number(f, func(ip *models.IPv6Info) int64 { return int64(ip.FlowLabel) })
+		}),
+
+		// TCP and UDP
+		protocolField("tcp", "TCP", func(f *models.Frame) bool { return f.TCP != nil }),
+		numberField("tcp.srcport", "TCP source port", func(f *models.Frame) []int64 {
+			return tcpValue(f, func(t *models.TCPInfo) int64 { return int64(t.SourcePort) })
+		}),
+		numberField("tcp.dstport", "TCP destination port", func(f *models.Frame) []int64 {
+			return tcpValue(f, func(t *models.TCPInfo) int64 { return int64(t.DestinationPort) })
+		}),
+		numberField("tcp.port", "TCP source or destination port", func(f *models.Frame) []int64 {
+			if f.TCP == nil {
+				return nil
+			}
+			return []int64{int64(f.TCP.SourcePort), int64(f.TCP.DestinationPort)}
+		}),
+		numberField("tcp.seq", "Sequence number", func(f *models.Frame) []int64 {
+			return tcpValue(f, func(t *models.TCPInfo) int64 { return int64(t.Seq) })
+		}),
+		numberField("tcp.ack", "Acknowledgment number", func(f *models.Frame) []int64 {
+			return tcpValue(f, func(t *models.TCPInfo) int64 { return int64(t.Ack) })
+		}),
+		numberField("tcp.window", "Window field, not scaled", func(f *models.Frame) []int64 {
+			return tcpValue(f, func(t *models.TCPInfo) int64 { return int64(t.Window) })
+		}),
+		numberField("tcp.len", "TCP payload length", func(f *models.Frame) []int64 {
+			return tcpValue(f, func(t *models.TCPInfo) int64 { return int64(t.PayloadLength) })
+		}),
+		boolField("tcp.flags.syn", "SYN flag", func(f *models.Frame) (bool, bool) { return tcpFlag(f, func(t models.TCPFlags) bool { return t.SYN }) }),
+		boolField("tcp.flags.ack", "ACK flag", func(f *models.Frame) (bool, bool) { return tcpFlag(f, func(t models.TCPFlags) bool { return t.ACK }) }),
+		boolField("tcp.flags.fin", "FIN flag", func(f *models.Frame) (bool, bool) { return tcpFlag(f, func(t models.TCPFlags) bool { return t.FIN }) }),
+		boolField("tcp.flags.rst", "RST flag", func(f *models.Frame) (bool, bool) { return tcpFlag(f, func(t models.TCPFlags) bool { return t.RST }) }),
+		boolField("tcp.flags.psh", "PSH flag", func(f *models.Frame) (bool, bool) { return tcpFlag(f, func(t models.TCPFlags) bool { return t.PSH }) }),
+		boolField("tcp.flags.urg", "URG flag", func(f *models.Frame) (bool, bool) { return tcpFlag(f, func(t models.TCPFlags) bool { return t.URG }) }),
+		protocolField("tcp.analysis", "The TCP analysis found a problem", func(f *models.Frame) bool {
+			_, ok := f.AnalysisResults["TCPAnalysis"]
+			return ok
+		}),
+		protocolField("udp", "UDP", func(f *models.Frame) bool { return f.UDP != nil }),
+		numberField("udp.srcport", "UDP source port", func(f *models.Frame) []int64 {
+			return udpValue(f, func(u *models.UDPInfo) int64 { return int64(u.SourcePort) })
+		}),
+		numberField("udp.dstport", "UDP destination port", func(f *models.Frame) []int64 {
+			return udpValue(f, func(u *models.UDPInfo) int64 { return int64(u.DestinationPort) })
+		}),
+		numberField("udp.port", "UDP source or destination port", func(f *models.Frame) []int64 {
+			if f.UDP == nil {
+				return nil
+			}
+			return []int64{int64(f.UDP.SourcePort), int64(f.UDP.DestinationPort)}
+		}),
+		numberField("udp.len", "UDP payload length", func(f *models.Frame) []int64 {
+			return udpValue(f, func(u *models.UDPInfo) int64 { return int64(u.PayloadLength) })
+		}),
+
+		// ICMP and Neighbor Discovery
+		protocolField("icmp", "ICMP", func(f *models.Frame) bool { return f.ICMP != nil && f.ICMP.Version == 4 }),
+		numberField("icmp.type", "ICMP type", func(f *models.Frame) []int64 {
+			return icmpValue(f, 4, func(i *models.ICMPInfo) int64 { return int64(i.Type) })
+		}),
+		numberField("icmp.code", "ICMP code", func(f *models.Frame) []int64 {
+			return icmpValue(f, 4, func(i *models.ICMPInfo) int64 { return int64(i.Code) })
+		}),
+		protocolField("icmpv6", "ICMPv6", func(f *models.Frame) bool { return f.ICMP != nil && f.ICMP.Version == 6 }),
+		numberField("icmpv6.type", "ICMPv6 type", func(f *models.Frame) []int64 {
+			return icmpValue(f, 6, func(i *models.ICMPInfo) int64 { return int64(i.Type) })
+		}),
+		numberField("icmpv6.code", "ICMPv6 code", func(f *models.Frame) []int64 {
+			return icmpValue(f, 6, func(i *models.ICMPInfo) int64 { return int64(i.Code) })
+		}),
+		protocolField("nd", "ICMPv6 Neighbor Discovery", func(f *models.Frame) bool { return f.ICMP != nil && f.ICMP.ND != nil }),
+		ipField("nd.target", "Target address of a neighbor solicitation, advertisement or redirect", func(f *models.Frame) []string {
+			if f.ICMP == nil || f.ICMP.ND == nil {
+				return nil
+			}
+			return []string{f.ICMP.ND.TargetAddress}
+		}),
+		stringField("nd.prefix", "Prefix announced by a router advertisement, e.g. 2001:db8::/64", func(f *models.Frame) []string {
+			if f.ICMP == nil || f.ICMP.ND == nil {
+				return nil
+			}
+			var prefixes []string
+			for _, prefix := range f.ICMP.ND.Prefixes {
+				prefixes = append(prefixes, prefix.Prefix)
+			}
+			return prefixes
+		}),
+
+		// Tunnels
+		stringField("tunnel.type", "Tunnel: GRE, ERSPAN, VXLAN, Geneve or IP-in-IP", func(f *models.Frame) []string {
+			if f.Tunnel == nil {
+				return nil
+			}
+			return []string{f.Tunnel.Type}
+		}),
+		numberField("vxlan.vni", "VXLAN or Geneve network identifier", func(f *models.Frame) []int64 {
+			if f.Tunnel == nil || (f.Tunnel.Type != "VXLAN" && f.Tunnel.Type != "Geneve") {
+				return nil
+			}
+			return []int64{int64(f.Tunnel.VNI)}
+		}),
+
+		// Application protocols
+		protocolField("dhcp", "DHCP", func(f *models.Frame) bool { return f.DHCP != nil && f.DHCP.Version == 4 }),
+		protocolField("dhcpv6", "DHCPv6", func(f *models.Frame) bool { return f.DHCP != nil && f.DHCP.Version == 6 }),
+		stringField("dhcp.type", "DHCP message type, e.g. Discover or Ack", func(f *models.Frame) []string {
+			if f.DHCP == nil {
+				return nil
+			}
+			return []string{f.DHCP.MessageType}
+		}),
+		macField("dhcp.client", "DHCP client MAC address", func(f *models.Frame) []string {
+			if f.DHCP == nil {
+				return nil
+			}
+			return []string{f.DHCP.ClientMAC}
+		}),
+		protocolField("dns", "DNS, mDNS or LLMNR", func(f *models.Frame) bool { return f.DNS != nil }),
+		protocolField("mdns", "mDNS", func(f *models.Frame) bool { return f.DNS != nil && f.DNS.Protocol == "mDNS" }),
+		protocolField("llmnr", "LLMNR", func(f *models.Frame) bool { return f.DNS != nil && f.DNS.Protocol == "LLMNR" }),
+		numberField("dns.id", "Transaction ID", func(f *models.Frame) []int64 {
+			if f.DNS == nil {
+				return nil
+			}
+			return []int64{int64(f.DNS.ID)}
+		}),
+		boolField("dns.flags.response", "The message is a response", func(f *models.Frame) (bool, bool) { return f.DNS != nil && f.DNS.Response, f.DNS != nil }),
+		numberField("dns.flags.rcode", "Response code", func(f *models.Frame) []int64 {
+			if f.DNS == nil || !f.DNS.Response {
+				return nil
+			}
+			return []int64{int64(f.DNS.ResponseCode)}
+		}),
+		stringField("dns.qry.name", "Queried name", func(f *models.Frame) []string {
+			if f.DNS == nil {
+				return nil
+			}
+			var names []string
+			for _, question := range f.DNS.Questions {
+				names = append(names, question.Name)
+			}
+			return names
+		}),
+		stringField("dns.qry.type", "Queried record type, e.g. AAAA", func(f *models.Frame) []string {
+			if f.DNS == nil {
+				return nil
+			}
+			var types []string
+			for _, question := range f.DNS.Questions {
+				types = append(types, question.Type)
+			}
+			return types
+		}),
+		protocolField("tls", "TLS", func(f *models.Frame) bool {
+			_, ok := f.AnalysisResults["TLS"]
+			return f.TLS != nil || ok
+		}),
+		numberField("tls.record.content_type", "Record content type, e.g. 22 for handshake", func(f *models.Frame) []int64 {
+			if f.TLS == nil {
+				return nil
+			}
+			var types []int64
+			for _, record := range f.TLS.Records {
+				types = append(types, int64(record.ContentType))
+			}
+			return types
+		}),
+		numberField("tls.handshake.type", "Handshake message type, e.g. 1 for Client Hello", func(f *models.Frame) []int64 {
+			if f.TLS == nil {
+				return nil
+			}
+			var types []int64
+			for _, handshake := range f.TLS.Handshakes {
+				types = append(types, int64(handshake.Type))
+			}
+			return types
+		}),
+		stringField("tls.handshake.sni", "Server name of a Client Hello", func(f *models.Frame) []string {
+			var names []string
+			if f.TLS != nil {
+				for _, handshake := range f.TLS.Handshakes {
+					names = append(names, handshake.SNI)
+				}
+			}
+			return append(names, analysisString(f, "TLS", "SNI")...)
+		}),
+		protocolField("http", "HTTP message decoded from the TCP stream", func(f *models.Frame) bool {
+			_, ok := f.AnalysisResults["HTTP"]
+			return ok
+		}),
+		stringField("http.request.method", "Request method", func(f *models.Frame) []string { return analysisString(f, "HTTP", "Method") }),
+		stringField("http.request.uri", "Request URI", func(f *models.Frame) []string { return analysisString(f, "HTTP", "URI") }),
+		stringField("http.host", "Host header of the request", func(f *models.Frame) []string { return analysisString(f, "HTTP", "Host") }),
+		numberField("http.response.code", "Response status code", func(f *models.Frame) []int64 {
+			status, ok := analysisValue(f, "HTTP", "Status")
+			code, isInt := status.(int)
+			return number(int64(code), ok && isInt)
+		}),
+	)
+}
+
+// analysisString returns a string value of a map stored in the analysis results of a frame
+func analysisString(frame *models.Frame, result string, key string) []string {
+	value, _ := analysisValue(frame, result, key)
+	if s, ok := value.(string); ok {
+		return []string{s}
+	}
+	return nil
+}
+
+// arpValue returns a field of the ARP packet of a frame
+func arpValue(frame *models.Frame, get func(*models.ARPInfo) string) []string {
+	if frame.ARP == nil {
+		return nil
+	}
+	return []string{get(frame.ARP)}
+}
+
+// ipv4Value returns a field of the IPv4 header of a frame
+func ipv4Value(frame *models.Frame, get func(*models.IPv4Info) string) []string {
+	if frame.IPv4 == nil {
+		return nil
+	}
+	return []string{get(frame.IPv4)}
+}
+
+// ipv6Value returns a field of the IPv6 header of a frame
+func ipv6Value(frame *models.Frame, get func(*models.IPv6Info) string) []string {
+	if frame.IPv6 == nil {
+		return nil
+	}
+	return []string{get(frame.IPv6)}
+}
+
+// tcpValue returns a field of the TCP header of a frame
+func tcpValue(frame *models.Frame, get func(*models.TCPInfo) int64) []int64 {
+	if frame.TCP == nil {
+		return nil
+	}
+	return []int64{get(frame.TCP)}
+}
+
+// tcpFlag returns a flag of the TCP header of a frame
+func tcpFlag(frame *models.Frame, get func(models.TCPFlags) bool) (bool, bool) {
+	if frame.TCP == nil {
+		return false, false
+	}
+	return get(frame.TCP.Flags), true
+}
+
+// udpValue returns a field of the UDP header of a frame
+func udpValue(frame *models.Frame, get func(*models.UDPInfo) int64) []int64 {
+	if frame.UDP == nil {
+		return nil
+	}
+	return []int64{get(frame.UDP)}
+}
+
+// icmpValue returns a field of the ICMP header of a frame of the given ICMP version
+func icmpValue(frame *models.Frame, version int, get func(*models.ICMPInfo) int64) []int64 {
+	if frame.ICMP == nil || frame.ICMP.Version != version {
+		return nil
+	}
+	return []int64{get(frame.ICMP)}
+}
+
+// ipv4Number returns a numeric field of the IPv4 header of a frame
+func ipv4Number(frame *models.Frame, get func(*models.IPv4Info) int64) []int64 {
+	if frame.IPv4 == nil {
+		return nil
+	}
+	return []int64{get(frame.IPv4)}
+}
+
+// ipv6Number returns a numeric field of the IPv6 header of a frame
+func ipv6Number(frame *models.Frame, get func(*models.IPv6Info) int64) []int64 {
+	if frame.IPv6 == nil {
+		return nil
+	}
+	return []int64{get(frame.IPv6)}
+}
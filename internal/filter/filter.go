@@ -0,0 +1,187 @@
+// Package filter implements display filters: expressions over the decoded
+// fields of a frame such as
+//
+//	wlan.bssid == aa:bb:cc:dd:ee:ff && qos.tid >= 6 || eth.type == 0x888e
+//
+// Unlike capture (BPF) filters, display filters select frames that were
+// already captured and decoded, so they apply to live and loaded captures alike.
+package filter
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// Filter is a compiled display filter
+type Filter struct {
+	expression string
+	root       node // nil for the empty filter, which matches every frame
+}
+
+// Compile parses a display filter. An empty expression compiles to a filter
+// that matches every frame. Errors are *SyntaxError values.
+func Compile(expression string) (*Filter, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return &Filter{}, nil
+	}
+
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{expression: expression, root: root}, nil
+}
+
+// Matches reports whether a frame satisfies the filter. Fields take their
+// values from the frame and the frames encapsulated in it, and a test is
+// satisfied when any value of the field satisfies it.
+func (f *Filter) Matches(frame *models.Frame) bool {
+	if f.root == nil {
+		return true
+	}
+	return f.root.eval(frame)
+}
+
+// Empty reports whether the filter matches every frame
+func (f *Filter) Empty() bool {
+	return f.root == nil
+}
+
+// String returns the expression the filter was compiled from
+func (f *Filter) String() string {
+	return f.expression
+}
+
+// node is a node of the syntax tree of a filter
+type node interface {
+	eval(frame *models.Frame) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n *andNode) eval(frame *models.Frame) bool { return n.left.eval(frame) && n.right.eval(frame) }
+
+type orNode struct{ left, right node }
+
+func (n *orNode) eval(frame *models.Frame) bool { return n.left.eval(frame) || n.right.eval(frame) }
+
+type notNode struct{ operand node }
+
+func (n *notNode) eval(frame *models.Frame) bool { return !n.operand.eval(frame) }
+
+// existsNode tests whether a field has any value
+type existsNode struct{ field *Field }
+
+func (n *existsNode) eval(frame *models.Frame) bool {
+	return len(n.field.values(frame)) > 0
+}
+
+// compareNode compares a field with a literal. "!=" holds when the field is
+// present and none of its values is equal to the literal.
+type compareNode struct {
+	field *Field
+	op    tokenKind
+	value interface{}
+}
+
+func (n *compareNode) eval(frame *models.Frame) bool {
+	values := n.field.values(frame)
+	if n.op == tokenNe {
+		for _, value := range values {
+			if equal(value, n.value) {
+				return false
+			}
+		}
+		return len(values) > 0
+	}
+
+	for _, value := range values {
+		if n.compare(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare applies the operator to a single value of the field
+func (n *compareNode) compare(value interface{}) bool {
+	if n.op == tokenEq {
+		return equal(value, n.value)
+	}
+
+	// Ordering operators are only accepted for numbers
+	a, b := value.(int64), n.value.(int64)
+	switch n.op {
+	case tokenLt:
+		return a < b
+	case tokenLe:
+		return a <= b
+	case tokenGt:
+		return a > b
+	case tokenGe:
+		return a >= b
+	}
+	return false
+}
+
+// equal compares a value of a field with a literal of the same field type. An
+// IP address is equal to a CIDR network when the network contains it.
+func equal(value, literal interface{}) bool {
+	switch l := literal.(type) {
+	case *net.IPNet:
+		return l.Contains(value.(net.IP))
+	case net.IP:
+		return l.Equal(value.(net.IP))
+	case []byte:
+		return bytes.Equal(value.([]byte), l)
+	default:
+		return value == literal
+	}
+}
+
+// containsNode tests whether a string or byte field contains a byte sequence
+type containsNode struct {
+	field *Field
+	value []byte
+}
+
+func (n *containsNode) eval(frame *models.Frame) bool {
+	for _, value := range n.field.values(frame) {
+		switch v := value.(type) {
+		case string:
+			if strings.Contains(v, string(n.value)) {
+				return true
+			}
+		case []byte:
+			if bytes.Contains(v, n.value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesNode tests whether a string field matches a regular expression
+type matchesNode struct {
+	field *Field
+	re    *regexp.Regexp
+}
+
+func (n *matchesNode) eval(frame *models.Frame) bool {
+	for _, value := range n.field.values(frame) {
+		if n.re.MatchString(value.(string)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// testFrame returns an Ethernet frame carrying the TCP Client Hello of an
+// HTTPS connection, tunneled in VXLAN with an inner IPv4 header
+func testFrame() *models.Frame {
+	return &models.Frame{
+		ID:             7,
+		FrameType:      models.EthernetFrame,
+		SourceMAC:      "00:11:22:33:44:55",
+		DestinationMAC: "00:11:22:33:44:66",
+		EtherType:      0x0800,
+		RawData:        []byte{0x00, 0x11, 0xde, 0xad, 0xbe, 0xef, 'G', 'E', 'T'},
+		Length:         9,
+		IPv4:           &models.IPv4Info{TTL: 64, Protocol: 6, SourceIP: "192.168.1.10", DestinationIP: "10.0.0.1"},
+		TCP:            &models.TCPInfo{SourcePort: 51000, DestinationPort: 443, Flags: models.TCPFlags{SYN: true}},
+		Tunnel:         &models.TunnelInfo{Type: "VXLAN", VNI: 42},
+		Inner: &models.Frame{
+			FrameType: models.EthernetFrame,
+			IPv4:      &models.IPv4Info{TTL: 63, SourceIP: "172.16.0.5", DestinationIP: "172.16.0.6"},
+		},
+		AnalysisResults: map[string]interface{}{
+			"Summary": "TCP 192.168.1.10:51000 → 10.0.0.1:443",
+			"TLS":     map[string]interface{}{"SNI": "example.com"},
+		},
+	}
+}
+
+func TestMatches(t *testing.T) {
+	frame := testFrame()
+	for _, test := range []struct {
+		expression string
+		want       bool
+	}{
+		{"", true},
+
+		// Precedence: ! binds tighter than &&, which binds tighter than ||
+		{"tcp || udp && arp", true},
+		{"(tcp || udp) && arp", false},
+		{"udp && arp || tcp", true},
+		{"udp && (arp || tcp)", false},
+		{"!tcp || ip", true},
+		{"!(tcp || ip)", false},
+		{"!udp && tcp", true},
+		{"!!tcp", true},
+		{"not udp and (tcp or arp)", true},
+		{"((tcp))", true},
+
+		// Protocols
+		{"eth", true},
+		{"ip", true},
+		{"ipv6", false},
+		{"wlan", false},
+
+		// Numbers
+		{"tcp.dstport == 443", true},
+		{"tcp.dstport == 0x1bb", true},
+		{"tcp.dstport eq 443", true},
+		{"tcp.dstport != 443", false},
+		{"tcp.port != 443", false},
+		{"tcp.port != 80", true},
+		{"ip.ttl < 64", true},
+		{"ip.ttl < 63", false},
+		{"ip.ttl <= 63", true},
+		{"ip.ttl > 64", false},
+		{"ip.ttl >= 64", true},
+		{"ip.ttl gt 63", true},
+		{"frame.number == 7", true},
+		{"vxlan.vni == 42", true},
+
+		// Strings
+		{`tls.handshake.sni == "example.com"`, true},
+		{`tls.handshake.sni != "example.com"`, false},
+		{`tls.handshake.sni contains "ample"`, true},
+		{`tls.handshake.sni matches "^ex.*\\.com$"`, true},
+		{`tls.handshake.sni ~ "^www\\."`, false},
+		{`tunnel.type == VXLAN`, true},
+		{`frame.summary contains "10.0.0.1:443"`, true},
+
+		// Booleans
+		{"tcp.flags.syn", true},
+		{"tcp.flags.syn == true", true},
+		{"tcp.flags.syn == 0", false},
+		{"tcp.flags.ack", false},
+		{"tcp.flags.ack == false", true},
+		{"tcp.flags.ack != true", true},
+
+		// MAC addresses, in any notation net.ParseMAC accepts
+		{"eth.src == 00:11:22:33:44:55", true},
+		{"eth.src == 00-11-22-33-44-55", true},
+		{"eth.src == 00:11:22:33:44:66", false},
+		{"eth.addr == 00:11:22:33:44:66", true},
+		{"eth.dst != 00:11:22:33:44:55", true},
+
+		// IP addresses and CIDR networks
+		{"ip.src == 192.168.1.10", true},
+		{"ip.src == 192.168.1.0/24", true},
+		{"ip.dst == 192.168.0.0/16", false},
+		{"ip.addr == 10.0.0.0/8", true},
+		{"ip.dst != 10.0.0.1", false},
+		{"ip.dst != 10.0.0.2", true},
+
+		// Byte sequences
+		{"frame contains de:ad:be:ef", true},
+		{"frame contains DE:AD", true},
+		{`frame contains "GET"`, true},
+		{"frame contains ca:fe", false},
+		{"frame == 00:11:de:ad:be:ef:47:45:54", true},
+		{"frame != 00:11", true},
+
+		// Fields of the tunneled frame
+		{"ip.src == 172.16.0.5", true},
+		{"ip.ttl == 63", true},
+
+		// "!=" does not hold for missing fields, unlike "!(... == ...)"
+		{"udp.port != 53", false},
+		{"!(udp.port == 53)", true},
+		{"ipv6.src != ::1", false},
+		{"arp.gratuitous != true", false},
+		{"wlan.bssid != 00:11:22:33:44:55", false},
+		{`http.host != "example.com"`, false},
+	} {
+		f, err := Compile(test.expression)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.expression, err)
+			continue
+		}
+		if got := f.Matches(frame); got != test.want {
+			t.Errorf("%q matches = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	for _, test := range []struct {
+		expression string
+		column     int
+		message    string
+	}{
+		{"tcp & udp", 5, `expected "&&"`},
+		{"tcp | udp", 5, `expected "||"`},
+		{"tcp.port = 80", 10, `use "==" to test for equality`},
+		{"tcp.port # 1", 10, `unexpected character '#'`},
+		{`"abc`, 1, "unterminated string"},
+		{`tls.handshake.sni == "\q"`, 22, `invalid escape sequence in string "\q"`},
+		{"tcp udp", 5, `expected "&&", "||" or end of filter but found "udp"`},
+		{"tcp)", 4, `unmatched ")"`},
+		{"(tcp || udp", 12, `expected ")" to close the "(" at column 1 but found end of filter`},
+		{"tcp &&", 7, `expected a field or "(" but the filter ended`},
+		{"tcp && == 1", 8, `expected a field or "(" but found "=="`},
+		{"tcp.prt == 80", 1, `unknown field "tcp.prt", did you mean "tcp.port"?`},
+		{"nosuchfield", 1, `unknown field "nosuchfield"`},
+		{"tcp.port ==", 12, `expected a value after "==" but found end of filter`},
+		{"tcp == 1", 5, `"tcp" is a protocol and can only be tested for presence, e.g. "tcp"`},
+		{"ip.src > 1.2.3.4", 8, `">" cannot be applied to the IP address field "ip.src"`},
+		{"eth.src contains 00:11", 9, `"contains" cannot be applied to the MAC address field "eth.src"`},
+		{"tcp.port matches 80", 10, `"matches" cannot be applied to the number field "tcp.port"`},
+		{"tcp.port == abc", 13, `"tcp.port" is a number, expected a decimal or 0x hexadecimal value but found "abc"`},
+		{`tcp.port == "80"`, 13, `"tcp.port" is a number`},
+		{"tcp.flags.syn == yes", 18, `"tcp.flags.syn" is a boolean, expected true or false but found "yes"`},
+		{"eth.src == 00:11:22", 12, `"eth.src" is a MAC address, expected a value like aa:bb:cc:dd:ee:ff but found "00:11:22"`},
+		{"ip.src == 10.0.0.0/33", 11, `"ip.src" is an IP address, expected an address or a CIDR network`},
+		{"frame contains xyz", 16, `expected bytes like de:ad:be:ef or a quoted string but found "xyz"`},
+		{`frame contains ""`, 16, "empty byte sequence"},
+		{`http.host matches "("`, 19, "invalid regular expression"},
+	} {
+		_, err := Compile(test.expression)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Compile(%q) = %v, want a *SyntaxError", test.expression, err)
+			continue
+		}
+		if syntaxErr.Column != test.column || !strings.HasPrefix(syntaxErr.Message, test.message) {
+			t.Errorf("Compile(%q) = column %d %q, want column %d %q", test.expression, syntaxErr.Column, syntaxErr.Message, test.column, test.message)
+		}
+		if want := fmt.Sprintf("column %d: %s", test.column, syntaxErr.Message); err.Error() != want {
+			t.Errorf("Error() = %q, want %q", err.Error(), want)
+		}
+	}
+}
+
+func TestFieldOfUnexpectedType(t *testing.T) {
+	// Analysis results are free-form maps, so values of another type are ignored
+	frame := testFrame()
+	frame.AnalysisResults["TLS"] = map[string]interface{}{"SNI": 42}
+	for _, expression := range []string{"tls.handshake.sni", `tls.handshake.sni == "example.com"`, `tls.handshake.sni matches "."`} {
+		f, err := Compile(expression)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", expression, err)
+		}
+		if f.Matches(frame) {
+			t.Errorf("%q matched a non-string SNI", expression)
+		}
+	}
+}
+
+func TestEmptyFilter(t *testing.T) {
+	f, err := Compile("   ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Empty() || f.String() != "" || !f.Matches(&models.Frame{}) {
+		t.Fatalf("blank filter = %q, empty %v", f.String(), f.Empty())
+	}
+}
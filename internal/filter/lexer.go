@@ -0,0 +1,162 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokenKind is the kind of a lexical token of a display filter
+type tokenKind int
+
+const (
+	tokenEOF    tokenKind = iota
+	tokenWord             // Field name or unquoted value, such as tcp.port, 80 or aa:bb:cc:dd:ee:ff
+	tokenString           // Quoted string, already unescaped
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenEq
+	tokenNe
+	tokenLt
+	tokenLe
+	tokenGt
+	tokenGe
+	tokenContains
+	tokenMatches
+	tokenLParen
+	tokenRParen
+)
+
+// token is a lexical token and the byte offset where it starts
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keywords maps the word forms of the operators to their tokens
+var keywords = map[string]tokenKind{
+	"and":      tokenAnd,
+	"or":       tokenOr,
+	"not":      tokenNot,
+	"eq":       tokenEq,
+	"ne":       tokenNe,
+	"lt":       tokenLt,
+	"le":       tokenLe,
+	"gt":       tokenGt,
+	"ge":       tokenGe,
+	"contains": tokenContains,
+	"matches":  tokenMatches,
+}
+
+// describe returns how a token is named in error messages
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of filter"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// isComparison reports whether the token is a comparison, contains or matches operator
+func (t token) isComparison() bool {
+	return t.kind >= tokenEq && t.kind <= tokenMatches
+}
+
+// lex splits a display filter into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for pos := 0; pos < len(input); {
+		c := input[pos]
+		start := pos
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+			continue
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", start})
+			pos++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", start})
+			pos++
+		case c == '&' || c == '|':
+			if pos+1 >= len(input) || input[pos+1] != c {
+				return nil, &SyntaxError{Column: start + 1, Message: fmt.Sprintf("expected %q", string([]byte{c, c}))}
+			}
+			kind := tokenAnd
+			if c == '|' {
+				kind = tokenOr
+			}
+			tokens = append(tokens, token{kind, input[pos : pos+2], start})
+			pos += 2
+		case c == '=':
+			if pos+1 >= len(input) || input[pos+1] != '=' {
+				return nil, &SyntaxError{Column: start + 1, Message: `use "==" to test for equality`}
+			}
+			tokens = append(tokens, token{tokenEq, "==", start})
+			pos += 2
+		case c == '!' || c == '<' || c == '>':
+			kinds := map[byte][2]tokenKind{'!': {tokenNot, tokenNe}, '<': {tokenLt, tokenLe}, '>': {tokenGt, tokenGe}}[c]
+			if pos+1 < len(input) && input[pos+1] == '=' {
+				tokens = append(tokens, token{kinds[1], input[pos : pos+2], start})
+				pos += 2
+			} else {
+				tokens = append(tokens, token{kinds[0], input[pos : pos+1], start})
+				pos++
+			}
+		case c == '~':
+			tokens = append(tokens, token{tokenMatches, "~", start})
+			pos++
+		case c == '"':
+			end, err := scanString(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			text, err := strconv.Unquote(input[pos:end])
+			if err != nil {
+				return nil, &SyntaxError{Column: start + 1, Message: fmt.Sprintf("invalid escape sequence in string %s", input[pos:end])}
+			}
+			tokens = append(tokens, token{tokenString, text, start})
+			pos = end
+		case isWordChar(c):
+			for pos < len(input) && isWordChar(input[pos]) {
+				pos++
+			}
+			word := input[start:pos]
+			if kind, ok := keywords[strings.ToLower(word)]; ok {
+				tokens = append(tokens, token{kind, word, start})
+			} else {
+				tokens = append(tokens, token{tokenWord, word, start})
+			}
+		default:
+			return nil, &SyntaxError{Column: start + 1, Message: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(input)}), nil
+}
+
+// scanString returns the offset just past the closing quote of the string starting at pos
+func scanString(input string, pos int) (int, error) {
+	for i := pos + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, &SyntaxError{Column: pos + 1, Message: "unterminated string"}
+}
+
+// isWordChar reports whether c can be part of a field name or an unquoted value
+func isWordChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '_' || c == '.' || c == ':' || c == '-' || c == '/'
+}
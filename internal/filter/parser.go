@@ -0,0 +1,293 @@
+package filter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is an error in a display filter and the column (1-based) where it was found
+type SyntaxError struct {
+	Column  int
+	Message string
+}
+
+// Error implements the error interface
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+// parser is a recursive descent parser over the tokens of a display filter.
+// The grammar, from lowest to highest precedence, is:
+//
+//	or         = and { ("||" | "or") and }
+//	and        = not { ("&&" | "and") not }
+//	not        = ("!" | "not") not | primary
+//	primary    = "(" or ")" | field [ operator value ]
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek returns the current token
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and advances past it
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// errorAt returns a syntax error located at a token
+func errorAt(t token, format string, args ...interface{}) error {
+	return &SyntaxError{Column: t.pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// parse parses a whole filter
+func (p *parser) parse() (node, error) {
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		if t.kind == tokenRParen {
+			return nil, errorAt(t, `unmatched ")"`)
+		}
+		return nil, errorAt(t, `expected "&&", "||" or end of filter but found %s`, t.describe())
+	}
+	return root, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != tokenRParen {
+			return nil, errorAt(closing, `expected ")" to close the "(" at column %d but found %s`, t.pos+1, closing.describe())
+		}
+		p.next()
+		return inner, nil
+	case tokenWord:
+		return p.parseTest(t)
+	case tokenEOF:
+		return nil, errorAt(t, "expected a field or \"(\" but the filter ended")
+	default:
+		return nil, errorAt(t, `expected a field or "(" but found %s`, t.describe())
+	}
+}
+
+// parseTest parses a field, optionally followed by an operator and a value
+func (p *parser) parseTest(name token) (node, error) {
+	field, ok := registry[strings.ToLower(name.text)]
+	if !ok {
+		if suggestion := suggestField(name.text); suggestion != "" {
+			return nil, errorAt(name, "unknown field %q, did you mean %q?", name.text, suggestion)
+		}
+		return nil, errorAt(name, "unknown field %q", name.text)
+	}
+
+	op := p.peek()
+	if !op.isComparison() {
+		if field.Type == TypeBool {
+			return &compareNode{field: field, op: tokenEq, value: true}, nil
+		}
+		return &existsNode{field}, nil
+	}
+	p.next()
+
+	if err := checkOperator(field, op); err != nil {
+		return nil, err
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, errorAt(value, "expected a value after %q but found %s", op.text, value.describe())
+	}
+
+	switch op.kind {
+	case tokenMatches:
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, errorAt(value, "invalid regular expression: %v", err)
+		}
+		return &matchesNode{field, re}, nil
+	case tokenContains:
+		if field.Type == TypeBytes {
+			b, err := parseBytes(value)
+			if err != nil {
+				return nil, err
+			}
+			return &containsNode{field: field, value: b}, nil
+		}
+		return &containsNode{field: field, value: []byte(value.text)}, nil
+	}
+
+	literal, err := parseValue(field, value)
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{field: field, op: op.kind, value: literal}, nil
+}
+
+// checkOperator reports whether an operator can be applied to a field
+func checkOperator(field *Field, op token) error {
+	switch {
+	case field.Type == TypeProtocol:
+		return errorAt(op, "%q is a protocol and can only be tested for presence, e.g. %q", field.Name, field.Name)
+	case op.kind >= tokenLt && op.kind <= tokenGe && field.Type != TypeNumber:
+		return errorAt(op, "%q cannot be applied to the %s field %q", op.text, field.Type, field.Name)
+	case op.kind == tokenContains && field.Type != TypeString && field.Type != TypeBytes:
+		return errorAt(op, "\"contains\" cannot be applied to the %s field %q", field.Type, field.Name)
+	case op.kind == tokenMatches && field.Type != TypeString:
+		return errorAt(op, "%q cannot be applied to the %s field %q", op.text, field.Type, field.Name)
+	}
+	return nil
+}
+
+// parseValue parses a literal compared with a field according to the type of the field
+func parseValue(field *Field, value token) (interface{}, error) {
+	switch field.Type {
+	case TypeBool:
+		switch strings.ToLower(value.text) {
+		case "true", "1":
+			return true, nil
+		case "false", "0":
+			return false, nil
+		}
+		return nil, errorAt(value, "%q is a boolean, expected true or false but found %s", field.Name, value.describe())
+	case TypeNumber:
+		n, err := strconv.ParseInt(value.text, 0, 64)
+		if err != nil || value.kind == tokenString {
+			return nil, errorAt(value, "%q is a number, expected a decimal or 0x hexadecimal value but found %s", field.Name, value.describe())
+		}
+		return n, nil
+	case TypeMAC:
+		hw, err := net.ParseMAC(value.text)
+		if err != nil {
+			return nil, errorAt(value, "%q is a MAC address, expected a value like aa:bb:cc:dd:ee:ff but found %s", field.Name, value.describe())
+		}
+		return hw.String(), nil
+	case TypeIP:
+		if ip := net.ParseIP(value.text); ip != nil {
+			return ip, nil
+		}
+		if _, network, err := net.ParseCIDR(value.text); err == nil {
+			return network, nil
+		}
+		return nil, errorAt(value, "%q is an IP address, expected an address or a CIDR network but found %s", field.Name, value.describe())
+	case TypeBytes:
+		return parseBytes(value)
+	default:
+		return value.text, nil
+	}
+}
+
+// parseBytes parses a byte sequence: a quoted string, or hexadecimal bytes
+// separated by colons such as 88:8e or de:ad:be:ef
+func parseBytes(value token) ([]byte, error) {
+	if value.kind == tokenString {
+		if value.text == "" {
+			return nil, errorAt(value, "empty byte sequence")
+		}
+		return []byte(value.text), nil
+	}
+
+	var b []byte
+	for _, part := range strings.Split(value.text, ":") {
+		decoded, err := hex.DecodeString(part)
+		if err != nil || len(decoded) != 1 {
+			return nil, errorAt(value, "expected bytes like de:ad:be:ef or a quoted string but found %s", value.describe())
+		}
+		b = append(b, decoded[0])
+	}
+	return b, nil
+}
+
+// suggestField returns the known field closest to an unknown name, if any is close enough
+func suggestField(name string) string {
+	name = strings.ToLower(name)
+	best, bestDistance := "", 3
+	for candidate := range registry {
+		if d := editDistance(name, candidate); d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// dhcpOptRelayAgentInfo is the DHCP relay agent information option (option 82)
+const dhcpOptRelayAgentInfo layers.DHCPOpt = 82
+
+// parseDHCPv4 converts a decoded DHCPv4 layer into the frame model
+func parseDHCPv4(dhcp *layers.DHCPv4) *models.DHCPInfo {
+	info := &models.DHCPInfo{
+		Version:       4,
+		TransactionID: dhcp.Xid,
+		ClientMAC:     dhcp.ClientHWAddr.String(),
+		ClientIP:      dhcp.ClientIP.String(),
+		YourIP:        dhcp.YourClientIP.String(),
+		ServerIP:      dhcp.NextServerIP.String(),
+		RelayIP:       dhcp.RelayAgentIP.String(),
+	}
+
+	// BOOTP messages without option 53 are named after the operation
+	if dhcp.Operation == layers.DHCPOpRequest {
+		info.MessageType = "BOOTREQUEST"
+	} else {
+		info.MessageType = "BOOTREPLY"
+	}
+
+	for _, option := range dhcp.Options {
+		if option.Type == layers.DHCPOptPad || option.Type == layers.DHCPOptEnd {
+			continue
+		}
+
+		info.Options = append(info.Options, models.DHCPOption{
+			Code: uint16(option.Type),
+			Name: dhcpOptionName(option.Type),
+			Data: option.Data,
+		})
+
+		switch option.Type {
+		case layers.DHCPOptMessageType:
+			if len(option.Data) == 1 {
+				info.MessageType = layers.DHCPMsgType(option.Data[0]).String()
+			}
+		case layers.DHCPOptRequestIP:
+			info.RequestedIP = ipString(option.Data)
+		case layers.DHCPOptHostname:
+			info.Hostname = string(option.Data)
+		case layers.DHCPOptClassID:
+			info.VendorClass = string(option.Data)
+		case layers.DHCPOptServerID:
+			info.ServerIdentifier = ipString(option.Data)
+		case layers.DHCPOptLeaseTime:
+			if len(option.Data) == 4 {
+				info.LeaseTime = binary.BigEndian.Uint32(option.Data)
+			}
+		case layers.DHCPOptSubnetMask:
+			info.SubnetMask = ipString(option.Data)
+		case layers.DHCPOptRouter:
+			info.Routers = ipList(option.Data, net.IPv4len)
+		case layers.DHCPOptDNS:
+			info.DNSServers = ipList(option.Data, net.IPv4len)
+		case dhcpOptRelayAgentInfo:
+			parseRelayAgentInfo(info, option.Data)
+		}
+	}
+
+	return info
+}
+
+// dhcpOptionName returns the name of a DHCPv4 option
+func dhcpOptionName(code layers.DHCPOpt) string {
+	if code == dhcpOptRelayAgentInfo {
+		return "RelayAgentInfo"
+	}
+	return code.String()
+}
+
+// parseRelayAgentInfo decodes the circuit and remote ID sub-options of option 82
+func parseRelayAgentInfo(info *models.DHCPInfo, data []byte) {
+	for len(data) >= 2 {
+		subType, length := data[0], int(data[1])
+		if len(data) < 2+length {
+			return
+		}
+		value := data[2 : 2+length]
+
+		switch subType {
+		case 1:
+			info.CircuitID = printableOrHex(value)
+		case 2:
+			info.RemoteID = printableOrHex(value)
+		}
+
+		data = data[2+length:]
+	}
+}
+
+// parseDHCPv6 converts a decoded DHCPv6 layer into the frame model
+func parseDHCPv6(dhcp *layers.DHCPv6) *models.DHCPInfo {
+	info := &models.DHCPInfo{
+		Version:     6,
+		MessageType: dhcpv6MessageTypeName(dhcp.MsgType),
+	}
+
+	if len(dhcp.TransactionID) == 3 {
+		info.TransactionID = uint32(dhcp.TransactionID[0])<<16 | uint32(dhcp.TransactionID[1])<<8 | uint32(dhcp.TransactionID[2])
+	}
+
+	// Relay messages carry the link and peer addresses instead of a transaction ID
+	if dhcp.MsgType == layers.DHCPv6MsgTypeRelayForward || dhcp.MsgType == layers.DHCPv6MsgTypeRelayReply {
+		info.RelayIP = dhcp.LinkAddr.String()
+		info.ClientIP = dhcp.PeerAddr.String()
+	}
+
+	for _, option := range dhcp.Options {
+		info.Options = append(info.Options, models.DHCPOption{
+			Code: uint16(option.Code),
+			Name: option.Code.String(),
+			Data: option.Data,
+		})
+
+		switch option.Code {
+		case layers.DHCPv6OptClientID:
+			info.ClientIdentifier = hex.EncodeToString(option.Data)
+			info.ClientMAC = duidLinkLayerAddress(option.Data)
+		case layers.DHCPv6OptServerID:
+			info.ServerIdentifier = hex.EncodeToString(option.Data)
+		case layers.DHCPv6OptIANA:
+			parseIANA(info, option.Data)
+		case layers.DHCPv6OptVendorClass:
+			// Enterprise number followed by length-prefixed class data
+			if len(option.Data) > 6 {
+				info.VendorClass = printableOrHex(option.Data[6:])
+			}
+		case layers.DHCPv6OptDNSServers:
+			info.DNSServers = ipList(option.Data, net.IPv6len)
+		case layers.DHCPv6OptClientFQDN:
+			// Flags byte followed by a DNS encoded name
+			if len(option.Data) > 1 {
+				info.Hostname = decodeDNSLabels(option.Data[1:])
+			}
+		}
+	}
+
+	return info
+}
+
+// dhcpv6MessageTypeName returns the RFC 8415 name of a DHCPv6 message type
+func dhcpv6MessageTypeName(msgType layers.DHCPv6MsgType) string {
+	if msgType == layers.DHCPv6MsgTypeAdverstise {
+		return "Advertise"
+	}
+	return msgType.String()
+}
+
+// parseIANA extracts the leased address and lifetime from an IA_NA option
+func parseIANA(info *models.DHCPInfo, data []byte) {
+	// IAID, T1 and T2 precede the encapsulated options
+	if len(data) < 12 {
+		return
+	}
+	data = data[12:]
+
+	for len(data) >= 4 {
+		code := layers.DHCPv6Opt(binary.BigEndian.Uint16(data[0:2]))
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length {
+			return
+		}
+		value := data[4 : 4+length]
+
+		// IA Address: address, preferred lifetime and valid lifetime
+		if code == layers.DHCPv6OptIAAddr && len(value) >= 24 {
+			info.YourIP = net.IP(value[0:16]).String()
+			info.LeaseTime = binary.BigEndian.Uint32(value[20:24])
+		}
+
+		data = data[4+length:]
+	}
+}
+
+// duidLinkLayerAddress returns the MAC embedded in a DUID-LLT or DUID-LL, if any
+func duidLinkLayerAddress(duid []byte) string {
+	if len(duid) < 4 {
+		return ""
+	}
+
+	switch binary.BigEndian.Uint16(duid[0:2]) {
+	case 1: // DUID-LLT: type, hardware type, time, link-layer address
+		if len(duid) >= 14 {
+			return net.HardwareAddr(duid[8:14]).String()
+		}
+	case 3: // DUID-LL: type, hardware type, link-layer address
+		if len(duid) >= 10 {
+			return net.HardwareAddr(duid[4:10]).String()
+		}
+	}
+
+	return ""
+}
+
+// decodeDNSLabels decodes an uncompressed DNS encoded name
+func decodeDNSLabels(data []byte) string {
+	var labels []string
+	for len(data) > 0 {
+		length := int(data[0])
+		if length == 0 || len(data) < 1+length {
+			break
+		}
+		labels = append(labels, string(data[1:1+length]))
+		data = data[1+length:]
+	}
+	return strings.Join(labels, ".")
+}
+
+// ipString formats a 4 or 16 byte address, returning an empty string otherwise
+func ipString(data []byte) string {
+	if len(data) != net.IPv4len && len(data) != net.IPv6len {
+		return ""
+	}
+	return net.IP(data).String()
+}
+
+// ipList splits a list of fixed-size addresses
+func ipList(data []byte, size int) []string {
+	var ips []string
+	for i := 0; i+size <= len(data); i += size {
+		ips = append(ips, net.IP(data[i:i+size]).String())
+	}
+	return ips
+}
+
+// printableOrHex returns data as text when it is printable ASCII, and as hex otherwise
+func printableOrHex(data []byte) string {
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return hex.EncodeToString(data)
+		}
+	}
+	return string(data)
+}
@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// parseLLDP converts the decoded LLDP layers of a packet into the frame model
+func parseLLDP(packet gopacket.Packet) *models.DiscoveryInfo {
+	lldpLayer := packet.Layer(layers.LayerTypeLinkLayerDiscovery)
+	if lldpLayer == nil {
+		return nil
+	}
+	lldp, _ := lldpLayer.(*layers.LinkLayerDiscovery)
+
+	info := &models.DiscoveryInfo{
+		Protocol:      "LLDP",
+		ChassisID:     lldpID(byte(lldp.ChassisID.Subtype), lldp.ChassisID.ID, byte(layers.LLDPChassisIDSubTypeMACAddr), byte(layers.LLDPChassisIDSubTypeNetworkAddr)),
+		ChassisIDType: lldp.ChassisID.Subtype.String(),
+		PortID:        lldpID(byte(lldp.PortID.Subtype), lldp.PortID.ID, byte(layers.LLDPPortIDSubtypeMACAddr), byte(layers.LLDPPortIDSubtypeNetworkAddr)),
+		PortIDType:    lldp.PortID.Subtype.String(),
+		TTL:           lldp.TTL,
+	}
+
+	infoLayer := packet.Layer(layers.LayerTypeLinkLayerDiscoveryInfo)
+	if infoLayer == nil {
+		return info
+	}
+	details, _ := infoLayer.(*layers.LinkLayerDiscoveryInfo)
+
+	info.PortDescription = details.PortDescription
+	info.SystemName = details.SysName
+	info.SystemDescription = details.SysDescription
+	info.Capabilities = lldpCapabilities(details.SysCapabilities.SystemCap)
+	info.EnabledCapabilities = lldpCapabilities(details.SysCapabilities.EnabledCap)
+
+	if address := details.MgmtAddress; len(address.Address) > 0 {
+		switch address.Subtype {
+		case layers.IANAAddressFamilyIPV4, layers.IANAAddressFamilyIPV6:
+			info.ManagementAddresses = append(info.ManagementAddresses, net.IP(address.Address).String())
+		case layers.IANAAddressFamily802:
+			info.ManagementAddresses = append(info.ManagementAddresses, net.HardwareAddr(address.Address).String())
+		}
+	}
+
+	for _, tlv := range details.OrgTLVs {
+		switch {
+		case tlv.OUI == layers.IEEEOUI8021 && tlv.SubType == layers.LLDP8021SubtypePortVLANID && len(tlv.Info) >= 2:
+			info.VLAN = binary.BigEndian.Uint16(tlv.Info[0:2])
+		case tlv.OUI == layers.IEEEOUI8023 && tlv.SubType == layers.LLDP8023SubtypeMDIPower:
+			info.PoE = parseLLDPPower(tlv.Info)
+		}
+	}
+
+	return info
+}
+
+// lldpID formats a chassis or port ID according to its subtype
+func lldpID(subtype byte, id []byte, macSubtype byte, networkSubtype byte) string {
+	switch {
+	case subtype == macSubtype && len(id) == 6:
+		return net.HardwareAddr(id).String()
+	case subtype == networkSubtype && len(id) > 1:
+		// Network addresses are prefixed with their IANA address family
+		if ip := ipString(id[1:]); ip != "" {
+			return ip
+		}
+	}
+	return printableOrHex(id)
+}
+
+// parseLLDPPower decodes the IEEE 802.3 Power via MDI TLV
+func parseLLDPPower(data []byte) *models.PoEInfo {
+	if len(data) < 3 {
+		return nil
+	}
+
+	poe := &models.PoEInfo{
+		Class: data[2],
+	}
+
+	// Type 2 extension: power type/source/priority and requested/allocated power in 0.1 W
+	if len(data) >= 8 {
+		powerType := layers.LLDPPowerType((data[3] & 0xc0) >> 6)
+		source := layers.LLDPPowerSource((data[3] & 0x30) >> 4)
+		if powerType == 1 || powerType == 3 {
+			source += 128 // PD sources are offset for their string representation
+		}
+
+		poe.PowerType = powerType.String()
+		poe.PowerSource = source.String()
+		poe.PowerPriority = layers.LLDPPowerPriority(data[3] & 0x0f).String()
+		poe.RequestedWatts = float64(binary.BigEndian.Uint16(data[4:6])) / 10
+		poe.AllocatedWatts = float64(binary.BigEndian.Uint16(data[6:8])) / 10
+	}
+
+	return poe
+}
+
+// lldpCapabilities lists the names of the capabilities that are set
+func lldpCapabilities(capabilities layers.LLDPCapabilities) []string {
+	var names []string
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{capabilities.Other, "Other"},
+		{capabilities.Repeater, "Repeater"},
+		{capabilities.Bridge, "Bridge"},
+		{capabilities.WLANAP, "WLAN AP"},
+		{capabilities.Router, "Router"},
+		{capabilities.Phone, "Phone"},
+		{capabilities.DocSis, "DOCSIS"},
+		{capabilities.StationOnly, "Station"},
+		{capabilities.CVLAN, "C-VLAN"},
+		{capabilities.SVLAN, "S-VLAN"},
+		{capabilities.TMPR, "TPMR"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			names = append(names, flag.name)
+		}
+	}
+	return names
+}
+
+// parseCDP converts the decoded CDP layers of a packet into the frame model
+func parseCDP(packet gopacket.Packet) *models.DiscoveryInfo {
+	cdpLayer := packet.Layer(layers.LayerTypeCiscoDiscovery)
+	if cdpLayer == nil {
+		return nil
+	}
+	cdp, _ := cdpLayer.(*layers.CiscoDiscovery)
+
+	info := &models.DiscoveryInfo{
+		Protocol: "CDP",
+		TTL:      uint16(cdp.TTL),
+	}
+
+	infoLayer := packet.Layer(layers.LayerTypeCiscoDiscoveryInfo)
+	if infoLayer == nil {
+		return info
+	}
+	details, _ := infoLayer.(*layers.CiscoDiscoveryInfo)
+
+	info.ChassisID = details.DeviceID
+	info.ChassisIDType = "Device ID"
+	info.PortID = details.PortID
+	info.PortIDType = "Interface Name"
+	info.SystemName = details.SysName
+	info.SystemDescription = details.Version
+	info.Platform = details.Platform
+	info.VLAN = details.NativeVLAN
+	info.Capabilities = cdpCapabilities(details.Capabilities)
+	info.EnabledCapabilities = info.Capabilities
+
+	for _, address := range details.MgmtAddresses {
+		info.ManagementAddresses = appendUnique(info.ManagementAddresses, address.String())
+	}
+	for _, address := range details.Addresses {
+		info.ManagementAddresses = appendUnique(info.ManagementAddresses, address.String())
+	}
+
+	if details.PowerConsumption > 0 {
+		// CDP reports power consumption in mW
+		info.PoE = &models.PoEInfo{
+			RequestedWatts: float64(details.PowerConsumption) / 1000,
+		}
+	}
+
+	return info
+}
+
+// cdpCapabilities lists the names of the CDP capabilities that are set
+func cdpCapabilities(capabilities layers.CDPCapabilities) []string {
+	var names []string
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{capabilities.L3Router, "Router"},
+		{capabilities.TBBridge, "Trans Bridge"},
+		{capabilities.SPBridge, "Source Route Bridge"},
+		{capabilities.L2Switch, "Switch"},
+		{capabilities.IsHost, "Host"},
+		{capabilities.IGMPFilter, "IGMP"},
+		{capabilities.L1Repeater, "Repeater"},
+		{capabilities.IsPhone, "Phone"},
+		{capabilities.RemotelyManaged, "Remotely Managed"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			names = append(names, flag.name)
+		}
+	}
+	return names
+}
+
+// appendUnique appends value to values unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// Well-known ports of the name resolution protocols
+const (
+	dnsPort   = 53
+	mdnsPort  = 5353
+	llmnrPort = 5355
+)
+
+// dnsProtocol returns the name resolution protocol carried on the given ports, if any
+func dnsProtocol(srcPort uint16, dstPort uint16) string {
+	switch {
+	case srcPort == mdnsPort || dstPort == mdnsPort:
+		return "mDNS"
+	case srcPort == llmnrPort || dstPort == llmnrPort:
+		return "LLMNR"
+	case srcPort == dnsPort || dstPort == dnsPort:
+		return "DNS"
+	default:
+		return ""
+	}
+}
+
+// parseDNSPayload decodes the DNS, mDNS or LLMNR message carried by the frame's
+// transport payload when gopacket did not decode it as part of the packet
+func parseDNSPayload(frame *models.Frame) {
+	srcPort, dstPort, ok := frame.Ports()
+	if !ok {
+		return
+	}
+
+	protocol := dnsProtocol(srcPort, dstPort)
+	payload := frame.ApplicationPayload()
+	if protocol == "" || len(payload) == 0 {
+		return
+	}
+
+	// DNS over TCP prefixes each message with its length
+	if frame.TCP != nil {
+		if len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != len(payload)-2 {
+			return
+		}
+		payload = payload[2:]
+	}
+
+	dns := &layers.DNS{}
+	if err := dns.DecodeFromBytes(payload, gopacket.NilDecodeFeedback); err != nil {
+		return
+	}
+
+	frame.DNS = parseDNS(dns, protocol)
+}
+
+// parseDNS converts a decoded DNS layer into the frame model
+func parseDNS(dns *layers.DNS, protocol string) *models.DNSInfo {
+	info := &models.DNSInfo{
+		Protocol:           protocol,
+		ID:                 dns.ID,
+		Response:           dns.QR,
+		OpCode:             dns.OpCode.String(),
+		Authoritative:      dns.AA,
+		Truncated:          dns.TC,
+		RecursionDesired:   dns.RD,
+		RecursionAvailable: dns.RA,
+		ResponseCode:       uint8(dns.ResponseCode),
+	}
+
+	for _, question := range dns.Questions {
+		info.Questions = append(info.Questions, models.DNSQuestion{
+			Name:  dnsName(question.Name),
+			Type:  question.Type.String(),
+			Class: dnsClassName(question.Class, protocol),
+		})
+	}
+
+	info.Answers = parseDNSRecords(dns.Answers, protocol)
+	info.Authorities = parseDNSRecords(dns.Authorities, protocol)
+
+	for _, record := range dns.Additionals {
+		if record.Type == layers.DNSTypeOPT {
+			info.EDNS = parseEDNS(record)
+			continue
+		}
+		info.Additionals = append(info.Additionals, parseDNSRecord(record, protocol))
+	}
+
+	// EDNS extends the response code with 8 high bits
+	rcode := uint16(info.ResponseCode)
+	if info.EDNS != nil {
+		rcode |= uint16(info.EDNS.ExtendedRCode) << 4
+	}
+	info.ResponseCodeName = dnsResponseCodeName(rcode)
+
+	return info
+}
+
+// parseDNSRecords converts a section of resource records into the frame model
+func parseDNSRecords(records []layers.DNSResourceRecord, protocol string) []models.DNSRecord {
+	var parsed []models.DNSRecord
+	for _, record := range records {
+		parsed = append(parsed, parseDNSRecord(record, protocol))
+	}
+	return parsed
+}
+
+// parseDNSRecord converts a resource record into the frame model
+func parseDNSRecord(record layers.DNSResourceRecord, protocol string) models.DNSRecord {
+	return models.DNSRecord{
+		Name:  dnsName(record.Name),
+		Type:  record.Type.String(),
+		Class: dnsClassName(record.Class, protocol),
+		TTL:   record.TTL,
+		Data:  dnsRecordData(record),
+	}
+}
+
+// dnsRecordData formats the RDATA of a resource record in presentation format
+func dnsRecordData(record layers.DNSResourceRecord) string {
+	switch record.Type {
+	case layers.DNSTypeA, layers.DNSTypeAAAA:
+		return record.IP.String()
+	case layers.DNSTypeNS:
+		return dnsName(record.NS)
+	case layers.DNSTypeCNAME:
+		return dnsName(record.CNAME)
+	case layers.DNSTypePTR:
+		return dnsName(record.PTR)
+	case layers.DNSTypeMX:
+		return fmt.Sprintf("%d %s", record.MX.Preference, dnsName(record.MX.Name))
+	case layers.DNSTypeSRV:
+		return fmt.Sprintf("%d %d %d %s", record.SRV.Priority, record.SRV.Weight, record.SRV.Port, dnsName(record.SRV.Name))
+	case layers.DNSTypeSOA:
+		return fmt.Sprintf("%s %s %d", dnsName(record.SOA.MName), dnsName(record.SOA.RName), record.SOA.Serial)
+	case layers.DNSTypeTXT:
+		texts := make([]string, 0, len(record.TXTs))
+		for _, txt := range record.TXTs {
+			texts = append(texts, fmt.Sprintf("%q", txt))
+		}
+		return strings.Join(texts, " ")
+	default:
+		return fmt.Sprintf("%x", record.Data)
+	}
+}
+
+// parseEDNS decodes the EDNS(0) parameters of an OPT pseudo-record (RFC 6891)
+func parseEDNS(record layers.DNSResourceRecord) *models.EDNSInfo {
+	edns := &models.EDNSInfo{
+		UDPSize:       uint16(record.Class),
+		ExtendedRCode: uint8(record.TTL >> 24),
+		Version:       uint8(record.TTL >> 16),
+		DNSSECOK:      record.TTL&0x8000 != 0,
+	}
+
+	for _, option := range record.OPT {
+		edns.Options = append(edns.Options, option.Code.String())
+	}
+
+	return edns
+}
+
+// dnsName returns a domain name, using "." for the root
+func dnsName(name []byte) string {
+	if len(name) == 0 {
+		return "."
+	}
+	return string(name)
+}
+
+// dnsClassName returns the name of a DNS class. mDNS uses the top bit of the
+// class as the unicast-response or cache-flush flag (RFC 6762).
+func dnsClassName(class layers.DNSClass, protocol string) string {
+	if protocol == "mDNS" && class&0x8000 != 0 {
+		return (class &^ 0x8000).String() + " (QU/flush)"
+	}
+	return class.String()
+}
+
+// dnsResponseCodeName returns the mnemonic of a DNS response code
+func dnsResponseCodeName(rcode uint16) string {
+	switch rcode {
+	case 0:
+		return "NOERROR"
+	case 1:
+		return "FORMERR"
+	case 2:
+		return "SERVFAIL"
+	case 3:
+		return "NXDOMAIN"
+	case 4:
+		return "NOTIMP"
+	case 5:
+		return "REFUSED"
+	case 6:
+		return "YXDOMAIN"
+	case 7:
+		return "YXRRSET"
+	case 8:
+		return "NXRRSET"
+	case 9:
+		return "NOTAUTH"
+	case 10:
+		return "NOTZONE"
+	case 16:
+		return "BADVERS"
+	default:
+		return fmt.Sprintf("RCODE%d", rcode)
+	}
+}
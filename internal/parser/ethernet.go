@@ -0,0 +1,297 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// PPP protocol numbers carried in PPPoE sessions
+const (
+	pppProtocolIPv4 uint16 = 0x0021
+	pppProtocolIPv6 uint16 = 0x0057
+	pppProtocolLCP  uint16 = 0xC021
+	pppProtocolIPCP uint16 = 0x8021
+)
+
+// parseMPLS decodes the MPLS label stack starting at offset and returns the
+// offset of the payload that follows the bottom of the stack
+func parseMPLS(frame *models.Frame, offset int) int {
+	data := frame.RawData
+	for offset+4 <= len(data) {
+		entry := binary.BigEndian.Uint32(data[offset : offset+4])
+		label := models.MPLSLabel{
+			Label:         entry >> 12,
+			TrafficClass:  uint8(entry>>9) & 0x7,
+			BottomOfStack: entry&0x100 != 0,
+			TTL:           uint8(entry),
+		}
+		frame.MPLSLabels = append(frame.MPLSLabels, label)
+		offset += 4
+
+		if label.BottomOfStack {
+			break
+		}
+	}
+	return offset
+}
+
+// parsePPPoE decodes the PPPoE header starting at offset and returns the
+// offset of the PPP payload for session packets
+func parsePPPoE(frame *models.Frame, offset int) int {
+	data := frame.RawData
+	if offset+6 > len(data) {
+		return offset
+	}
+
+	header := data[offset : offset+6]
+	pppoe := &models.PPPoEInfo{
+		Version:   header[0] >> 4,
+		Type:      header[0] & 0x0F,
+		Code:      header[1],
+		CodeName:  pppoeCodeName(header[1]),
+		SessionID: binary.BigEndian.Uint16(header[2:4]),
+		Length:    binary.BigEndian.Uint16(header[4:6]),
+	}
+	frame.PPPoE = pppoe
+	offset += 6
+
+	end := offset + int(pppoe.Length)
+	if end > len(data) {
+		end = len(data)
+	}
+	payload := data[offset:end]
+
+	// Discovery packets carry tags instead of PPP
+	if frame.EtherType == 0x8863 {
+		pppoe.Tags = parsePPPoETags(payload)
+		return offset
+	}
+
+	if len(payload) < 1 {
+		return offset
+	}
+
+	// The protocol field may be compressed to a single (odd) byte
+	protocolLength := 2
+	if payload[0]&0x01 != 0 {
+		protocolLength = 1
+		pppoe.PPPProtocol = uint16(payload[0])
+	} else if len(payload) >= 2 {
+		pppoe.PPPProtocol = binary.BigEndian.Uint16(payload[0:2])
+	} else {
+		return offset
+	}
+	pppoe.PPPProtocolName = pppProtocolName(pppoe.PPPProtocol)
+
+	switch pppoe.PPPProtocol {
+	case pppProtocolLCP:
+		pppoe.Control = parsePPPControl("LCP", payload[protocolLength:])
+	case pppProtocolIPCP:
+		pppoe.Control = parsePPPControl("IPCP", payload[protocolLength:])
+	}
+
+	return offset + protocolLength
+}
+
+// parsePPPoETags decodes the tags of a PPPoE discovery packet
+func parsePPPoETags(data []byte) []models.PPPoETag {
+	var tags []models.PPPoETag
+	for len(data) >= 4 {
+		tagType := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length || tagType == 0x0000 {
+			break
+		}
+		value := data[4 : 4+length]
+
+		tag := models.PPPoETag{
+			Type: tagType,
+			Name: pppoeTagName(tagType),
+		}
+		switch tagType {
+		case 0x0101, 0x0102, 0x0201, 0x0202, 0x0203: // Names and error messages are UTF-8
+			tag.Value = string(value)
+		default:
+			tag.Value = fmt.Sprintf("%x", value)
+		}
+		tags = append(tags, tag)
+
+		data = data[4+length:]
+	}
+	return tags
+}
+
+// parsePPPControl decodes an LCP or IPCP packet
+func parsePPPControl(protocol string, data []byte) *models.PPPControlInfo {
+	if len(data) < 4 {
+		return nil
+	}
+
+	control := &models.PPPControlInfo{
+		Protocol:   protocol,
+		Code:       data[0],
+		CodeName:   pppControlCodeName(data[0]),
+		Identifier: data[1],
+	}
+
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	if length > len(data) {
+		length = len(data)
+	}
+
+	// Only the Configure-* packets carry options
+	if control.Code < 1 || control.Code > 4 {
+		return control
+	}
+
+	options := data[4:length]
+	for len(options) >= 2 {
+		optionType, optionLength := options[0], int(options[1])
+		if optionLength < 2 || optionLength > len(options) {
+			break
+		}
+		value := options[2:optionLength]
+
+		control.Options = append(control.Options, pppOption(protocol, optionType, value))
+		options = options[optionLength:]
+	}
+
+	return control
+}
+
+// pppOption names and formats an LCP or IPCP configuration option
+func pppOption(protocol string, optionType uint8, value []byte) models.PPPOption {
+	option := models.PPPOption{
+		Type:  optionType,
+		Name:  fmt.Sprintf("Option %d", optionType),
+		Value: fmt.Sprintf("%x", value),
+	}
+
+	if protocol == "LCP" {
+		switch optionType {
+		case 1:
+			option.Name = "MRU"
+			if len(value) == 2 {
+				option.Value = fmt.Sprintf("%d", binary.BigEndian.Uint16(value))
+			}
+		case 3:
+			option.Name = "Authentication-Protocol"
+			if len(value) >= 2 {
+				option.Value = pppProtocolName(binary.BigEndian.Uint16(value))
+				if len(value) == 3 && value[2] == 5 {
+					option.Value += " (MD5)"
+				}
+			}
+		case 5:
+			option.Name = "Magic-Number"
+		case 7:
+			option.Name = "Protocol-Field-Compression"
+		case 8:
+			option.Name = "Address-Control-Field-Compression"
+		}
+		return option
+	}
+
+	switch optionType {
+	case 2:
+		option.Name = "IP-Compression-Protocol"
+	case 3:
+		option.Name = "IP-Address"
+	case 129:
+		option.Name = "Primary-DNS"
+	case 130:
+		option.Name = "Primary-NBNS"
+	case 131:
+		option.Name = "Secondary-DNS"
+	case 132:
+		option.Name = "Secondary-NBNS"
+	}
+	if optionType != 2 && len(value) == net.IPv4len {
+		option.Value = net.IP(value).String()
+	}
+	return option
+}
+
+// pppoeCodeName returns the name of a PPPoE code
+func pppoeCodeName(code uint8) string {
+	switch code {
+	case 0x00:
+		return "Session"
+	case 0x09:
+		return "PADI"
+	case 0x07:
+		return "PADO"
+	case 0x19:
+		return "PADR"
+	case 0x65:
+		return "PADS"
+	case 0xA7:
+		return "PADT"
+	default:
+		return fmt.Sprintf("Unknown (0x%02x)", code)
+	}
+}
+
+// pppoeTagName returns the name of a PPPoE discovery tag
+func pppoeTagName(tagType uint16) string {
+	switch tagType {
+	case 0x0101:
+		return "Service-Name"
+	case 0x0102:
+		return "AC-Name"
+	case 0x0103:
+		return "Host-Uniq"
+	case 0x0104:
+		return "AC-Cookie"
+	case 0x0105:
+		return "Vendor-Specific"
+	case 0x0110:
+		return "Relay-Session-Id"
+	case 0x0120:
+		return "PPP-Max-Payload"
+	case 0x0201:
+		return "Service-Name-Error"
+	case 0x0202:
+		return "AC-System-Error"
+	case 0x0203:
+		return "Generic-Error"
+	default:
+		return fmt.Sprintf("Tag 0x%04x", tagType)
+	}
+}
+
+// pppProtocolName returns the name of a PPP protocol number
+func pppProtocolName(protocol uint16) string {
+	switch protocol {
+	case pppProtocolIPv4:
+		return "IPv4"
+	case pppProtocolIPv6:
+		return "IPv6"
+	case pppProtocolLCP:
+		return "LCP"
+	case pppProtocolIPCP:
+		return "IPCP"
+	case 0x8057:
+		return "IPv6CP"
+	case 0xC023:
+		return "PAP"
+	case 0xC223:
+		return "CHAP"
+	default:
+		return fmt.Sprintf("0x%04x", protocol)
+	}
+}
+
+// pppControlCodeName returns the name of an LCP or IPCP code
+func pppControlCodeName(code uint8) string {
+	names := []string{"", "Configure-Request", "Configure-Ack", "Configure-Nak", "Configure-Reject",
+		"Terminate-Request", "Terminate-Ack", "Code-Reject", "Protocol-Reject",
+		"Echo-Request", "Echo-Reply", "Discard-Request"}
+	if int(code) < len(names) && code != 0 {
+		return names[code]
+	}
+	return fmt.Sprintf("Code %d", code)
+}
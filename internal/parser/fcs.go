@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// fcsLength is the length of the IEEE 802.3 and 802.11 frame check sequence
+const fcsLength = 4
+
+// minEthernetFCSFrame is the shortest frame (without FCS) for which the FCS is auto-detected,
+// shorter frames are unpadded host frames that never carry one
+const minEthernetFCSFrame = 60
+
+// fcsAutoConfirm is the number of frames with a valid FCS after which auto mode
+// assumes that every frame of the capture carries one, so that a mismatch is a bad FCS
+const fcsAutoConfirm = 8
+
+// FCSMode tells the parser whether captured Ethernet frames end with their FCS
+type FCSMode int
+
+const (
+	// FCSAuto assumes an FCS only when the trailing 4 bytes match the CRC32 of
+	// the frame, until fcsAutoConfirm frames matched; from then on it behaves
+	// as FCSPresent
+	FCSAuto FCSMode = iota
+	// FCSPresent assumes every frame ends with its FCS, as captured by taps
+	FCSPresent
+	// FCSAbsent never looks for an FCS
+	FCSAbsent
+)
+
+// ParseFCSMode parses an FCS mode name: "auto", "present" or "absent"
+func ParseFCSMode(s string) (FCSMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return FCSAuto, nil
+	case "present":
+		return FCSPresent, nil
+	case "absent":
+		return FCSAbsent, nil
+	default:
+		return FCSAuto, fmt.Errorf("unknown FCS mode %q (use auto, present or absent)", s)
+	}
+}
+
+// checkEthernetFCS locates and validates the FCS at the end of an Ethernet frame
+func (fp *FrameParser) checkEthernetFCS(frame *models.Frame) {
+	mode := fp.fcsMode
+	if mode == FCSAuto && fp.fcsMatches >= fcsAutoConfirm {
+		mode = FCSPresent
+	}
+	if mode == FCSAbsent || len(frame.RawData) <= fcsLength {
+		return
+	}
+
+	fcs := computeFCS(frame.RawData)
+	if mode == FCSAuto {
+		if len(frame.RawData)-fcsLength < minEthernetFCSFrame || !fcs.Valid {
+			return
+		}
+		fp.fcsMatches++
+	}
+	frame.FCS = fcs
+}
+
+// checkWLANFCS validates the FCS of an 802.11 frame whose radiotap header announces one
+func checkWLANFCS(frame *models.Frame) {
+	radioTap := frame.RadioTap
+	if radioTap == nil || !radioTap.FCSIncluded || radioTap.Length > len(frame.RawData) {
+		return
+	}
+
+	data := frame.RawData[radioTap.Length:]
+	if len(data) <= fcsLength {
+		return
+	}
+
+	frame.FCS = computeFCS(data)
+	if radioTap.BadFCS {
+		frame.FCS.Valid = false
+	}
+}
+
+// computeFCS compares the trailing FCS of data with the CRC32 of the bytes before it
+func computeFCS(data []byte) *models.FCSInfo {
+	n := len(data) - fcsLength
+	fcs := &models.FCSInfo{
+		Value:    binary.LittleEndian.Uint32(data[n:]),
+		Computed: crc32.ChecksumIEEE(data[:n]),
+		Length:   n + fcsLength,
+	}
+	fcs.Valid = fcs.Value == fcs.Computed
+	return fcs
+}
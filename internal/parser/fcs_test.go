@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// ethernetFrameWithFCS returns a 64 byte Ethernet frame ending with its FCS,
+// corrupted when bad is set
+func ethernetFrameWithFCS(bad bool) *models.Frame {
+	data := make([]byte, minEthernetFCSFrame)
+	copy(data, []byte{0, 1, 2, 3, 4, 5, 0, 1, 2, 3, 4, 6, 0x88, 0xb5})
+	fcs := crc32.ChecksumIEEE(data)
+	if bad {
+		fcs ^= 1
+	}
+	data = binary.LittleEndian.AppendUint32(data, fcs)
+
+	return &models.Frame{
+		RawData:        data,
+		Length:         len(data),
+		OriginalPacket: gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default),
+	}
+}
+
+func TestFCSAutoConfirm(t *testing.T) {
+	fp := NewFrameParser()
+
+	// Before the capture shows that frames carry an FCS, a mismatch means there is none
+	bad := ethernetFrameWithFCS(true)
+	fp.ParseFrame(bad)
+	if bad.FCS != nil {
+		t.Fatalf("unconfirmed auto mode reported FCS %+v", bad.FCS)
+	}
+
+	for i := 0; i < fcsAutoConfirm; i++ {
+		frame := ethernetFrameWithFCS(false)
+		fp.ParseFrame(frame)
+		if frame.FCS == nil || !frame.FCS.Valid {
+			t.Fatalf("valid FCS not detected: %+v", frame.FCS)
+		}
+	}
+
+	bad = ethernetFrameWithFCS(true)
+	fp.ParseFrame(bad)
+	if bad.FCS == nil || bad.FCS.Valid {
+		t.Fatalf("confirmed auto mode did not report a bad FCS: %+v", bad.FCS)
+	}
+
+	// Changing the mode starts over
+	fp.SetFCSMode(FCSAuto)
+	bad = ethernetFrameWithFCS(true)
+	fp.ParseFrame(bad)
+	if bad.FCS != nil {
+		t.Fatalf("auto mode kept its confirmation after SetFCSMode: %+v", bad.FCS)
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// icmpv6OptRDNSS is the Recursive DNS Server option (RFC 8106), not known to gopacket
+const icmpv6OptRDNSS = 25
+
+// parseRouterAdvertisement decodes a Router Advertisement and its options
+func parseRouterAdvertisement(l *layers.ICMPv6RouterAdvertisement) *models.NDInfo {
+	nd := &models.NDInfo{
+		CurHopLimit:      l.HopLimit,
+		Managed:          l.ManagedAddressConfig(),
+		OtherConfig:      l.OtherConfig(),
+		RouterPreference: models.NDRouterPreferenceName(l.Flags),
+		RouterLifetime:   l.RouterLifetime,
+		ReachableTime:    l.ReachableTime,
+		RetransTimer:     l.RetransTimer,
+	}
+	parseNDOptions(nd, l.Options)
+	return nd
+}
+
+// parseNeighborAdvertisement decodes a Neighbor Advertisement and its options
+func parseNeighborAdvertisement(l *layers.ICMPv6NeighborAdvertisement) *models.NDInfo {
+	nd := &models.NDInfo{
+		TargetAddress: l.TargetAddress.String(),
+		Router:        l.Router(),
+		Solicited:     l.Solicited(),
+		Override:      l.Override(),
+	}
+	parseNDOptions(nd, l.Options)
+	return nd
+}
+
+// parseNDOptions decodes the Neighbor Discovery options of a message
+func parseNDOptions(nd *models.NDInfo, options layers.ICMPv6Options) {
+	for _, option := range options {
+		data := option.Data
+		switch option.Type {
+		case layers.ICMPv6OptSourceAddress:
+			nd.SourceLinkAddress = linkAddress(data)
+		case layers.ICMPv6OptTargetAddress:
+			nd.TargetLinkAddress = linkAddress(data)
+		case layers.ICMPv6OptMTU:
+			if len(data) >= 6 {
+				nd.MTU = binary.BigEndian.Uint32(data[2:6])
+			}
+		case layers.ICMPv6OptPrefixInfo:
+			// Prefix length, flags, lifetimes, reserved and the 16 byte prefix
+			if len(data) < 30 {
+				continue
+			}
+			nd.Prefixes = append(nd.Prefixes, models.NDPrefix{
+				Prefix:            fmt.Sprintf("%s/%d", net.IP(data[14:30]), data[0]),
+				OnLink:            data[1]&0x80 != 0,
+				Autonomous:        data[1]&0x40 != 0,
+				ValidLifetime:     binary.BigEndian.Uint32(data[2:6]),
+				PreferredLifetime: binary.BigEndian.Uint32(data[6:10]),
+			})
+		case icmpv6OptRDNSS:
+			// Reserved, lifetime and the server addresses
+			if len(data) < 6 {
+				continue
+			}
+			nd.RDNSSLifetime = binary.BigEndian.Uint32(data[2:6])
+			for servers := data[6:]; len(servers) >= 16; servers = servers[16:] {
+				nd.RDNSS = append(nd.RDNSS, net.IP(servers[:16]).String())
+			}
+		}
+	}
+}
+
+// linkAddress formats the link-layer address of a Source or Target Link-Layer
+// Address option, a MAC address on Ethernet and 802.11
+func linkAddress(data []byte) string {
+	if len(data) < 6 {
+		return ""
+	}
+	return net.HardwareAddr(data[:6]).String()
+}
@@ -0,0 +1,334 @@
+package parser
+
+import (
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// NetworkParser parses the network and transport layers carried by a frame
+type NetworkParser struct{}
+
+// NewNetworkParser creates a new network parser
+func NewNetworkParser() *NetworkParser {
+	return &NetworkParser{}
+}
+
+// Parse decodes ARP, IPv4/IPv6, TCP/UDP/ICMP headers and the supported application
+// protocols starting at the frame's network offset
+func (np *NetworkParser) Parse(frame *models.Frame) {
+	if frame.NetworkOffset <= 0 || frame.NetworkOffset >= len(frame.RawData) {
+		return
+	}
+
+	firstLayer := networkLayerType(frame)
+	if firstLayer == gopacket.LayerTypeZero {
+		return
+	}
+
+	np.parseAt(frame, frame.NetworkOffset, firstLayer)
+}
+
+// parseAt decodes the layers found at offset within RawData, starting with firstLayer
+func (np *NetworkParser) parseAt(frame *models.Frame, offset int, firstLayer gopacket.LayerType) {
+	data := frame.RawData[offset:]
+	packet := gopacket.NewPacket(data, firstLayer, gopacket.DecodeOptions{NoCopy: true})
+
+	// Track where the transport payload begins
+	transportDecoded := false
+
+decode:
+	for _, layer := range packet.Layers() {
+		switch l := layer.(type) {
+		case *layers.ARP:
+			frame.ARP = parseARP(l)
+		case *layers.IPv4:
+			// A second IP header is an IP-in-IP tunnel, leave it to the inner frame
+			if frame.IPv4 != nil || frame.IPv6 != nil {
+				frame.Tunnel = &models.TunnelInfo{Type: "IP-in-IP", Protocol: 0x0800, Offset: offset}
+				break decode
+			}
+			frame.IPv4 = parseIPv4(l)
+		case *layers.IPv6:
+			if frame.IPv4 != nil || frame.IPv6 != nil {
+				frame.Tunnel = &models.TunnelInfo{Type: "IP-in-IP", Protocol: 0x86DD, Offset: offset}
+				break decode
+			}
+			frame.IPv6 = parseIPv6(l)
+		case *layers.GRE:
+			frame.Tunnel = parseGRE(frame, l, offset)
+			break decode
+		case *layers.VXLAN:
+			frame.Tunnel = &models.TunnelInfo{
+				Type:     "VXLAN",
+				Protocol: 0x6558,
+				Offset:   offset + len(l.Contents),
+				VNI:      l.VNI,
+			}
+			break decode
+		case *layers.Geneve:
+			frame.Tunnel = &models.TunnelInfo{
+				Type:       "Geneve",
+				Protocol:   uint16(l.Protocol),
+				Offset:     offset + len(l.Contents),
+				VNI:        l.VNI,
+				OptionsLen: int(l.OptionsLength),
+				OAM:        l.OAMPacket,
+			}
+			break decode
+		case *layers.IPv6HopByHop:
+			np.addExtensionHeader(frame, "Hop-by-Hop", uint8(l.NextHeader), len(l.Contents), nil)
+		case *layers.IPv6Routing:
+			np.addExtensionHeader(frame, "Routing", uint8(l.NextHeader), len(l.Contents), func(ext *models.IPv6ExtensionHeader) {
+				ext.RoutingType = l.RoutingType
+				ext.SegmentsLeft = l.SegmentsLeft
+			})
+		case *layers.IPv6Fragment:
+			np.addExtensionHeader(frame, "Fragment", uint8(l.NextHeader), len(l.Contents), func(ext *models.IPv6ExtensionHeader) {
+				ext.FragmentOffset = l.FragmentOffset
+				ext.MoreFragments = l.MoreFragments
+				ext.Identification = l.Identification
+			})
+		case *layers.IPv6Destination:
+			np.addExtensionHeader(frame, "Destination", uint8(l.NextHeader), len(l.Contents), nil)
+		case *layers.TCP:
+			frame.TCP = parseTCP(l)
+		case *layers.UDP:
+			frame.UDP = &models.UDPInfo{
+				SourcePort:      uint16(l.SrcPort),
+				DestinationPort: uint16(l.DstPort),
+				Length:          l.Length,
+				Checksum:        l.Checksum,
+				PayloadLength:   len(l.Payload),
+			}
+		case *layers.ICMPv4:
+			frame.ICMP = &models.ICMPInfo{
+				Version:  4,
+				Type:     l.TypeCode.Type(),
+				Code:     l.TypeCode.Code(),
+				TypeName: l.TypeCode.String(),
+				Checksum: l.Checksum,
+				ID:       l.Id,
+				Seq:      l.Seq,
+			}
+		case *layers.ICMPv6:
+			frame.ICMP = &models.ICMPInfo{
+				Version:  6,
+				Type:     l.TypeCode.Type(),
+				Code:     l.TypeCode.Code(),
+				TypeName: l.TypeCode.String(),
+				Checksum: l.Checksum,
+			}
+		case *layers.ICMPv6Echo:
+			if frame.ICMP != nil {
+				frame.ICMP.ID = l.Identifier
+				frame.ICMP.Seq = l.SeqNumber
+			}
+		case *layers.ICMPv6RouterSolicitation:
+			if frame.ICMP != nil {
+				frame.ICMP.ND = &models.NDInfo{}
+				parseNDOptions(frame.ICMP.ND, l.Options)
+			}
+		case *layers.ICMPv6RouterAdvertisement:
+			if frame.ICMP != nil {
+				frame.ICMP.ND = parseRouterAdvertisement(l)
+			}
+		case *layers.ICMPv6NeighborSolicitation:
+			if frame.ICMP != nil {
+				frame.ICMP.ND = &models.NDInfo{TargetAddress: l.TargetAddress.String()}
+				parseNDOptions(frame.ICMP.ND, l.Options)
+			}
+		case *layers.ICMPv6NeighborAdvertisement:
+			if frame.ICMP != nil {
+				frame.ICMP.ND = parseNeighborAdvertisement(l)
+			}
+		case *layers.ICMPv6Redirect:
+			if frame.ICMP != nil {
+				frame.ICMP.ND = &models.NDInfo{
+					TargetAddress:      l.TargetAddress.String(),
+					DestinationAddress: l.DestinationAddress.String(),
+				}
+				parseNDOptions(frame.ICMP.ND, l.Options)
+			}
+		case *layers.DHCPv4:
+			frame.DHCP = parseDHCPv4(l)
+		case *layers.DHCPv6:
+			frame.DHCP = parseDHCPv6(l)
+		case *layers.DNS:
+			// gopacket decodes DNS over TCP without its length prefix
+			if frame.UDP != nil {
+				frame.DNS = parseDNS(l, "DNS")
+			}
+		}
+
+		// Application layers are decoded in place and do not move the payload offset
+		if !transportDecoded {
+			offset += len(layer.LayerContents())
+			transportDecoded = frame.TCP != nil || frame.UDP != nil
+		}
+	}
+
+	if transportDecoded && offset <= len(frame.RawData) {
+		frame.ApplicationOffset = offset
+	}
+
+	// mDNS, LLMNR and DNS over TCP are not decoded by gopacket
+	if frame.DNS == nil && frame.ApplicationOffset > 0 {
+		parseDNSPayload(frame)
+	}
+
+	// TLS is recognized by its record header on any TCP port
+	if frame.TCP != nil && frame.ApplicationOffset > 0 {
+		parseTLSPayload(frame)
+	}
+}
+
+// networkLayerType returns the layer found at the frame's network offset
+func networkLayerType(frame *models.Frame) gopacket.LayerType {
+	switch frame.EtherType {
+	case 0x0806:
+		return layers.LayerTypeARP
+	case 0x0800:
+		return layers.LayerTypeIPv4
+	case 0x86DD:
+		return layers.LayerTypeIPv6
+	case 0x8847, 0x8848:
+		// MPLS does not announce its payload, guess it from the IP version nibble
+		if len(frame.MPLSLabels) > 0 && frame.MPLSLabels[len(frame.MPLSLabels)-1].BottomOfStack {
+			switch frame.RawData[frame.NetworkOffset] >> 4 {
+			case 4:
+				return layers.LayerTypeIPv4
+			case 6:
+				return layers.LayerTypeIPv6
+			}
+		}
+	case 0x8864:
+		if frame.PPPoE != nil {
+			switch frame.PPPoE.PPPProtocol {
+			case pppProtocolIPv4:
+				return layers.LayerTypeIPv4
+			case pppProtocolIPv6:
+				return layers.LayerTypeIPv6
+			}
+		}
+	}
+	return gopacket.LayerTypeZero
+}
+
+// addExtensionHeader records an IPv6 extension header on the frame
+func (np *NetworkParser) addExtensionHeader(frame *models.Frame, name string, nextHeader uint8, length int, fill func(*models.IPv6ExtensionHeader)) {
+	if frame.IPv6 == nil {
+		return
+	}
+
+	ext := models.IPv6ExtensionHeader{
+		Name:       name,
+		NextHeader: nextHeader,
+		Length:     length,
+	}
+	if fill != nil {
+		fill(&ext)
+	}
+
+	frame.IPv6.ExtensionHeaders = append(frame.IPv6.ExtensionHeaders, ext)
+	frame.IPv6.Protocol = nextHeader
+}
+
+// parseARP converts a decoded ARP layer into the frame model
+func parseARP(arp *layers.ARP) *models.ARPInfo {
+	info := &models.ARPInfo{
+		HardwareType: uint16(arp.AddrType),
+		ProtocolType: uint16(arp.Protocol),
+		Operation:    arp.Operation,
+		SenderMAC:    net.HardwareAddr(arp.SourceHwAddress).String(),
+		TargetMAC:    net.HardwareAddr(arp.DstHwAddress).String(),
+	}
+
+	// Only IPv4 over ARP carries 4-byte protocol addresses
+	if len(arp.SourceProtAddress) == net.IPv4len && len(arp.DstProtAddress) == net.IPv4len {
+		info.SenderIP = net.IP(arp.SourceProtAddress).String()
+		info.TargetIP = net.IP(arp.DstProtAddress).String()
+		info.Gratuitous = info.SenderIP == info.TargetIP
+	}
+
+	return info
+}
+
+// parseIPv4 converts a decoded IPv4 layer into the frame model
+func parseIPv4(ip *layers.IPv4) *models.IPv4Info {
+	info := &models.IPv4Info{
+		IHL:            ip.IHL,
+		DSCP:           ip.TOS >> 2,
+		ECN:            ip.TOS & 0x3,
+		TotalLength:    ip.Length,
+		ID:             ip.Id,
+		DontFragment:   ip.Flags&layers.IPv4DontFragment != 0,
+		MoreFragments:  ip.Flags&layers.IPv4MoreFragments != 0,
+		FragmentOffset: ip.FragOffset,
+		TTL:            ip.TTL,
+		Protocol:       uint8(ip.Protocol),
+		Checksum:       ip.Checksum,
+		SourceIP:       ip.SrcIP.String(),
+		DestinationIP:  ip.DstIP.String(),
+	}
+
+	for _, option := range ip.Options {
+		info.Options = append(info.Options, models.IPOption{
+			Type:   option.OptionType,
+			Length: option.OptionLength,
+			Data:   option.OptionData,
+		})
+	}
+
+	return info
+}
+
+// parseIPv6 converts a decoded IPv6 fixed header into the frame model
+func parseIPv6(ip *layers.IPv6) *models.IPv6Info {
+	return &models.IPv6Info{
+		TrafficClass:  ip.TrafficClass,
+		DSCP:          ip.TrafficClass >> 2,
+		ECN:           ip.TrafficClass & 0x3,
+		FlowLabel:     ip.FlowLabel,
+		PayloadLength: ip.Length,
+		NextHeader:    uint8(ip.NextHeader),
+		Protocol:      uint8(ip.NextHeader),
+		HopLimit:      ip.HopLimit,
+		SourceIP:      ip.SrcIP.String(),
+		DestinationIP: ip.DstIP.String(),
+	}
+}
+
+// parseTCP converts a decoded TCP layer into the frame model
+func parseTCP(tcp *layers.TCP) *models.TCPInfo {
+	info := &models.TCPInfo{
+		SourcePort:      uint16(tcp.SrcPort),
+		DestinationPort: uint16(tcp.DstPort),
+		Seq:             tcp.Seq,
+		Ack:             tcp.Ack,
+		DataOffset:      tcp.DataOffset,
+		Flags: models.TCPFlags{
+			FIN: tcp.FIN, SYN: tcp.SYN, RST: tcp.RST, PSH: tcp.PSH, ACK: tcp.ACK,
+			URG: tcp.URG, ECE: tcp.ECE, CWR: tcp.CWR, NS: tcp.NS,
+		},
+		Window:        tcp.Window,
+		Checksum:      tcp.Checksum,
+		Urgent:        tcp.Urgent,
+		PayloadLength: len(tcp.Payload),
+	}
+
+	for _, option := range tcp.Options {
+		if option.OptionType == layers.TCPOptionKindNop || option.OptionType == layers.TCPOptionKindEndList {
+			continue
+		}
+		info.Options = append(info.Options, models.TCPOption{
+			Kind: uint8(option.OptionType),
+			Name: option.OptionType.String(),
+			Data: option.OptionData,
+		})
+	}
+
+	return info
+}
@@ -12,6 +12,9 @@ import (
 type FrameParser struct {
 	ethernetParser *EthernetParser
 	wlanParser     *WLANParser
+	networkParser  *NetworkParser
+	fcsMode        FCSMode
+	fcsMatches     int // Frames whose trailing bytes matched their CRC32 in auto mode
 }
 
 // NewFrameParser creates a new frame parser
@@ -19,11 +22,29 @@ func NewFrameParser() *FrameParser {
 	return &FrameParser{
 		ethernetParser: NewEthernetParser(),
 		wlanParser:     NewWLANParser(),
+		networkParser:  NewNetworkParser(),
 	}
 }
 
+// SetFCSMode sets whether captured Ethernet frames end with their FCS
+func (fp *FrameParser) SetFCSMode(mode FCSMode) {
+	fp.fcsMode = mode
+	fp.fcsMatches = 0
+}
+
 // ParseFrame identifies the frame type and parses it accordingly
 func (fp *FrameParser) ParseFrame(frame *models.Frame) {
+	fp.parseLinkLayer(frame)
+
+	// Decode the network and transport layers located by the link layer parser
+	fp.networkParser.Parse(frame)
+
+	// Parse the packet carried by a tunnel as an inner frame
+	fp.decapsulate(frame, 0)
+}
+
+// parseLinkLayer identifies the link layer of the frame and parses it
+func (fp *FrameParser) parseLinkLayer(frame *models.Frame) {
 	// Try to determine the frame type based on the packet
 	packet := frame.OriginalPacket
 
@@ -31,15 +52,23 @@ func (fp *FrameParser) ParseFrame(frame *models.Frame) {
 	if ethernetLayer := packet.Layer(layers.LayerTypeEthernet); ethernetLayer != nil {
 		frame.FrameType = models.EthernetFrame
 		fp.ethernetParser.Parse(frame)
+		fp.checkEthernetFCS(frame)
 		return
 	}
 
+	// Monitor mode interfaces usually prepend a radiotap header to the 802.11 frame
+	if radioTapLayer := packet.Layer(layers.LayerTypeRadioTap); radioTapLayer != nil {
+		radioTap, _ := radioTapLayer.(*layers.RadioTap)
+		frame.RadioTap = parseRadioTap(radioTap)
+		checkWLANFCS(frame)
+	}
+
 	// Check if it's a wireless frame (802.11)
 	// This requires the interface to be in monitor mode to capture 802.11 headers
 	// Try to parse the raw data as a WLAN frame
-	if len(frame.RawData) >= 2 {
+	if data := wlanData(frame); len(data) >= 2 {
 		// Check frame control field to identify if it's a WLAN frame
-		frameControl := binary.LittleEndian.Uint16(frame.RawData[0:2])
+		frameControl := binary.LittleEndian.Uint16(data[0:2])
 		frameType := (frameControl >> 2) & 0x3
 
 		switch frameType {
@@ -82,15 +111,46 @@ func (ep *EthernetParser) Parse(frame *models.Frame) {
 		frame.SourceMAC = ethernet.SrcMAC.String()
 		frame.DestinationMAC = ethernet.DstMAC.String()
 		frame.EtherType = uint16(ethernet.EthernetType)
-
-		// Check for VLAN tagging
-		if vlanLayer := packet.Layer(layers.LayerTypeDot1Q); vlanLayer != nil {
-			vlan, _ := vlanLayer.(*layers.Dot1Q)
-			frame.VLANInfo = map[string]interface{}{
-				"Priority": vlan.Priority,
-				"VID":      vlan.VLANIdentifier,
+		frame.OuterEtherType = frame.EtherType
+
+		// The network layer starts right after the Ethernet header and its VLAN tags,
+		// and the innermost tag announces the EtherType of the payload
+		offset := len(ethernet.Contents)
+		tpid := frame.EtherType
+		for _, layer := range packet.Layers() {
+			if vlan, ok := layer.(*layers.Dot1Q); ok {
+				frame.VLANTags = append(frame.VLANTags, models.VLANTag{
+					TPID:     tpid,
+					Priority: vlan.Priority,
+					DEI:      vlan.DropEligible,
+					VID:      vlan.VLANIdentifier,
+				})
+				offset += len(vlan.Contents)
+				frame.EtherType = uint16(vlan.Type)
+				tpid = frame.EtherType
 			}
 		}
+
+		// MPLS and PPPoE sit between the Ethernet header and the network layer
+		switch frame.EtherType {
+		case 0x8847, 0x8848:
+			offset = parseMPLS(frame, offset)
+		case 0x8863, 0x8864:
+			offset = parsePPPoE(frame, offset)
+		}
+
+		if offset < len(frame.RawData) && frame.EtherType != 0x8863 {
+			frame.NetworkOffset = offset
+		}
+
+		// Decode the link layer protocols carried directly over Ethernet
+		switch {
+		case frame.EtherType == 0x88CC:
+			frame.Discovery = parseLLDP(packet)
+		case frame.EtherType <= 1500: // 802.3 length field, an LLC header follows
+			frame.Discovery = parseCDP(packet)
+			frame.STP = parseBPDU(frame)
+		}
 	}
 
 	frame.Parsed = true
@@ -109,13 +169,15 @@ func (wp *WLANParser) Parse(frame *models.Frame) {
 	// We need to manually parse the raw data for 802.11 frames
 	// since gopacket may not have full support for all 802.11 frame types
 
+	data := wlanData(frame)
+
 	// Parse frame control field
-	if len(frame.RawData) < 24 { // Minimum size for a valid 802.11 frame
+	if len(data) < 10 { // Frame control, duration and receiver address
 		return
 	}
 
-	frameControl := binary.LittleEndian.Uint16(frame.RawData[0:2])
-	duration := binary.LittleEndian.Uint16(frame.RawData[2:4])
+	frameControl := binary.LittleEndian.Uint16(data[0:2])
+	duration := binary.LittleEndian.Uint16(data[2:4])
 
 	// Extract frame type and subtype
 	frameType := (frameControl >> 2) & 0x3
@@ -151,21 +213,37 @@ func (wp *WLANParser) Parse(frame *models.Frame) {
 	// Address fields depend on the ToDS and FromDS flags
 
 	// Address 1 is always present (DA/RA)
-	frame.Address1 = net.HardwareAddr(frame.RawData[4:10]).String()
+	frame.Address1 = net.HardwareAddr(data[4:10]).String()
+
+	// Control frames carry at most a receiver and a transmitter address
+	// (ACK and CTS only have the receiver address)
+	if frame.FrameType == models.WLANControlFrame {
+		frame.DestinationMAC = frame.Address1
+		if len(data) >= 16 && frameSubtype != 12 && frameSubtype != 13 {
+			frame.Address2 = net.HardwareAddr(data[10:16]).String()
+			frame.SourceMAC = frame.Address2
+		}
+		frame.Parsed = true
+		return
+	}
+
+	if len(data) < 24 { // Minimum size for a valid 802.11 management or data frame
+		return
+	}
 
 	// Address 2 is always present (SA/TA)
-	frame.Address2 = net.HardwareAddr(frame.RawData[10:16]).String()
+	frame.Address2 = net.HardwareAddr(data[10:16]).String()
 
 	// Address 3 is always present (varies based on ToDS/FromDS)
-	frame.Address3 = net.HardwareAddr(frame.RawData[16:22]).String()
+	frame.Address3 = net.HardwareAddr(data[16:22]).String()
 
 	// Get sequence control field
-	frame.SequenceControl = binary.LittleEndian.Uint16(frame.RawData[22:24])
+	frame.SequenceControl = binary.LittleEndian.Uint16(data[22:24])
 
 	// Address 4 is only present if both ToDS and FromDS are set
 	offset := 24
-	if toDS == 1 && fromDS == 1 && len(frame.RawData) >= 30 {
-		frame.Address4 = net.HardwareAddr(frame.RawData[24:30]).String()
+	if toDS == 1 && fromDS == 1 && len(data) >= 30 {
+		frame.Address4 = net.HardwareAddr(data[24:30]).String()
 		offset = 30
 	}
 
@@ -196,8 +274,8 @@ func (wp *WLANParser) Parse(frame *models.Frame) {
 
 	// Parse QoS info for QoS data frames
 	if frame.FrameType == models.WLANDataFrame && (frameSubtype == 8 || frameSubtype == 9 || frameSubtype == 10 || frameSubtype == 11) {
-		if offset+2 <= len(frame.RawData) {
-			qosControl := binary.LittleEndian.Uint16(frame.RawData[offset : offset+2])
+		if offset+2 <= len(data) {
+			qosControl := binary.LittleEndian.Uint16(data[offset : offset+2])
 			tid := qosControl & 0xF
 			eosp := (qosControl >> 4) & 0x1
 			ackPolicy := (qosControl >> 5) & 0x3
@@ -216,9 +294,19 @@ func (wp *WLANParser) Parse(frame *models.Frame) {
 			}
 
 			offset += 2
+
+			// QoS frames with the Order bit set carry an HT Control field
+			if order == 1 {
+				offset += 4
+			}
 		}
 	}
 
+	// Unprotected data frames carry an LLC/SNAP header announcing the EtherType
+	if frame.FrameType == models.WLANDataFrame && protected == 0 && (frameSubtype&0x4) == 0 {
+		wp.parseLLCSNAP(frame, data, offset)
+	}
+
 	// Check for security info based on Protected flag
 	if protected == 1 {
 		frame.Security = &models.SecurityInfo{
@@ -228,24 +316,24 @@ func (wp *WLANParser) Parse(frame *models.Frame) {
 
 		// Try to identify encryption type
 		// This is a simplified approach and may need to be refined
-		if offset+4 <= len(frame.RawData) {
+		if offset+4 <= len(data) {
 			// Check for WEP
-			if len(frame.RawData) >= offset+4 && len(frame.RawData) <= offset+12 {
+			if len(data) >= offset+4 && len(data) <= offset+12 {
 				frame.Security.EncryptionType = "WEP"
-				frame.Security.Details["IV"] = frame.RawData[offset : offset+3]
-				frame.Security.Details["KeyID"] = frame.RawData[offset+3] >> 6
-			} else if len(frame.RawData) >= offset+8 {
+				frame.Security.Details["IV"] = data[offset : offset+3]
+				frame.Security.Details["KeyID"] = data[offset+3] >> 6
+			} else if len(data) >= offset+8 {
 				// Check TKIP/CCMP/GCMP
-				if offset+12 <= len(frame.RawData) {
+				if offset+12 <= len(data) {
 					// Check for CCMP
-					if (frame.RawData[offset+3] & 0x20) == 0 {
+					if (data[offset+3] & 0x20) == 0 {
 						frame.Security.EncryptionType = "CCMP (WPA2)"
-						frame.Security.Details["PN"] = frame.RawData[offset : offset+6]
+						frame.Security.Details["PN"] = data[offset : offset+6]
 					} else {
 						// TKIP
 						frame.Security.EncryptionType = "TKIP (WPA)"
-						frame.Security.Details["IV"] = frame.RawData[offset : offset+4]
-						frame.Security.Details["ExtIV"] = frame.RawData[offset+4 : offset+8]
+						frame.Security.Details["IV"] = data[offset : offset+4]
+						frame.Security.Details["ExtIV"] = data[offset+4 : offset+8]
 					}
 				}
 			}
@@ -254,14 +342,37 @@ func (wp *WLANParser) Parse(frame *models.Frame) {
 
 	// Handle management frames special parsing
 	if frame.FrameType == models.WLANManagementFrame {
-		wp.parseManagementFrame(frame, frameSubtype, offset)
+		wp.parseManagementFrame(frame, data, frameSubtype, offset)
 	}
 
 	frame.Parsed = true
 }
 
+// parseLLCSNAP parses the LLC/SNAP header that precedes the payload of 802.11 data frames
+func (wp *WLANParser) parseLLCSNAP(frame *models.Frame, data []byte, offset int) {
+	if offset+8 > len(data) {
+		return
+	}
+
+	llc := data[offset : offset+8]
+	if llc[0] != 0xAA || llc[1] != 0xAA || llc[2] != 0x03 {
+		return
+	}
+
+	frame.EtherType = binary.BigEndian.Uint16(llc[6:8])
+
+	// Offsets in the frame are relative to RawData, which includes the radiotap header
+	networkOffset := offset + 8
+	if frame.RadioTap != nil {
+		networkOffset += frame.RadioTap.Length
+	}
+	if networkOffset < len(frame.RawData) {
+		frame.NetworkOffset = networkOffset
+	}
+}
+
 // parseManagementFrame parses management frame details
-func (wp *WLANParser) parseManagementFrame(frame *models.Frame, subtype uint16, offset int) {
+func (wp *WLANParser) parseManagementFrame(frame *models.Frame, data []byte, subtype uint16, offset int) {
 	// Add management frame specific details
 	managementInfo := make(map[string]interface{})
 
@@ -278,43 +389,10 @@ func (wp *WLANParser) parseManagementFrame(frame *models.Frame, subtype uint16,
 		managementInfo["Type"] = "Probe Request"
 	case 5: // Probe Response
 		managementInfo["Type"] = "Probe Response"
+		wp.parseBeaconBody(data, offset, managementInfo)
 	case 8: // Beacon
 		managementInfo["Type"] = "Beacon"
-		// Parse beacon specific fields
-		if offset+12 <= len(frame.RawData) {
-			// Parse timestamp
-			timestamp := binary.LittleEndian.Uint64(frame.RawData[offset : offset+8])
-			offset += 8
-
-			// Parse beacon interval
-			beaconInterval := binary.LittleEndian.Uint16(frame.RawData[offset : offset+2])
-			offset += 2
-
-			// Parse capability info
-			capabilityInfo := binary.LittleEndian.Uint16(frame.RawData[offset : offset+2])
-			offset += 2
-
-			managementInfo["Timestamp"] = timestamp
-			managementInfo["BeaconInterval"] = beaconInterval
-			managementInfo["CapabilityInfo"] = map[string]bool{
-				"ESS":               (capabilityInfo & 0x0001) != 0,
-				"IBSS":              (capabilityInfo & 0x0002) != 0,
-				"CF-Pollable":       (capabilityInfo & 0x0004) != 0,
-				"CF-Poll-Request":   (capabilityInfo & 0x0008) != 0,
-				"Privacy":           (capabilityInfo & 0x0010) != 0,
-				"ShortPreamble":     (capabilityInfo & 0x0020) != 0,
-				"PBCC":              (capabilityInfo & 0x0040) != 0,
-				"ChannelAgility":    (capabilityInfo & 0x0080) != 0,
-				"SpectrumMgmt":      (capabilityInfo & 0x0100) != 0,
-				"QoS":               (capabilityInfo & 0x0200) != 0,
-				"ShortSlotTime":     (capabilityInfo & 0x0400) != 0,
-				"APSD":              (capabilityInfo & 0x0800) != 0,
-				"RadioMeasurement":  (capabilityInfo & 0x1000) != 0,
-				"DSSS-OFDM":         (capabilityInfo & 0x2000) != 0,
-				"DelayedBlockAck":   (capabilityInfo & 0x4000) != 0,
-				"ImmediateBlockAck": (capabilityInfo & 0x8000) != 0,
-			}
-		}
+		wp.parseBeaconBody(data, offset, managementInfo)
 	case 9: // ATIM
 		managementInfo["Type"] = "ATIM"
 	case 10: // Disassociation
@@ -335,6 +413,111 @@ func (wp *WLANParser) parseManagementFrame(frame *models.Frame, subtype uint16,
 	frame.AnalysisResults["ManagementInfo"] = managementInfo
 }
 
+// parseBeaconBody parses the fixed fields and information elements shared
+// by beacons and probe responses
+func (wp *WLANParser) parseBeaconBody(data []byte, offset int, managementInfo map[string]interface{}) {
+	// Parse beacon specific fields
+	if offset+12 <= len(data) {
+		// Parse timestamp
+		timestamp := binary.LittleEndian.Uint64(data[offset : offset+8])
+		offset += 8
+
+		// Parse beacon interval
+		beaconInterval := binary.LittleEndian.Uint16(data[offset : offset+2])
+		offset += 2
+
+		// Parse capability info
+		capabilityInfo := binary.LittleEndian.Uint16(data[offset : offset+2])
+		offset += 2
+
+		managementInfo["Timestamp"] = timestamp
+		managementInfo["BeaconInterval"] = beaconInterval
+		managementInfo["CapabilityInfo"] = map[string]bool{
+			"ESS":               (capabilityInfo & 0x0001) != 0,
+			"IBSS":              (capabilityInfo & 0x0002) != 0,
+			"CF-Pollable":       (capabilityInfo & 0x0004) != 0,
+			"CF-Poll-Request":   (capabilityInfo & 0x0008) != 0,
+			"Privacy":           (capabilityInfo & 0x0010) != 0,
+			"ShortPreamble":     (capabilityInfo & 0x0020) != 0,
+			"PBCC":              (capabilityInfo & 0x0040) != 0,
+			"ChannelAgility":    (capabilityInfo & 0x0080) != 0,
+			"SpectrumMgmt":      (capabilityInfo & 0x0100) != 0,
+			"QoS":               (capabilityInfo & 0x0200) != 0,
+			"ShortSlotTime":     (capabilityInfo & 0x0400) != 0,
+			"APSD":              (capabilityInfo & 0x0800) != 0,
+			"RadioMeasurement":  (capabilityInfo & 0x1000) != 0,
+			"DSSS-OFDM":         (capabilityInfo & 0x2000) != 0,
+			"DelayedBlockAck":   (capabilityInfo & 0x4000) != 0,
+			"ImmediateBlockAck": (capabilityInfo & 0x8000) != 0,
+		}
+
+		// Tagged parameters follow the fixed fields
+		parseInformationElements(data[offset:], managementInfo)
+	}
+}
+
+// parseInformationElements parses the tagged parameters of a management frame
+func parseInformationElements(elements []byte, managementInfo map[string]interface{}) {
+	for len(elements) >= 2 {
+		id := elements[0]
+		length := int(elements[1])
+		if 2+length > len(elements) {
+			break
+		}
+		body := elements[2 : 2+length]
+
+		switch id {
+		case 0: // SSID
+			managementInfo["SSID"] = string(body)
+		case 3: // DS Parameter Set
+			if len(body) >= 1 {
+				managementInfo["Channel"] = int(body[0])
+			}
+		case 221: // Vendor Specific
+			if wmm := parseWMMParameterElement(body); wmm != nil {
+				managementInfo["WMMParameters"] = wmm
+			}
+		}
+
+		elements = elements[2+length:]
+	}
+}
+
+// parseWMMParameterElement parses a WMM parameter element (OUI 00:50:F2, type 2, subtype 1)
+func parseWMMParameterElement(body []byte) *models.WMMParameterSet {
+	if len(body) < 24 || body[0] != 0x00 || body[1] != 0x50 || body[2] != 0xF2 || body[3] != 2 || body[4] != 1 {
+		return nil
+	}
+
+	qosInfo := body[6]
+	wmm := &models.WMMParameterSet{
+		QoSInfo: qosInfo,
+		UAPSD:   (qosInfo & 0x80) != 0,
+	}
+
+	// Four AC parameter records follow the QoS info and reserved bytes
+	accessCategories := []string{
+		models.AccessCategoryBestEffort,
+		models.AccessCategoryBackground,
+		models.AccessCategoryVideo,
+		models.AccessCategoryVoice,
+	}
+	for i := 0; i < 4; i++ {
+		record := body[8+i*4 : 12+i*4]
+		aci := (record[0] >> 5) & 0x3
+		wmm.ACParameters = append(wmm.ACParameters, models.WMMACParameters{
+			AccessCategory: accessCategories[aci],
+			AIFSN:          int(record[0] & 0xF),
+			ACM:            (record[0] & 0x10) != 0,
+			ECWMin:         int(record[1] & 0xF),
+			ECWMax:         int(record[1] >> 4),
+			TXOPLimit:      int(binary.LittleEndian.Uint16(record[2:4])),
+		})
+	}
+
+	return wmm
+}
+
 // getACKPolicyString returns a string representation of the ACK policy
 func getACKPolicyString(policy uint16) string {
 	switch policy {
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// htBaseRates holds the 20 MHz long-GI data rates in Mbps for HT MCS 0-7 (one spatial stream)
+var htBaseRates = [8]float64{6.5, 13, 19.5, 26, 39, 52, 58.5, 65}
+
+// parseRadioTap extracts the radio metadata from a radiotap layer
+func parseRadioTap(radioTap *layers.RadioTap) *models.RadioTapInfo {
+	info := &models.RadioTapInfo{
+		Length:        int(radioTap.Length),
+		Flags:         uint8(radioTap.Flags),
+		MCS:           -1,
+		ChannelFreq:   uint16(radioTap.ChannelFrequency),
+		Signal:        radioTap.DBMAntennaSignal,
+		ShortPreamble: radioTap.Flags.ShortPreamble(),
+		FCSIncluded:   radioTap.Flags.FCS(),
+		BadFCS:        radioTap.Flags&layers.RadioTapFlagsBadFCS != 0,
+	}
+
+	if radioTap.Present.Rate() {
+		info.Rate = 0.5 * float64(radioTap.Rate)
+	}
+
+	if radioTap.Present.MCS() && radioTap.MCS.Known.MCSIndex() {
+		info.MCS = int(radioTap.MCS.MCS)
+		if info.Rate == 0 {
+			info.Rate = htRate(radioTap.MCS)
+		}
+	}
+
+	return info
+}
+
+// htRate returns the data rate in Mbps of an HT transmission
+func htRate(mcs layers.RadioTapMCS) float64 {
+	index := int(mcs.MCS)
+	if index >= 32 {
+		return 0
+	}
+
+	streams := float64(index/8 + 1)
+	rate := htBaseRates[index%8] * streams
+
+	// 40 MHz channels carry 108 data subcarriers instead of 52
+	if mcs.Known.Bandwidth() && mcs.Flags.Bandwidth() == 1 {
+		rate = rate * 108 / 52
+	}
+
+	// Short guard interval shortens the symbol from 4.0µs to 3.6µs
+	if mcs.Known.GuardInterval() && mcs.Flags.ShortGI() {
+		rate = rate * 10 / 9
+	}
+
+	return rate
+}
+
+// wlanData returns the 802.11 portion of a frame, skipping the radiotap
+// header and trailing FCS when present
+func wlanData(frame *models.Frame) []byte {
+	data := frame.RawData
+	if frame.RadioTap == nil {
+		return data
+	}
+
+	if frame.RadioTap.Length > len(data) {
+		return nil
+	}
+	data = data[frame.RadioTap.Length:]
+
+	if frame.RadioTap.FCSIncluded && len(data) >= 4 {
+		data = data[:len(data)-4]
+	}
+
+	return data
+}
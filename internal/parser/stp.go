@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// Lengths of the fixed parts of a BPDU
+const (
+	bpduTCNLength    = 4
+	bpduConfigLength = 35
+	bpduRSTLength    = 36
+	bpduMSTLength    = 102 // Up to and including the CIST remaining hops
+	bpduMSTILength   = 16
+)
+
+// pvstSNAPHeader is the LLC/SNAP header of Cisco PVST+ BPDUs
+var pvstSNAPHeader = []byte{0xAA, 0xAA, 0x03, 0x00, 0x00, 0x0C, 0x01, 0x0B}
+
+// parseBPDU decodes a spanning tree BPDU carried in an IEEE 802.3 LLC frame
+func parseBPDU(frame *models.Frame) *models.STPInfo {
+	if frame.NetworkOffset <= 0 || frame.NetworkOffset >= len(frame.RawData) {
+		return nil
+	}
+	data := frame.RawData[frame.NetworkOffset:]
+
+	switch {
+	case len(data) > 3 && data[0] == 0x42 && data[1] == 0x42:
+		// IEEE 802.1D/Q spanning tree LLC SAP
+		return decodeBPDU(data[3:])
+	case bytes.HasPrefix(data, pvstSNAPHeader):
+		return decodeBPDU(data[len(pvstSNAPHeader):])
+	default:
+		return nil
+	}
+}
+
+// decodeBPDU decodes the BPDU following the LLC header
+func decodeBPDU(data []byte) *models.STPInfo {
+	if len(data) < bpduTCNLength || binary.BigEndian.Uint16(data[0:2]) != 0 {
+		return nil
+	}
+
+	info := &models.STPInfo{
+		Version: data[2],
+	}
+
+	switch info.Version {
+	case 0:
+		info.VersionName = "STP"
+	case 2:
+		info.VersionName = "RSTP"
+	default:
+		info.VersionName = "MSTP"
+	}
+
+	switch data[3] {
+	case 0x80:
+		info.BPDUType = "TCN"
+		return info
+	case 0x00:
+		info.BPDUType = "Configuration"
+	case 0x02:
+		info.BPDUType = "RST"
+	default:
+		return nil
+	}
+
+	if len(data) < bpduConfigLength {
+		return nil
+	}
+
+	flags := data[4]
+	info.TopologyChange = flags&0x01 != 0
+	info.TopologyChangeAck = flags&0x80 != 0
+	if info.BPDUType == "RST" {
+		info.Proposal = flags&0x02 != 0
+		info.PortRole = bpduPortRole(flags)
+		info.Learning = flags&0x10 != 0
+		info.Forwarding = flags&0x20 != 0
+		info.Agreement = flags&0x40 != 0
+	}
+
+	info.RootID = parseBridgeID(data[5:13])
+	info.RootPathCost = binary.BigEndian.Uint32(data[13:17])
+	info.BridgeID = parseBridgeID(data[17:25])
+	info.PortID = binary.BigEndian.Uint16(data[25:27])
+	info.MessageAge = bpduTime(data[27:29])
+	info.MaxAge = bpduTime(data[29:31])
+	info.HelloTime = bpduTime(data[31:33])
+	info.ForwardDelay = bpduTime(data[33:35])
+
+	end := bpduConfigLength
+	if info.BPDUType == "RST" {
+		end = bpduRSTLength
+	}
+	if info.Version >= 3 && len(data) >= bpduMSTLength {
+		info.MST, end = parseMST(data)
+	}
+
+	// PVST+ appends a TLV carrying the originating VLAN
+	if len(data) >= end+6 && binary.BigEndian.Uint16(data[end:end+2]) == 0 && binary.BigEndian.Uint16(data[end+2:end+4]) == 2 {
+		info.PVSTVLAN = binary.BigEndian.Uint16(data[end+4 : end+6])
+	}
+
+	return info
+}
+
+// parseMST decodes the MST extension of a version 3 BPDU and returns the end offset
+func parseMST(data []byte) (*models.MSTInfo, int) {
+	// Version 3 length counts the bytes following the field itself
+	v3Length := int(binary.BigEndian.Uint16(data[36:38]))
+	end := 38 + v3Length
+	if end > len(data) {
+		end = len(data)
+	}
+
+	mst := &models.MSTInfo{
+		ConfigName:               strings.TrimRight(string(data[39:71]), "\x00"),
+		Revision:                 binary.BigEndian.Uint16(data[71:73]),
+		Digest:                   hex.EncodeToString(data[73:89]),
+		CISTInternalRootPathCost: binary.BigEndian.Uint32(data[89:93]),
+		CISTBridgeID:             parseBridgeID(data[93:101]),
+		RemainingHops:            data[101],
+	}
+
+	for offset := bpduMSTLength; offset+bpduMSTILength <= end; offset += bpduMSTILength {
+		msti := data[offset : offset+bpduMSTILength]
+		flags := msti[0]
+		regionalRoot := parseBridgeID(msti[1:9])
+		mst.Instances = append(mst.Instances, models.MSTInstance{
+			ID:                   regionalRoot.SystemIDExtension,
+			RegionalRootID:       regionalRoot,
+			InternalRootPathCost: binary.BigEndian.Uint32(msti[9:13]),
+			BridgePriority:       msti[13],
+			PortPriority:         msti[14],
+			RemainingHops:        msti[15],
+			PortRole:             bpduPortRole(flags),
+			TopologyChange:       flags&0x01 != 0,
+			Learning:             flags&0x10 != 0,
+			Forwarding:           flags&0x20 != 0,
+		})
+	}
+
+	return mst, end
+}
+
+// parseBridgeID decodes an 8-byte bridge identifier
+func parseBridgeID(data []byte) models.BridgeID {
+	priority := binary.BigEndian.Uint16(data[0:2])
+	return models.BridgeID{
+		Priority:          priority & 0xF000,
+		SystemIDExtension: priority & 0x0FFF,
+		MAC:               net.HardwareAddr(data[2:8]).String(),
+	}
+}
+
+// bpduTime converts a BPDU timer in 1/256 seconds to seconds
+func bpduTime(data []byte) float64 {
+	return float64(binary.BigEndian.Uint16(data)) / 256
+}
+
+// bpduPortRole decodes the port role bits of RST and MSTI flags
+func bpduPortRole(flags byte) string {
+	switch (flags >> 2) & 0x3 {
+	case 1:
+		return "Alternate/Backup"
+	case 2:
+		return "Root"
+	case 3:
+		return "Designated"
+	default:
+		return "Unknown"
+	}
+}
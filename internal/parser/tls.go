@@ -0,0 +1,293 @@
+package parser
+
+import (
+	"crypto/x509"
+	"encoding/binary"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+const (
+	// tlsRecordHeaderLength is the length of the TLS record header
+	tlsRecordHeaderLength = 5
+	// maxTLSRecordLength is the largest record payload allowed (2^14 plus expansion)
+	maxTLSRecordLength = 16384 + 2048
+)
+
+// TLS extension types decoded from hello messages
+const (
+	tlsExtServerName          = 0
+	tlsExtSupportedGroups     = 10
+	tlsExtECPointFormats      = 11
+	tlsExtSignatureAlgorithms = 13
+	tlsExtALPN                = 16
+	tlsExtSupportedVersions   = 43
+)
+
+// parseTLSPayload decodes the TLS records that start in the frame's TCP payload.
+// Records continuing from an earlier segment cannot be recognized and are left
+// to the stream analysis.
+func parseTLSPayload(frame *models.Frame) {
+	if frame.TCP == nil {
+		return
+	}
+
+	payload := frame.ApplicationPayload()
+	info := &models.TLSInfo{}
+	encrypted := false
+	for len(payload) >= tlsRecordHeaderLength {
+		record, ok := readTLSRecordHeader(payload)
+		if !ok {
+			break
+		}
+		info.Records = append(info.Records, record)
+
+		end := tlsRecordHeaderLength + record.Length
+		if end > len(payload) {
+			break
+		}
+
+		// Only records holding whole messages are decoded here, which also
+		// rules out most encrypted handshake records
+		switch record.ContentType {
+		case models.TLSChangeCipherSpec:
+			encrypted = true
+		case models.TLSHandshake:
+			if !encrypted {
+				body := payload[tlsRecordHeaderLength:end]
+				if handshakes, n := ParseTLSHandshakes(body); n == len(body) {
+					info.Handshakes = append(info.Handshakes, handshakes...)
+				}
+			}
+		}
+		payload = payload[end:]
+	}
+
+	if len(info.Records) > 0 {
+		frame.TLS = info
+	}
+}
+
+// SplitTLSRecords returns the complete TLS records at the start of data, their
+// payloads and the number of bytes they take. ok is false when data does not
+// start with a valid TLS record header.
+func SplitTLSRecords(data []byte) (records []models.TLSRecord, payloads [][]byte, consumed int, ok bool) {
+	for len(data)-consumed >= tlsRecordHeaderLength {
+		record, valid := readTLSRecordHeader(data[consumed:])
+		if !valid {
+			return records, payloads, consumed, false
+		}
+
+		end := consumed + tlsRecordHeaderLength + record.Length
+		if end > len(data) {
+			break
+		}
+		records = append(records, record)
+		payloads = append(payloads, data[consumed+tlsRecordHeaderLength:end])
+		consumed = end
+	}
+
+	return records, payloads, consumed, true
+}
+
+// readTLSRecordHeader decodes a TLS record header, rejecting data that is not TLS
+func readTLSRecordHeader(data []byte) (models.TLSRecord, bool) {
+	record := models.TLSRecord{
+		ContentType: data[0],
+		Version:     binary.BigEndian.Uint16(data[1:3]),
+		Length:      int(binary.BigEndian.Uint16(data[3:5])),
+	}
+
+	if record.ContentType < models.TLSChangeCipherSpec || record.ContentType > models.TLSHeartbeat {
+		return record, false
+	}
+	if record.Version>>8 != 3 || record.Version&0xff > 4 {
+		return record, false
+	}
+	if record.Length == 0 || record.Length > maxTLSRecordLength {
+		return record, false
+	}
+	return record, true
+}
+
+// ParseTLSHandshakes decodes the complete handshake messages at the start of the
+// concatenated payload of handshake records, and returns them with the number of
+// bytes they take
+func ParseTLSHandshakes(data []byte) ([]models.TLSHandshakeInfo, int) {
+	var handshakes []models.TLSHandshakeInfo
+	consumed := 0
+
+	for len(data[consumed:]) >= 4 {
+		header := data[consumed:]
+		length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		if len(header) < 4+length {
+			break
+		}
+
+		handshake := models.TLSHandshakeInfo{Type: header[0], Length: length}
+		body := &tlsReader{data: header[4 : 4+length]}
+		switch handshake.Type {
+		case models.TLSClientHello:
+			parseTLSHello(&handshake, body, true)
+		case models.TLSServerHello:
+			parseTLSHello(&handshake, body, false)
+		case models.TLSCertificate:
+			parseTLSCertificates(&handshake, body)
+		}
+		handshakes = append(handshakes, handshake)
+		consumed += 4 + length
+	}
+
+	return handshakes, consumed
+}
+
+// parseTLSHello decodes a ClientHello or ServerHello body
+func parseTLSHello(handshake *models.TLSHandshakeInfo, r *tlsReader, client bool) {
+	handshake.Version = r.uint16()
+	r.skip(32)  // Random
+	r.vector(1) // Session ID
+	if client {
+		suites := r.vector(2)
+		for suites.remaining() >= 2 {
+			handshake.CipherSuites = append(handshake.CipherSuites, suites.uint16())
+		}
+		r.vector(1) // Compression methods
+	} else {
+		handshake.CipherSuites = []uint16{r.uint16()}
+		r.skip(1) // Compression method
+	}
+	if r.failed {
+		return
+	}
+
+	extensions := r.vector(2)
+	for extensions.remaining() >= 4 {
+		extType := extensions.uint16()
+		data := extensions.vector(2)
+		handshake.Extensions = append(handshake.Extensions, extType)
+
+		switch extType {
+		case tlsExtServerName:
+			names := data.vector(2)
+			for names.remaining() >= 3 {
+				nameType := names.uint8()
+				name := names.vector(2)
+				if nameType == 0 {
+					handshake.SNI = string(name.data)
+				}
+			}
+		case tlsExtALPN:
+			protocols := data.vector(2)
+			for protocols.remaining() >= 1 {
+				handshake.ALPN = append(handshake.ALPN, string(protocols.vector(1).data))
+			}
+		case tlsExtSupportedGroups:
+			groups := data.vector(2)
+			for groups.remaining() >= 2 {
+				handshake.SupportedGroups = append(handshake.SupportedGroups, groups.uint16())
+			}
+		case tlsExtECPointFormats:
+			formats := data.vector(1)
+			handshake.ECPointFormats = append(handshake.ECPointFormats, formats.data...)
+		case tlsExtSignatureAlgorithms:
+			algorithms := data.vector(2)
+			for algorithms.remaining() >= 2 {
+				handshake.SignatureAlgorithms = append(handshake.SignatureAlgorithms, algorithms.uint16())
+			}
+		case tlsExtSupportedVersions:
+			// The client sends a list, the server the version it selected
+			if client {
+				versions := data.vector(1)
+				for versions.remaining() >= 2 {
+					handshake.SupportedVersions = append(handshake.SupportedVersions, versions.uint16())
+				}
+			} else if data.remaining() >= 2 {
+				handshake.SupportedVersions = []uint16{data.uint16()}
+			}
+		}
+	}
+}
+
+// parseTLSCertificates decodes the certificate chain of a TLS 1.2 Certificate
+// message. TLS 1.3 sends it encrypted.
+func parseTLSCertificates(handshake *models.TLSHandshakeInfo, r *tlsReader) {
+	chain := r.vector(3)
+	for chain.remaining() >= 3 {
+		der := chain.vector(3)
+		if chain.failed {
+			return
+		}
+
+		cert, err := x509.ParseCertificate(der.data)
+		if err != nil {
+			continue
+		}
+		handshake.Certificates = append(handshake.Certificates, models.TLSCertificateInfo{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			DNSNames:  cert.DNSNames,
+		})
+	}
+}
+
+// tlsReader reads the big-endian fields and length-prefixed vectors of TLS
+// messages. Reading past the end sets failed and returns zero values.
+type tlsReader struct {
+	data   []byte
+	failed bool
+}
+
+// remaining returns the number of unread bytes
+func (r *tlsReader) remaining() int {
+	return len(r.data)
+}
+
+// next returns the next n bytes
+func (r *tlsReader) next(n int) []byte {
+	if r.failed || n > len(r.data) {
+		r.failed = true
+		r.data = nil
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// skip discards the next n bytes
+func (r *tlsReader) skip(n int) {
+	r.next(n)
+}
+
+// uint8 reads a single byte
+func (r *tlsReader) uint8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// uint16 reads a big-endian 16-bit value
+func (r *tlsReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+// vector reads a vector prefixed by a lengthSize byte length
+func (r *tlsReader) vector(lengthSize int) *tlsReader {
+	prefix := r.next(lengthSize)
+	if prefix == nil {
+		return &tlsReader{failed: true}
+	}
+
+	length := 0
+	for _, b := range prefix {
+		length = length<<8 | int(b)
+	}
+	data := r.next(length)
+	return &tlsReader{data: data, failed: r.failed}
+}
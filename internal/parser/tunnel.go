@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"encoding/binary"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// maxTunnelDepth bounds the number of nested tunnels that are decapsulated
+const maxTunnelDepth = 4
+
+// EtherTypes announced by tunnel headers
+const (
+	etherTypeTransparentBridging = 0x6558
+	etherTypeERSPAN              = 0x88BE // ERSPAN type I and II
+	etherTypeERSPAN3             = 0x22EB
+)
+
+// Lengths of the ERSPAN headers that precede the mirrored frame
+const (
+	erspan2HeaderLength    = 8
+	erspan3HeaderLength    = 12
+	erspan3SubheaderLength = 8
+)
+
+// decapsulate parses the packet carried by the frame's tunnel as its inner frame,
+// re-entering the parser so nested tunnels are decapsulated as well
+func (fp *FrameParser) decapsulate(frame *models.Frame, depth int) {
+	tunnel := frame.Tunnel
+	if tunnel == nil || depth >= maxTunnelDepth || tunnel.Offset <= 0 || tunnel.Offset >= len(frame.RawData) {
+		return
+	}
+
+	data := frame.RawData[tunnel.Offset:]
+	inner := &models.Frame{
+		ID:        frame.ID,
+		Timestamp: frame.Timestamp,
+		FrameType: models.EthernetFrame,
+		RawData:   data,
+		Length:    len(data),
+	}
+
+	switch tunnel.Protocol {
+	case etherTypeTransparentBridging:
+		inner.OriginalPacket = gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.DecodeOptions{NoCopy: true})
+		fp.ethernetParser.Parse(inner)
+		fp.networkParser.Parse(inner)
+	case 0x0800, 0x86DD:
+		// Inner IP packets have no link layer, the network layer starts at offset 0
+		inner.EtherType = tunnel.Protocol
+		firstLayer := networkLayerType(inner)
+		inner.OriginalPacket = gopacket.NewPacket(data, firstLayer, gopacket.DecodeOptions{NoCopy: true})
+		fp.networkParser.parseAt(inner, 0, firstLayer)
+		inner.Parsed = true
+	default:
+		return
+	}
+
+	frame.Inner = inner
+	fp.decapsulate(inner, depth+1)
+}
+
+// parseGRE converts a GRE header into a tunnel, locating the ERSPAN header and
+// mirrored frame that may follow it
+func parseGRE(frame *models.Frame, gre *layers.GRE, offset int) *models.TunnelInfo {
+	tunnel := &models.TunnelInfo{
+		Type:       "GRE",
+		Protocol:   uint16(gre.Protocol),
+		KeyPresent: gre.KeyPresent,
+		Key:        gre.Key,
+		SeqPresent: gre.SeqPresent,
+		Seq:        gre.Seq,
+	}
+
+	inner := offset + len(gre.Contents)
+	switch tunnel.Protocol {
+	case etherTypeERSPAN:
+		// Type I carries the mirrored frame right after GRE, type II adds a header
+		// and always sets the GRE sequence number
+		tunnel.Type = "ERSPAN"
+		tunnel.ERSPANVer = 1
+		if gre.SeqPresent {
+			if inner+erspan2HeaderLength > len(frame.RawData) {
+				return tunnel
+			}
+			parseERSPANHeader(tunnel, frame.RawData[inner:])
+			inner += erspan2HeaderLength
+		}
+		tunnel.Protocol = etherTypeTransparentBridging
+	case etherTypeERSPAN3:
+		tunnel.Type = "ERSPAN"
+		if inner+erspan3HeaderLength > len(frame.RawData) {
+			return tunnel
+		}
+		header := frame.RawData[inner:]
+		parseERSPANHeader(tunnel, header)
+		inner += erspan3HeaderLength
+		if header[11]&0x01 != 0 { // Platform specific subheader present
+			inner += erspan3SubheaderLength
+		}
+		tunnel.Protocol = etherTypeTransparentBridging
+	case etherTypeTransparentBridging, 0x0800, 0x86DD:
+	default:
+		// Other payloads (PPTP, WCCP, ...) are not decapsulated
+		return tunnel
+	}
+
+	if inner < len(frame.RawData) {
+		tunnel.Offset = inner
+	}
+	return tunnel
+}
+
+// parseERSPANHeader decodes the fields shared by the ERSPAN type II and III headers
+func parseERSPANHeader(tunnel *models.TunnelInfo, header []byte) {
+	tunnel.ERSPANVer = header[0]>>4 + 1 // Version 1 is type II, version 2 is type III
+	tunnel.ERSPANVLAN = binary.BigEndian.Uint16(header[0:2]) & 0x0fff
+	tunnel.SessionID = binary.BigEndian.Uint16(header[2:4]) & 0x03ff
+}
@@ -0,0 +1,684 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// A .gcap v2 file is a sequence of records that can be written as frames
+// arrive and read back one frame at a time:
+//
+//	header   "GCAP" | version uint16 | reserved uint16
+//	records  kind byte | length uint32 | payload
+//	footer   metadata offset uint64 | index offset uint64 | "GCAPIDX2"
+//
+// All integers are big endian. The first record holds the metadata known when
+// the capture started. Frames are encoded with a single gob stream: the type
+// definitions the encoder emits are stored in type records and the value of
+// each frame in its own frame record. Types held in interface values, such as
+// the maps of the analysis results, are defined inside the value message of
+// the first frame that holds them instead; such frames are stored in defining
+// frame records. Any frame can be decoded after the type records and the
+// defining frames that precede it. Closing the file appends the final
+// metadata, an index with the offset of every frame and type record, and the
+// footer. A file that was not closed, e.g. after a crash, is recovered by
+// scanning its records.
+//
+// Version 1 files are a gob stream of the metadata followed by the whole frame
+// slice, and are still readable.
+const (
+	gcapVersion = 2
+
+	recordMetadata = 'M'
+	recordTypes    = 'T'
+	recordFrame    = 'F'
+	recordDefining = 'D' // Frame whose value message defines nested types
+	recordIndex    = 'I'
+
+	gcapHeaderSize       = 8
+	gcapRecordHeaderSize = 5
+	gcapFooterSize       = 24
+)
+
+var (
+	gcapMagic       = []byte("GCAP")
+	gcapFooterMagic = []byte("GCAPIDX2")
+)
+
+// gcapIndex locates the records of a v2 file
+type gcapIndex struct {
+	Frames   []int64 // Offset of the record of each frame
+	Types    []int64 // Offset of each type definition record
+	Defining []int   // Position of each frame stored in a defining frame record, in order
+}
+
+// CaptureWriter writes a .gcap v2 file one frame at a time
+type CaptureWriter struct {
+	file     *os.File
+	writer   *bufio.Writer
+	encoder  *gob.Encoder
+	encoded  bytes.Buffer // Output of the gob encoder for the current frame
+	value    []byte       // Value message of the current frame
+	offset   int64
+	index    gcapIndex
+	metadata *SaveMetadata
+	first    time.Time
+	last     time.Time
+	err      error
+}
+
+// CreateCapture creates a .gcap file in the output directory and writes its
+// header. The filename of the metadata is generated when empty, and the
+// metadata is completed with the frame count and times when the writer is closed.
+func (sm *StorageManager) CreateCapture(metadata *SaveMetadata) (*CaptureWriter, error) {
+	metadata.Filename = captureFilename(metadata.Filename)
+	metadata.Version = gcapVersion
+
+	file, err := os.Create(filepath.Join(sm.outputDir, metadata.Filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %v", err)
+	}
+
+	registerGobTypes()
+	cw := &CaptureWriter{
+		file:     file,
+		writer:   bufio.NewWriter(file),
+		metadata: metadata,
+	}
+	cw.encoder = gob.NewEncoder(&cw.encoded)
+
+	header := make([]byte, gcapHeaderSize)
+	copy(header, gcapMagic)
+	binary.BigEndian.PutUint16(header[4:], gcapVersion)
+	cw.write(header)
+	cw.writeGob(recordMetadata, metadata)
+	if err := cw.flush(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+// captureFilename generates a filename when none is given and ensures the .gcap extension
+func captureFilename(filename string) string {
+	if filename == "" {
+		timestamp := time.Now().Format("20060102_150405")
+		filename = fmt.Sprintf("capture_%s.gcap", timestamp)
+	}
+	if filepath.Ext(filename) != ".gcap" {
+		filename += ".gcap"
+	}
+	return filepath.Base(filename)
+}
+
+// serializable copies a frame and the frames tunneled in it without their
+// original packets, which cannot be serialized
+func serializable(frame *models.Frame) *models.Frame {
+	copied := *frame
+	copied.OriginalPacket = nil
+	if frame.Inner != nil {
+		copied.Inner = serializable(frame.Inner)
+	}
+	return &copied
+}
+
+// WriteFrame appends a frame to the file and flushes it to disk
+func (cw *CaptureWriter) WriteFrame(frame *models.Frame) error {
+	if cw.err != nil {
+		return cw.err
+	}
+
+	serializableFrame := serializable(frame)
+
+	// A failed encoding may leave type definitions the encoder considers sent
+	// out of the file, so the frames after it could not be decoded
+	cw.encoded.Reset()
+	if err := cw.encoder.Encode(serializableFrame); err != nil {
+		cw.err = fmt.Errorf("failed to encode frame %d: %v", frame.ID, err)
+		return cw.err
+	}
+
+	// The encoder emits the definitions of the types it has not sent yet before the value
+	messages, err := splitGobMessages(cw.encoded.Bytes())
+	if err != nil {
+		cw.err = err
+		return err
+	}
+	last := messages[len(messages)-1]
+	if types := cw.encoded.Bytes()[:len(cw.encoded.Bytes())-len(last)]; len(types) > 0 {
+		cw.index.Types = append(cw.index.Types, cw.offset)
+		cw.writeRecord(recordTypes, types)
+	}
+	cw.value = append(cw.value[:0], last...)
+
+	// The value message also defines the nested types sent for the first
+	// time. Encoding the frame again, with those types already sent, gives a
+	// shorter message when it did.
+	cw.encoded.Reset()
+	if err := cw.encoder.Encode(serializableFrame); err != nil {
+		cw.err = fmt.Errorf("failed to encode frame %d: %v", frame.ID, err)
+		return cw.err
+	}
+	kind := byte(recordFrame)
+	if cw.encoded.Len() != len(cw.value) {
+		kind = recordDefining
+		cw.index.Defining = append(cw.index.Defining, len(cw.index.Frames))
+	}
+	cw.index.Frames = append(cw.index.Frames, cw.offset)
+	cw.writeRecord(kind, cw.value)
+
+	if cw.first.IsZero() {
+		cw.first = frame.Timestamp
+	}
+	cw.last = frame.Timestamp
+
+	return cw.flush()
+}
+
+// Frames returns the number of frames written
+func (cw *CaptureWriter) Frames() int {
+	return len(cw.index.Frames)
+}
+
+// Size returns the number of bytes written
+func (cw *CaptureWriter) Size() int64 {
+	return cw.offset
+}
+
+// Filename returns the name of the file within the output directory
+func (cw *CaptureWriter) Filename() string {
+	return cw.metadata.Filename
+}
+
+// Close completes the metadata, writes the index and the footer and closes the file
+func (cw *CaptureWriter) Close() error {
+	if cw.metadata.StartTime.IsZero() {
+		cw.metadata.StartTime = cw.first
+	}
+	if cw.metadata.EndTime.IsZero() {
+		cw.metadata.EndTime = cw.last
+	}
+	cw.metadata.FrameCount = len(cw.index.Frames)
+
+	metadataOffset := cw.offset
+	cw.writeGob(recordMetadata, cw.metadata)
+	indexOffset := cw.offset
+	cw.writeGob(recordIndex, &cw.index)
+
+	footer := make([]byte, gcapFooterSize)
+	binary.BigEndian.PutUint64(footer, uint64(metadataOffset))
+	binary.BigEndian.PutUint64(footer[8:], uint64(indexOffset))
+	copy(footer[16:], gcapFooterMagic)
+	cw.write(footer)
+
+	err := cw.flush()
+	if closeErr := cw.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close file: %v", closeErr)
+	}
+	return err
+}
+
+// writeGob writes a record holding a value encoded by its own gob encoder
+func (cw *CaptureWriter) writeGob(kind byte, value interface{}) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		if cw.err == nil {
+			cw.err = fmt.Errorf("failed to encode record: %v", err)
+		}
+		return
+	}
+	cw.writeRecord(kind, buf.Bytes())
+}
+
+// writeRecord writes a record header and its payload
+func (cw *CaptureWriter) writeRecord(kind byte, payload []byte) {
+	header := make([]byte, gcapRecordHeaderSize)
+	header[0] = kind
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	cw.write(header)
+	cw.write(payload)
+}
+
+// write writes bytes unless a previous write failed
+func (cw *CaptureWriter) write(b []byte) {
+	if cw.err != nil {
+		return
+	}
+	if _, err := cw.writer.Write(b); err != nil {
+		cw.err = fmt.Errorf("failed to write file: %v", err)
+		return
+	}
+	cw.offset += int64(len(b))
+}
+
+// flush writes the buffered bytes to the file
+func (cw *CaptureWriter) flush() error {
+	if cw.err == nil {
+		if err := cw.writer.Flush(); err != nil {
+			cw.err = fmt.Errorf("failed to write file: %v", err)
+		}
+	}
+	return cw.err
+}
+
+// CaptureReader reads the frames of a .gcap file in order or by position
+// without loading the whole file. Version 1 files are loaded in memory.
+type CaptureReader struct {
+	file     *os.File
+	metadata SaveMetadata
+	index    gcapIndex
+	decoder  *gob.Decoder // nil until a frame is decoded, or after a decoding error
+	source   messageSource
+	types    []byte // Type definitions of the type records
+	pending  []byte // Type definitions not yet given to the decoder
+	decoded  int    // The decoder was given the defining frames before this position, and none after
+	frames   []*models.Frame
+	next     int
+}
+
+// OpenCapture opens a .gcap file of the output directory
+func (sm *StorageManager) OpenCapture(filename string) (*CaptureReader, error) {
+	return openCapture(filepath.Join(sm.outputDir, filename))
+}
+
+// openCapture opens a .gcap file, reading its index or recovering it when the file was not closed
+func openCapture(path string) (*CaptureReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("file %s does not exist", filepath.Base(path))
+		}
+		return nil, fmt.Errorf("failed to open file: %v", err)
+	}
+
+	registerGobTypes()
+	cr := &CaptureReader{file: file}
+
+	version, err := readGcapVersion(file)
+	if err == nil {
+		if version == 1 {
+			err = cr.loadV1()
+		} else {
+			err = cr.loadIndex()
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return cr, nil
+}
+
+// readGcapVersion returns the version of a .gcap file from its header
+func readGcapVersion(file *os.File) (int, error) {
+	header := make([]byte, gcapHeaderSize)
+	if _, err := io.ReadFull(file, header); err != nil || !bytes.Equal(header[:4], gcapMagic) {
+		// Version 1 files have no header and start with the gob stream
+		return 1, nil
+	}
+	version := int(binary.BigEndian.Uint16(header[4:]))
+	if version != gcapVersion {
+		return 0, fmt.Errorf("unsupported .gcap version %d", version)
+	}
+	return version, nil
+}
+
+// loadV1 decodes a version 1 file, which holds every frame in a single gob value
+func (cr *CaptureReader) loadV1() error {
+	if _, err := cr.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+	decoder := gob.NewDecoder(bufio.NewReader(cr.file))
+	if err := decoder.Decode(&cr.metadata); err != nil {
+		return fmt.Errorf("failed to decode metadata: %v", err)
+	}
+	if err := decoder.Decode(&cr.frames); err != nil {
+		return fmt.Errorf("failed to decode frames: %v", err)
+	}
+	cr.metadata.Version = 1
+	return nil
+}
+
+// loadIndex reads the metadata and the index of a v2 file and the type
+// definitions needed to decode its frames
+func (cr *CaptureReader) loadIndex() error {
+	info, err := cr.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+
+	metadataOffset, indexOffset, closed := cr.readFooter(info.Size())
+	if closed {
+		if err := cr.readGobRecord(metadataOffset, recordMetadata, &cr.metadata); err != nil {
+			return err
+		}
+		if err := cr.readGobRecord(indexOffset, recordIndex, &cr.index); err != nil {
+			return err
+		}
+	} else if err := cr.recover(info.Size()); err != nil {
+		return err
+	}
+
+	for _, offset := range cr.index.Types {
+		payload, err := cr.readRecord(offset, recordTypes)
+		if err != nil {
+			return err
+		}
+		cr.types = append(cr.types, payload...)
+	}
+
+	// The last frame of a recovered file gives the end of the capture
+	if !closed && len(cr.index.Frames) > 0 {
+		if last, err := cr.Frame(len(cr.index.Frames) - 1); err == nil {
+			cr.metadata.EndTime = last.Timestamp
+		}
+		cr.next = 0
+	}
+	return nil
+}
+
+// readFooter returns the offsets stored in the footer, if the file has one
+func (cr *CaptureReader) readFooter(size int64) (int64, int64, bool) {
+	if size < gcapHeaderSize+gcapFooterSize {
+		return 0, 0, false
+	}
+	footer := make([]byte, gcapFooterSize)
+	if _, err := cr.file.ReadAt(footer, size-gcapFooterSize); err != nil || !bytes.Equal(footer[16:], gcapFooterMagic) {
+		return 0, 0, false
+	}
+	return int64(binary.BigEndian.Uint64(footer)), int64(binary.BigEndian.Uint64(footer[8:])), true
+}
+
+// recover rebuilds the index of a file that was not closed by scanning its
+// records. A record cut short by the end of the file is ignored.
+func (cr *CaptureReader) recover(size int64) error {
+	header := make([]byte, gcapRecordHeaderSize)
+	for offset := int64(gcapHeaderSize); offset+gcapRecordHeaderSize <= size; {
+		if _, err := cr.file.ReadAt(header, offset); err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+		end := offset + gcapRecordHeaderSize + int64(binary.BigEndian.Uint32(header[1:]))
+		if end > size {
+			break
+		}
+
+		switch header[0] {
+		case recordMetadata:
+			// The first metadata record is the one written when the capture started
+			if offset == gcapHeaderSize {
+				if err := cr.readGobRecord(offset, recordMetadata, &cr.metadata); err != nil {
+					return err
+				}
+			}
+		case recordTypes:
+			cr.index.Types = append(cr.index.Types, offset)
+		case recordFrame:
+			cr.index.Frames = append(cr.index.Frames, offset)
+		case recordDefining:
+			cr.index.Defining = append(cr.index.Defining, len(cr.index.Frames))
+			cr.index.Frames = append(cr.index.Frames, offset)
+		case recordIndex:
+		default:
+			return fmt.Errorf("corrupt record at offset %d", offset)
+		}
+		offset = end
+	}
+
+	cr.metadata.FrameCount = len(cr.index.Frames)
+	return nil
+}
+
+// readRecord returns the payload of a record of one of the given kinds
+func (cr *CaptureReader) readRecord(offset int64, kinds ...byte) ([]byte, error) {
+	header := make([]byte, gcapRecordHeaderSize)
+	if _, err := cr.file.ReadAt(header, offset); err != nil {
+		return nil, fmt.Errorf("failed to read record at offset %d: %v", offset, err)
+	}
+	if bytes.IndexByte(kinds, header[0]) < 0 {
+		return nil, fmt.Errorf("corrupt record at offset %d", offset)
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := cr.file.ReadAt(payload, offset+gcapRecordHeaderSize); err != nil {
+		return nil, fmt.Errorf("failed to read record at offset %d: %v", offset, err)
+	}
+	return payload, nil
+}
+
+// readGobRecord decodes a record holding a value encoded by its own gob encoder
+func (cr *CaptureReader) readGobRecord(offset int64, kind byte, value interface{}) error {
+	payload, err := cr.readRecord(offset, kind)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(value); err != nil {
+		return fmt.Errorf("failed to decode record at offset %d: %v", offset, err)
+	}
+	return nil
+}
+
+// Metadata returns the metadata of the capture
+func (cr *CaptureReader) Metadata() *SaveMetadata {
+	metadata := cr.metadata
+	return &metadata
+}
+
+// Len returns the number of frames in the capture
+func (cr *CaptureReader) Len() int {
+	if cr.frames != nil {
+		return len(cr.frames)
+	}
+	return len(cr.index.Frames)
+}
+
+// Next returns the next frame, or io.EOF after the last one
+func (cr *CaptureReader) Next() (*models.Frame, error) {
+	if cr.next >= cr.Len() {
+		return nil, io.EOF
+	}
+	n := cr.next
+	cr.next++
+
+	if cr.frames != nil {
+		return cr.frames[n], nil
+	}
+	return cr.decode(n)
+}
+
+// decode decodes the frame at position n. The decoder must have been given
+// the definitions of the defining frames before n, and cannot be given the
+// definitions of a frame twice, so it is recreated when a defining frame it
+// already decoded is read again.
+func (cr *CaptureReader) decode(n int) (*models.Frame, error) {
+	defining := sort.SearchInts(cr.index.Defining, n)
+	isDefining := defining < len(cr.index.Defining) && cr.index.Defining[defining] == n
+	if cr.decoder == nil || (isDefining && n < cr.decoded) {
+		cr.decoder = gob.NewDecoder(&cr.source)
+		cr.pending = cr.types
+		cr.decoded = 0
+	}
+
+	for _, position := range cr.index.Defining[:defining] {
+		if position >= cr.decoded {
+			if _, err := cr.decodeRecord(position); err != nil {
+				return nil, err
+			}
+		}
+	}
+	frame, err := cr.decodeRecord(n)
+	if err != nil {
+		return nil, err
+	}
+	if n >= cr.decoded {
+		cr.decoded = n + 1
+	}
+	return frame, nil
+}
+
+// decodeRecord gives the record of the frame at position n to the decoder
+func (cr *CaptureReader) decodeRecord(n int) (*models.Frame, error) {
+	payload, err := cr.readRecord(cr.index.Frames[n], recordFrame, recordDefining)
+	if err != nil {
+		return nil, err
+	}
+	if cr.pending != nil {
+		payload = append(append([]byte(nil), cr.pending...), payload...)
+		cr.pending = nil
+	}
+	cr.source.reset(payload)
+
+	var frame models.Frame
+	if err := cr.decoder.Decode(&frame); err != nil {
+		cr.decoder = nil
+		return nil, fmt.Errorf("failed to decode frame %d: %v", n, err)
+	}
+	return &frame, nil
+}
+
+// Seek positions the reader so that Next returns the frame at position n, counting from 0
+func (cr *CaptureReader) Seek(n int) error {
+	if n < 0 || n > cr.Len() {
+		return fmt.Errorf("frame %d out of range (%d frames)", n, cr.Len())
+	}
+	cr.next = n
+	return nil
+}
+
+// Frame returns the frame at position n, counting from 0
+func (cr *CaptureReader) Frame(n int) (*models.Frame, error) {
+	if err := cr.Seek(n); err != nil {
+		return nil, err
+	}
+	return cr.Next()
+}
+
+// Close closes the file
+func (cr *CaptureReader) Close() error {
+	return cr.file.Close()
+}
+
+// readCaptureMetadata reads the metadata of a .gcap file without decoding its frames
+func readCaptureMetadata(path string) (*SaveMetadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	version, err := readGcapVersion(file)
+	if err != nil {
+		return nil, err
+	}
+	if version == 1 {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		var metadata SaveMetadata
+		if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&metadata); err != nil {
+			return nil, err
+		}
+		metadata.Version = 1
+		return &metadata, nil
+	}
+
+	// Closed files keep the final metadata next to the footer, others are recovered
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	cr := &CaptureReader{file: file}
+	if metadataOffset, _, closed := cr.readFooter(info.Size()); closed {
+		if err := cr.readGobRecord(metadataOffset, recordMetadata, &cr.metadata); err != nil {
+			return nil, err
+		}
+		return cr.Metadata(), nil
+	}
+
+	reader, err := openCapture(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return reader.Metadata(), nil
+}
+
+// splitGobMessages splits the output of a gob encoder into its messages. Each
+// message starts with its length, and every message but the value is a type definition.
+func splitGobMessages(data []byte) ([][]byte, error) {
+	var messages [][]byte
+	for len(data) > 0 {
+		length, n, err := gobUint(data)
+		if err != nil || uint64(len(data)-n) < length {
+			return nil, errors.New("malformed gob message")
+		}
+		end := n + int(length)
+		messages = append(messages, data[:end])
+		data = data[end:]
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("empty gob message")
+	}
+	return messages, nil
+}
+
+// gobUint decodes an unsigned integer of the gob encoding: a single byte below
+// 0x80, or the negated count of the big endian bytes that follow
+func gobUint(data []byte) (uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	if data[0] < 0x80 {
+		return uint64(data[0]), 1, nil
+	}
+	n := -int(int8(data[0]))
+	if n > 8 || len(data) < 1+n {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	var value uint64
+	for _, b := range data[1 : 1+n] {
+		value = value<<8 | uint64(b)
+	}
+	return value, 1 + n, nil
+}
+
+// messageSource feeds the gob decoder of a reader one frame at a time. It
+// implements io.ByteReader so the decoder does not read ahead.
+type messageSource struct {
+	data []byte
+}
+
+// reset replaces the bytes given to the decoder
+func (s *messageSource) reset(data []byte) {
+	s.data = data
+}
+
+// Read implements io.Reader
+func (s *messageSource) Read(p []byte) (int, error) {
+	if len(s.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, s.data)
+	s.data = s.data[n:]
+	return n, nil
+}
+
+// ReadByte implements io.ByteReader
+func (s *messageSource) ReadByte() (byte, error) {
+	if len(s.data) == 0 {
+		return 0, io.EOF
+	}
+	b := s.data[0]
+	s.data = s.data[1:]
+	return b, nil
+}
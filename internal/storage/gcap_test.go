@@ -0,0 +1,320 @@
+package storage
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// testFrames returns frames whose analysis results hold the types gob defines
+// inside value messages: maps nested in interface values
+func testFrames(n int) []*models.Frame {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	frames := make([]*models.Frame, n)
+	for i := range frames {
+		frame := &models.Frame{
+			ID:        int64(i + 1),
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			FrameType: models.WLANManagementFrame,
+			RawData:   []byte{0x80, 0x00, byte(i)},
+			Length:    3,
+			Address2:  "00:11:22:33:44:55",
+			AnalysisResults: map[string]interface{}{
+				"Summary": "Beacon",
+				"ManagementInfo": map[string]interface{}{
+					"CapabilityInfo": map[string]bool{"ESS": true, "Privacy": i%2 == 0},
+				},
+			},
+		}
+		// Types first sent in later frames are defined by those frames
+		if i == n/2 {
+			frame.AnalysisResults["ARP"] = map[string]interface{}{"Alerts": []string{"Binding conflict"}}
+		}
+		frames[i] = frame
+	}
+	return frames
+}
+
+// writeTestCapture writes frames to a .gcap file and returns the writer without closing it
+func writeTestCapture(t *testing.T, sm *StorageManager, frames []*models.Frame) *CaptureWriter {
+	t.Helper()
+	writer, err := sm.CreateCapture(&SaveMetadata{Filename: "test.gcap", Interface: "wlan0"})
+	if err != nil {
+		t.Fatalf("CreateCapture: %v", err)
+	}
+	for _, frame := range frames {
+		if err := writer.WriteFrame(frame); err != nil {
+			t.Fatalf("WriteFrame(%d): %v", frame.ID, err)
+		}
+	}
+	return writer
+}
+
+// checkFrame compares a decoded frame with the frame that was written
+func checkFrame(t *testing.T, got, want *models.Frame) {
+	t.Helper()
+	if got.ID != want.ID || !got.Timestamp.Equal(want.Timestamp) || string(got.RawData) != string(want.RawData) {
+		t.Fatalf("frame %d decoded as ID %d at %v with data %x", want.ID, got.ID, got.Timestamp, got.RawData)
+	}
+	info, ok := got.AnalysisResults["ManagementInfo"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("frame %d lost its management info: %v", want.ID, got.AnalysisResults)
+	}
+	capabilities, ok := info["CapabilityInfo"].(map[string]bool)
+	wantPrivacy := want.AnalysisResults["ManagementInfo"].(map[string]interface{})["CapabilityInfo"].(map[string]bool)["Privacy"]
+	if !ok || !capabilities["ESS"] || capabilities["Privacy"] != wantPrivacy {
+		t.Fatalf("frame %d capabilities = %v", want.ID, info["CapabilityInfo"])
+	}
+}
+
+func TestCaptureRoundTrip(t *testing.T) {
+	sm, err := NewStorageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := testFrames(6)
+	if err := writeTestCapture(t, sm, frames).Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reader, err := sm.OpenCapture("test.gcap")
+	if err != nil {
+		t.Fatalf("OpenCapture: %v", err)
+	}
+	defer reader.Close()
+
+	metadata := reader.Metadata()
+	if metadata.FrameCount != len(frames) || metadata.Version != gcapVersion || metadata.Interface != "wlan0" {
+		t.Fatalf("metadata = %+v", metadata)
+	}
+	if !metadata.StartTime.Equal(frames[0].Timestamp) || !metadata.EndTime.Equal(frames[len(frames)-1].Timestamp) {
+		t.Fatalf("capture spans %v - %v", metadata.StartTime, metadata.EndTime)
+	}
+
+	for _, want := range frames {
+		got, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		checkFrame(t, got, want)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("Next after the last frame = %v, want io.EOF", err)
+	}
+	alerts := frames[3].AnalysisResults["ARP"].(map[string]interface{})["Alerts"]
+	if len(alerts.([]string)) != 1 {
+		t.Fatalf("alerts = %v", alerts)
+	}
+}
+
+func TestCaptureRandomAccess(t *testing.T) {
+	sm, err := NewStorageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := testFrames(8)
+	if err := writeTestCapture(t, sm, frames).Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Jump around, reading frames that define nested types before and after
+	// the decoder saw them
+	for _, n := range []int{2, 7, 4, 0, 0, 5, 1, 4, 6} {
+		reader, err := sm.OpenCapture("test.gcap")
+		if err != nil {
+			t.Fatalf("OpenCapture: %v", err)
+		}
+		got, err := reader.Frame(n)
+		if err != nil {
+			t.Fatalf("Frame(%d) on a new reader: %v", n, err)
+		}
+		checkFrame(t, got, frames[n])
+		reader.Close()
+	}
+
+	reader, err := sm.OpenCapture("test.gcap")
+	if err != nil {
+		t.Fatalf("OpenCapture: %v", err)
+	}
+	defer reader.Close()
+	for _, n := range []int{5, 2, 7, 4, 0, 0, 3, 6, 1} {
+		got, err := reader.Frame(n)
+		if err != nil {
+			t.Fatalf("Frame(%d): %v", n, err)
+		}
+		checkFrame(t, got, frames[n])
+	}
+
+	if err := reader.Seek(6); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	for n := 6; n < len(frames); n++ {
+		got, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Next after Seek: %v", err)
+		}
+		checkFrame(t, got, frames[n])
+	}
+	if err := reader.Seek(len(frames) + 1); err == nil {
+		t.Fatal("Seek past the end succeeded")
+	}
+}
+
+func TestCaptureRecovery(t *testing.T) {
+	dir := t.TempDir()
+	sm, err := NewStorageManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := testFrames(5)
+
+	// A capture interrupted before closing: no index or footer, and the last
+	// record cut short
+	writer := writeTestCapture(t, sm, frames)
+	if err := writer.file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "test.gcap")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := sm.OpenCapture("test.gcap")
+	if err != nil {
+		t.Fatalf("OpenCapture: %v", err)
+	}
+	defer reader.Close()
+
+	recovered := len(frames) - 1
+	if reader.Len() != recovered || reader.Metadata().FrameCount != recovered {
+		t.Fatalf("recovered %d frames (metadata %d), want %d", reader.Len(), reader.Metadata().FrameCount, recovered)
+	}
+	if end := reader.Metadata().EndTime; !end.Equal(frames[recovered-1].Timestamp) {
+		t.Fatalf("EndTime = %v, want %v", end, frames[recovered-1].Timestamp)
+	}
+	for n := 0; n < recovered; n++ {
+		got, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		checkFrame(t, got, frames[n])
+	}
+
+	captures, err := sm.ListSavedCaptures()
+	if err != nil || len(captures) != 1 || captures[0].FrameCount != recovered {
+		t.Fatalf("ListSavedCaptures = %v, %v", captures, err)
+	}
+}
+
+func TestCaptureVersion1(t *testing.T) {
+	dir := t.TempDir()
+	sm, err := NewStorageManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := testFrames(3)
+
+	// Version 1 files are a gob stream of the metadata and the frame slice
+	file, err := os.Create(filepath.Join(dir, "old.gcap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerGobTypes()
+	encoder := gob.NewEncoder(file)
+	metadata := SaveMetadata{Filename: "old.gcap", Interface: "wlan0", FrameCount: len(frames)}
+	if err := encoder.Encode(metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := encoder.Encode(frames); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	loaded, loadedMetadata, err := sm.LoadFrames("old.gcap")
+	if err != nil {
+		t.Fatalf("LoadFrames: %v", err)
+	}
+	if loadedMetadata.Version != 1 || loadedMetadata.FrameCount != len(frames) || len(loaded) != len(frames) {
+		t.Fatalf("loaded %d frames with metadata %+v", len(loaded), loadedMetadata)
+	}
+	for n, want := range frames {
+		checkFrame(t, loaded[n], want)
+	}
+
+	reader, err := sm.OpenCapture("old.gcap")
+	if err != nil {
+		t.Fatalf("OpenCapture: %v", err)
+	}
+	defer reader.Close()
+	got, err := reader.Frame(2)
+	if err != nil {
+		t.Fatalf("Frame(2): %v", err)
+	}
+	checkFrame(t, got, frames[2])
+}
+
+func TestCaptureEncodeError(t *testing.T) {
+	sm, err := NewStorageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := writeTestCapture(t, sm, testFrames(2))
+
+	// Types that are not registered cannot be encoded in interface values
+	bad := testFrames(1)[0]
+	bad.AnalysisResults["Unregistered"] = struct{ X int }{1}
+	if err := writer.WriteFrame(bad); err == nil {
+		t.Fatal("WriteFrame of an unregistered type succeeded")
+	}
+	if err := writer.WriteFrame(testFrames(1)[0]); err == nil {
+		t.Fatal("WriteFrame after an encoding error succeeded")
+	}
+	writer.Close()
+}
+
+func TestCaptureTunneledFrame(t *testing.T) {
+	sm, err := NewStorageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The parser decodes the inner frame of a tunnel into its own packet
+	innerData := []byte{0, 1, 2, 3, 4, 5, 0, 1, 2, 3, 4, 6, 0x88, 0xb5, 0xaa}
+	frame := testFrames(1)[0]
+	frame.OriginalPacket = gopacket.NewPacket(frame.RawData, layers.LayerTypeDot11, gopacket.Default)
+	frame.Inner = &models.Frame{
+		ID:             frame.ID,
+		FrameType:      models.EthernetFrame,
+		RawData:        innerData,
+		Length:         len(innerData),
+		OriginalPacket: gopacket.NewPacket(innerData, layers.LayerTypeEthernet, gopacket.Default),
+	}
+	frame.Tunnel = &models.TunnelInfo{Type: "VXLAN"}
+	if err := sm.SaveFrames([]*models.Frame{frame}, &SaveMetadata{Filename: "tunnel.gcap"}); err != nil {
+		t.Fatalf("SaveFrames: %v", err)
+	}
+	if frame.Inner.OriginalPacket == nil {
+		t.Fatal("saving cleared the packet of the frame being saved")
+	}
+
+	loaded, _, err := sm.LoadFrames("tunnel.gcap")
+	if err != nil {
+		t.Fatalf("LoadFrames: %v", err)
+	}
+	checkFrame(t, loaded[0], frame)
+	inner := loaded[0].Inner
+	if inner == nil || string(inner.RawData) != string(innerData) || loaded[0].Tunnel == nil || loaded[0].Tunnel.Type != "VXLAN" {
+		t.Fatalf("tunnel %+v decoded with inner frame %+v", loaded[0].Tunnel, inner)
+	}
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// HAR is an HTTP Archive 1.2 document, as read by browsers' network tools
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the root object of an HTTP Archive
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator names the application that created an HTTP Archive
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is an HTTP request and its response. Times are in milliseconds,
+// -1 when they do not apply.
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+}
+
+// HARRequest is the request of an HTTP Archive entry
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse is the response of an HTTP Archive entry
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARContent describes the body of a response
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// HARTimings splits the time of an entry into its phases
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HARNameValue is a header, cookie or query string parameter
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewHAR creates an empty HTTP Archive created by gocapture
+func NewHAR() *HAR {
+	return &HAR{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "gocapture", Version: "dev"},
+			Entries: []HAREntry{},
+		},
+	}
+}
+
+// SaveHAR writes an HTTP Archive in the output directory and returns the name of the file
+func (sm *StorageManager) SaveHAR(filename string, har *HAR) (string, error) {
+	if filepath.Ext(filename) != ".har" {
+		filename += ".har"
+	}
+
+	data, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode HAR: %v", err)
+	}
+
+	return sm.SaveFile(filename, data)
+}
@@ -0,0 +1,370 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// PacketFormat is a packet file format read by Wireshark and tcpdump
+type PacketFormat int
+
+const (
+	// FormatPcap is the classic libpcap format: a single link type and no comments
+	FormatPcap PacketFormat = iota
+	// FormatPcapng holds one interface per link type and a comment per packet
+	FormatPcapng
+)
+
+// String returns the file extension of the format
+func (f PacketFormat) String() string {
+	if f == FormatPcap {
+		return "pcap"
+	}
+	return "pcapng"
+}
+
+// Both formats are written in little endian. Classic pcap files have
+// microsecond timestamps; pcapng interfaces declare nanosecond timestamps
+// with the if_tsresol option.
+const (
+	pcapMagic         = 0xa1b2c3d4
+	pcapSnapLen       = 262144
+	pcapngByteOrder   = 0x1a2b3c4d
+	pcapngTSResolNano = 9
+
+	pcapngBlockSHB = 0x0a0d0d0a
+	pcapngBlockIDB = 0x00000001
+	pcapngBlockEPB = 0x00000006
+
+	pcapngOptEnd       = 0
+	pcapngOptComment   = 1
+	pcapngOptIfName    = 2 // Interface description block option
+	pcapngOptUserAppl  = 4 // Section header block option
+	pcapngOptIfTSResol = 9 // Interface description block option
+)
+
+// PacketWriter writes frames to a pcap or pcapng file one frame at a time, with
+// their original timestamps, lengths and link types
+type PacketWriter struct {
+	file          *os.File
+	writer        *bufio.Writer
+	format        PacketFormat
+	filename      string
+	interfaceName string
+	linkType      layers.LinkType         // Link type of a pcap file, set by its first frame
+	interfaces    map[layers.LinkType]int // Interface ID of each link type of a pcapng file
+	frames        int
+	offset        int64
+	err           error
+}
+
+// CreatePacketFile creates a pcap or pcapng file in the output directory. The
+// format is chosen by the extension of the filename, and .pcapng is added when
+// it has neither. The interface name is recorded in the pcapng interface
+// description blocks.
+func (sm *StorageManager) CreatePacketFile(filename, interfaceName string) (*PacketWriter, error) {
+	filename, format := packetFilename(filename)
+
+	file, err := os.Create(filepath.Join(sm.outputDir, filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %v", err)
+	}
+
+	pw := &PacketWriter{
+		file:          file,
+		writer:        bufio.NewWriter(file),
+		format:        format,
+		filename:      filename,
+		interfaceName: interfaceName,
+		interfaces:    make(map[layers.LinkType]int),
+	}
+
+	// The pcap header needs the link type, so it is written with the first frame
+	if format == FormatPcapng {
+		var options bytes.Buffer
+		writePcapngOption(&options, pcapngOptUserAppl, []byte("gocapture"))
+		writePcapngOption(&options, pcapngOptEnd, nil)
+
+		body := make([]byte, 16)
+		binary.LittleEndian.PutUint32(body, pcapngByteOrder)
+		binary.LittleEndian.PutUint16(body[4:], 1)                  // Major version
+		binary.LittleEndian.PutUint16(body[6:], 0)                  // Minor version
+		binary.LittleEndian.PutUint64(body[8:], 0xffffffffffffffff) // Section length not specified
+		pw.writeBlock(pcapngBlockSHB, append(body, options.Bytes()...))
+		if err := pw.flush(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return pw, nil
+}
+
+// packetFilename generates a filename when none is given and returns the format its extension selects
+func packetFilename(filename string) (string, PacketFormat) {
+	if filename == "" {
+		timestamp := time.Now().Format("20060102_150405")
+		filename = fmt.Sprintf("capture_%s", timestamp)
+	}
+	filename = filepath.Base(filename)
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".pcap":
+		return filename, FormatPcap
+	case ".pcapng":
+		return filename, FormatPcapng
+	default:
+		return filename + ".pcapng", FormatPcapng
+	}
+}
+
+// WriteFrame appends a frame to the file and flushes it to disk. A pcap file
+// holds a single link type, so frames of other link types are rejected.
+func (pw *PacketWriter) WriteFrame(frame *models.Frame) error {
+	if pw.err != nil {
+		return pw.err
+	}
+
+	linkType := frameLinkType(frame)
+	originalLength := frame.OriginalLength
+	if originalLength < len(frame.RawData) {
+		originalLength = len(frame.RawData)
+	}
+
+	if pw.format == FormatPcap {
+		if pw.frames == 0 {
+			pw.linkType = linkType
+			pw.writePcapHeader()
+		} else if linkType != pw.linkType {
+			return fmt.Errorf("frame %d has link type %s but the pcap file holds %s frames; export to pcapng to mix link types",
+				frame.ID, linkType, pw.linkType)
+		}
+
+		record := make([]byte, 16)
+		binary.LittleEndian.PutUint32(record, uint32(frame.Timestamp.Unix()))
+		binary.LittleEndian.PutUint32(record[4:], uint32(frame.Timestamp.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:], uint32(len(frame.RawData)))
+		binary.LittleEndian.PutUint32(record[12:], uint32(originalLength))
+		pw.write(record)
+		pw.write(frame.RawData)
+	} else {
+		interfaceID, ok := pw.interfaces[linkType]
+		if !ok {
+			interfaceID = len(pw.interfaces)
+			pw.interfaces[linkType] = interfaceID
+			pw.writeInterface(linkType)
+		}
+
+		var options bytes.Buffer
+		comments := frameComments(frame)
+		for _, comment := range comments {
+			writePcapngOption(&options, pcapngOptComment, []byte(comment))
+		}
+		if len(comments) > 0 {
+			writePcapngOption(&options, pcapngOptEnd, nil)
+		}
+
+		timestamp := uint64(frame.Timestamp.UnixNano())
+		body := make([]byte, 20, 20+len(frame.RawData)+3+options.Len())
+		binary.LittleEndian.PutUint32(body, uint32(interfaceID))
+		binary.LittleEndian.PutUint32(body[4:], uint32(timestamp>>32))
+		binary.LittleEndian.PutUint32(body[8:], uint32(timestamp))
+		binary.LittleEndian.PutUint32(body[12:], uint32(len(frame.RawData)))
+		binary.LittleEndian.PutUint32(body[16:], uint32(originalLength))
+		body = append(body, frame.RawData...)
+		body = append(body, make([]byte, pcapngPadding(len(frame.RawData)))...)
+		pw.writeBlock(pcapngBlockEPB, append(body, options.Bytes()...))
+	}
+
+	pw.frames++
+	return pw.flush()
+}
+
+// Frames returns the number of frames written
+func (pw *PacketWriter) Frames() int {
+	return pw.frames
+}
+
+// Size returns the number of bytes written
+func (pw *PacketWriter) Size() int64 {
+	return pw.offset
+}
+
+// Filename returns the name of the file within the output directory
+func (pw *PacketWriter) Filename() string {
+	return pw.filename
+}
+
+// Format returns the format of the file
+func (pw *PacketWriter) Format() PacketFormat {
+	return pw.format
+}
+
+// Close closes the file. A pcap file without frames gets an Ethernet header so
+// that it can still be opened.
+func (pw *PacketWriter) Close() error {
+	if pw.format == FormatPcap && pw.frames == 0 {
+		pw.linkType = layers.LinkTypeEthernet
+		pw.writePcapHeader()
+	}
+
+	err := pw.flush()
+	if closeErr := pw.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close file: %v", closeErr)
+	}
+	return err
+}
+
+// writePcapHeader writes the global header of a pcap file
+func (pw *PacketWriter) writePcapHeader() {
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header, pcapMagic)
+	binary.LittleEndian.PutUint16(header[4:], 2) // Major version
+	binary.LittleEndian.PutUint16(header[6:], 4) // Minor version
+	binary.LittleEndian.PutUint32(header[16:], pcapSnapLen)
+	binary.LittleEndian.PutUint32(header[20:], uint32(pw.linkType))
+	pw.write(header)
+}
+
+// writeInterface writes the interface description block of a link type
+func (pw *PacketWriter) writeInterface(linkType layers.LinkType) {
+	var options bytes.Buffer
+	if pw.interfaceName != "" {
+		writePcapngOption(&options, pcapngOptIfName, []byte(pw.interfaceName))
+	}
+	writePcapngOption(&options, pcapngOptIfTSResol, []byte{pcapngTSResolNano})
+	writePcapngOption(&options, pcapngOptEnd, nil)
+
+	body := make([]byte, 8)
+	binary.LittleEndian.PutUint16(body, uint16(linkType))
+	binary.LittleEndian.PutUint32(body[4:], pcapSnapLen)
+	pw.writeBlock(pcapngBlockIDB, append(body, options.Bytes()...))
+}
+
+// writeBlock writes a pcapng block, whose total length is stored before and after its body
+func (pw *PacketWriter) writeBlock(blockType uint32, body []byte) {
+	header := make([]byte, 8)
+	length := uint32(len(body) + 12)
+	binary.LittleEndian.PutUint32(header, blockType)
+	binary.LittleEndian.PutUint32(header[4:], length)
+	pw.write(header)
+	pw.write(body)
+	pw.write(header[4:])
+}
+
+// write writes bytes unless a previous write failed
+func (pw *PacketWriter) write(b []byte) {
+	if pw.err != nil {
+		return
+	}
+	if _, err := pw.writer.Write(b); err != nil {
+		pw.err = fmt.Errorf("failed to write file: %v", err)
+		return
+	}
+	pw.offset += int64(len(b))
+}
+
+// flush writes the buffered bytes to the file
+func (pw *PacketWriter) flush() error {
+	if pw.err == nil {
+		if err := pw.writer.Flush(); err != nil {
+			pw.err = fmt.Errorf("failed to write file: %v", err)
+		}
+	}
+	return pw.err
+}
+
+// writePcapngOption appends an option, padded to 32 bits, to the options of a block
+func writePcapngOption(buf *bytes.Buffer, code uint16, value []byte) {
+	header := make([]byte, 4)
+	binary.LittleEndian.PutUint16(header, code)
+	binary.LittleEndian.PutUint16(header[2:], uint16(len(value)))
+	buf.Write(header)
+	buf.Write(value)
+	buf.Write(make([]byte, pcapngPadding(len(value))))
+}
+
+// pcapngPadding returns the number of bytes that align a length to 32 bits
+func pcapngPadding(length int) int {
+	return (4 - length%4) % 4
+}
+
+// frameLinkType returns the link type of the capture a frame was read from.
+// Frames saved before the link type was recorded, which also lack the
+// original length, have it inferred from their decoded link layer.
+func frameLinkType(frame *models.Frame) layers.LinkType {
+	if frame.OriginalLength > 0 {
+		return frame.LinkType
+	}
+	switch {
+	case frame.FrameType == models.EthernetFrame:
+		return layers.LinkTypeEthernet
+	case frame.RadioTap != nil:
+		return layers.LinkTypeIEEE80211Radio
+	default:
+		return layers.LinkTypeIEEE802_11
+	}
+}
+
+// frameComments returns the annotations of a frame written as packet comments:
+// its analysis summary followed by the alerts raised by the analyzers
+func frameComments(frame *models.Frame) []string {
+	var comments []string
+	if summary, ok := frame.AnalysisResults["Summary"].(string); ok && summary != "" {
+		comments = append(comments, summary)
+	}
+
+	keys := make([]string, 0, len(frame.AnalysisResults))
+	for key := range frame.AnalysisResults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		info, ok := frame.AnalysisResults[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if alerts, ok := info["Alerts"].([]string); ok {
+			for _, alert := range alerts {
+				comments = append(comments, fmt.Sprintf("%s alert: %s", key, alert))
+			}
+		}
+	}
+
+	return comments
+}
+
+// ExportFrames writes frames to a pcap or pcapng file in the output directory
+// and returns the name of the file. The format is chosen as in CreatePacketFile.
+func (sm *StorageManager) ExportFrames(filename string, frames []*models.Frame, interfaceName string) (string, error) {
+	if len(frames) == 0 {
+		return "", fmt.Errorf("no frames to export")
+	}
+
+	writer, err := sm.CreatePacketFile(filename, interfaceName)
+	if err != nil {
+		return "", err
+	}
+	for _, frame := range frames {
+		if err := writer.WriteFrame(frame); err != nil {
+			writer.Close()
+			os.Remove(filepath.Join(sm.outputDir, writer.Filename()))
+			return "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return writer.Filename(), nil
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// FrameWriter writes frames to a file one frame at a time. CaptureWriter and
+// PacketWriter implement it.
+type FrameWriter interface {
+	WriteFrame(frame *models.Frame) error
+	Frames() int
+	Size() int64
+	Filename() string
+	Close() error
+}
+
+// Formats of the files written by a RotatingWriter
+const (
+	RingFormatGcap   = "gcap"
+	RingFormatPcap   = "pcap"
+	RingFormatPcapng = "pcapng"
+)
+
+// RotationPolicy sets when a RotatingWriter starts a new file and how many files it keeps
+type RotationPolicy struct {
+	Prefix      string        // Start of the name of every file, "ring" when empty
+	Format      string        // RingFormatGcap (default), RingFormatPcap or RingFormatPcapng
+	MaxBytes    int64         // Start a new file once the current one holds this many bytes, 0 for no limit
+	MaxDuration time.Duration // Start a new file once the current one spans this long, 0 for no limit
+	MaxFrames   int           // Start a new file once the current one holds this many frames, 0 for no limit
+	MaxFiles    int           // Delete the oldest file when there are more, 0 to keep them all
+}
+
+// Validate reports whether the policy limits the files it writes
+func (p RotationPolicy) Validate() error {
+	switch {
+	case p.MaxBytes < 0 || p.MaxDuration < 0 || p.MaxFrames < 0 || p.MaxFiles < 0:
+		return fmt.Errorf("rotation limits cannot be negative")
+	case p.MaxBytes == 0 && p.MaxDuration == 0 && p.MaxFrames == 0:
+		return fmt.Errorf("a rotation policy needs a size, duration or frame limit")
+	case p.Prefix != "" && filepath.Base(p.Prefix) != p.Prefix:
+		return fmt.Errorf("invalid ring file prefix %q, the files are written to the output directory", p.Prefix)
+	}
+	switch p.Format {
+	case "", RingFormatGcap, RingFormatPcap, RingFormatPcapng:
+		return nil
+	default:
+		return fmt.Errorf("unknown ring file format %q, expected gcap, pcap or pcapng", p.Format)
+	}
+}
+
+// RotatingWriter records frames in a ring of files in the output directory.
+// The files are named <prefix>_<sequence>_<time of their first frame>, e.g.
+// ring_00003_20250101120000.gcap, so they sort in the order they were written.
+// A file is started when the first frame arrives, and durations are measured
+// with the frame timestamps. Closing the writer closes the current file; the
+// next frame written starts a new one, so a writer can span several capture
+// sessions. It is safe for concurrent use.
+type RotatingWriter struct {
+	sm       *StorageManager
+	policy   RotationPolicy
+	metadata SaveMetadata // Interface and description of every .gcap file
+	current  FrameWriter
+	started  time.Time // Timestamp of the first frame of the current file
+	sequence int
+	files    []string // Files of the ring, oldest first
+	frames   int
+	mutex    sync.Mutex
+}
+
+// CreateRotatingWriter creates a writer that records frames in a ring of files
+// following a rotation policy
+func (sm *StorageManager) CreateRotatingWriter(policy RotationPolicy, metadata SaveMetadata) (*RotatingWriter, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if policy.Prefix == "" {
+		policy.Prefix = "ring"
+	}
+	if policy.Format == "" {
+		policy.Format = RingFormatGcap
+	}
+
+	return &RotatingWriter{
+		sm:       sm,
+		policy:   policy,
+		metadata: metadata,
+	}, nil
+}
+
+// WriteFrame writes a frame to the current file, first starting a new file
+// when the current one reached a limit of the policy
+func (rw *RotatingWriter) WriteFrame(frame *models.Frame) error {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	if rw.current != nil && rw.full(frame) {
+		if err := rw.closeCurrent(); err != nil {
+			return err
+		}
+	}
+	if rw.current == nil {
+		if err := rw.open(frame.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	if err := rw.current.WriteFrame(frame); err != nil {
+		return err
+	}
+	rw.frames++
+	return nil
+}
+
+// full reports whether the current file reached a limit before writing a frame
+func (rw *RotatingWriter) full(frame *models.Frame) bool {
+	policy := rw.policy
+	return (policy.MaxBytes > 0 && rw.current.Size() >= policy.MaxBytes) ||
+		(policy.MaxFrames > 0 && rw.current.Frames() >= policy.MaxFrames) ||
+		(policy.MaxDuration > 0 && frame.Timestamp.Sub(rw.started) >= policy.MaxDuration)
+}
+
+// open starts the next file of the ring and deletes the oldest files beyond the limit
+func (rw *RotatingWriter) open(started time.Time) error {
+	rw.sequence++
+	filename := fmt.Sprintf("%s_%05d_%s.%s", rw.policy.Prefix, rw.sequence, started.Format("20060102150405"), rw.policy.Format)
+
+	var err error
+	if rw.policy.Format == RingFormatGcap {
+		metadata := rw.metadata
+		metadata.Filename = filename
+		rw.current, err = rw.sm.CreateCapture(&metadata)
+	} else {
+		rw.current, err = rw.sm.CreatePacketFile(filename, rw.metadata.Interface)
+	}
+	if err != nil {
+		rw.current = nil
+		return err
+	}
+	rw.started = started
+	rw.files = append(rw.files, rw.current.Filename())
+
+	for rw.policy.MaxFiles > 0 && len(rw.files) > rw.policy.MaxFiles {
+		if err := os.Remove(filepath.Join(rw.sm.outputDir, rw.files[0])); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete ring file: %v", err)
+		}
+		rw.files = rw.files[1:]
+	}
+	return nil
+}
+
+// closeCurrent closes the current file
+func (rw *RotatingWriter) closeCurrent() error {
+	err := rw.current.Close()
+	rw.current = nil
+	return err
+}
+
+// Filename returns the name of the file being written, empty between files
+func (rw *RotatingWriter) Filename() string {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	if rw.current == nil {
+		return ""
+	}
+	return rw.current.Filename()
+}
+
+// Files returns the files of the ring that were not deleted, oldest first
+func (rw *RotatingWriter) Files() []string {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	return append([]string(nil), rw.files...)
+}
+
+// Frames returns the number of frames written to every file of the ring
+func (rw *RotatingWriter) Frames() int {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	return rw.frames
+}
+
+// Close closes the current file
+func (rw *RotatingWriter) Close() error {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	if rw.current == nil {
+		return nil
+	}
+	return rw.closeCurrent()
+}
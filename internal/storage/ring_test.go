@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// ringFiles returns the files a ring left in the output directory, sorted by name
+func ringFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotationPolicyValidate(t *testing.T) {
+	for _, policy := range []RotationPolicy{
+		{},
+		{MaxFiles: 3},
+		{MaxFrames: -1},
+		{MaxFrames: 10, MaxFiles: -1},
+		{MaxFrames: 10, Prefix: "../ring"},
+		{MaxFrames: 10, Format: "pcapx"},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", policy)
+		}
+	}
+	for _, policy := range []RotationPolicy{
+		{MaxFrames: 10},
+		{MaxBytes: 1000, Format: RingFormatPcap},
+		{MaxDuration: time.Minute, MaxFiles: 2, Prefix: "office", Format: RingFormatPcapng},
+	} {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", policy, err)
+		}
+	}
+}
+
+func TestRotatingWriterKeepsLastFiles(t *testing.T) {
+	for _, format := range []string{RingFormatGcap, RingFormatPcap, RingFormatPcapng} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			sm, err := NewStorageManager(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ring, err := sm.CreateRotatingWriter(RotationPolicy{Format: format, MaxFrames: 2, MaxFiles: 3}, SaveMetadata{Interface: "wlan0"})
+			if err != nil {
+				t.Fatalf("CreateRotatingWriter: %v", err)
+			}
+
+			// 9 frames fill 5 files of 2 frames, of which the last 3 are kept
+			frames := testFrames(9)
+			for _, frame := range frames {
+				if err := ring.WriteFrame(frame); err != nil {
+					t.Fatalf("WriteFrame(%d): %v", frame.ID, err)
+				}
+			}
+			if err := ring.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			want := []string{
+				"ring_00003_20250101120004." + format,
+				"ring_00004_20250101120006." + format,
+				"ring_00005_20250101120008." + format,
+			}
+			files := ring.Files()
+			if len(files) != len(want) {
+				t.Fatalf("Files() = %v, want %v", files, want)
+			}
+			for i := range want {
+				if files[i] != want[i] {
+					t.Fatalf("Files() = %v, want %v", files, want)
+				}
+			}
+			if names := ringFiles(t, dir); len(names) != len(want) || names[0] != want[0] || names[2] != want[2] {
+				t.Fatalf("output directory holds %v, want %v", names, want)
+			}
+			if ring.Frames() != len(frames) || ring.Filename() != "" {
+				t.Fatalf("ring wrote %d frames, current file %q", ring.Frames(), ring.Filename())
+			}
+
+			if format != RingFormatGcap {
+				return
+			}
+			reader, err := sm.OpenCapture(want[0])
+			if err != nil {
+				t.Fatalf("OpenCapture: %v", err)
+			}
+			defer reader.Close()
+			if reader.Len() != 2 {
+				t.Fatalf("%s holds %d frames, want 2", want[0], reader.Len())
+			}
+			got, err := reader.Next()
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			checkFrame(t, got, frames[4])
+		})
+	}
+}
+
+func TestRotatingWriterKeepsAllFiles(t *testing.T) {
+	dir := t.TempDir()
+	sm, err := NewStorageManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := sm.CreateRotatingWriter(RotationPolicy{Prefix: "office", MaxFrames: 2}, SaveMetadata{})
+	if err != nil {
+		t.Fatalf("CreateRotatingWriter: %v", err)
+	}
+	for _, frame := range testFrames(7) {
+		if err := ring.WriteFrame(frame); err != nil {
+			t.Fatalf("WriteFrame(%d): %v", frame.ID, err)
+		}
+	}
+	ring.Close()
+
+	if files := ring.Files(); len(files) != 4 || files[0] != "office_00001_20250101120000.gcap" {
+		t.Fatalf("Files() = %v", files)
+	}
+	if names := ringFiles(t, dir); len(names) != 4 {
+		t.Fatalf("output directory holds %v", names)
+	}
+}
+
+func TestRotatingWriterLimits(t *testing.T) {
+	frames := testFrames(6)
+	for _, test := range []struct {
+		name   string
+		policy RotationPolicy
+		files  int
+	}{
+		// Frames are one second apart
+		{"duration", RotationPolicy{MaxDuration: 2 * time.Second}, 3},
+		{"size", RotationPolicy{MaxBytes: 1, Format: RingFormatPcap}, 6},
+		{"first limit", RotationPolicy{MaxFrames: 4, MaxDuration: 3 * time.Second}, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sm, err := NewStorageManager(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ring, err := sm.CreateRotatingWriter(test.policy, SaveMetadata{})
+			if err != nil {
+				t.Fatalf("CreateRotatingWriter: %v", err)
+			}
+			for _, frame := range frames {
+				if err := ring.WriteFrame(frame); err != nil {
+					t.Fatalf("WriteFrame(%d): %v", frame.ID, err)
+				}
+			}
+			ring.Close()
+			if files := ring.Files(); len(files) != test.files {
+				t.Fatalf("Files() = %v, want %d files", files, test.files)
+			}
+		})
+	}
+}
+
+func TestRotatingWriterAcrossSessions(t *testing.T) {
+	dir := t.TempDir()
+	sm, err := NewStorageManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := sm.CreateRotatingWriter(RotationPolicy{MaxFrames: 10, MaxFiles: 2}, SaveMetadata{})
+	if err != nil {
+		t.Fatalf("CreateRotatingWriter: %v", err)
+	}
+
+	// Every capture session closes the ring, so the next one starts a new file
+	// and the oldest session is pruned
+	frames := testFrames(3)
+	for _, frame := range frames {
+		if err := ring.WriteFrame(frame); err != nil {
+			t.Fatalf("WriteFrame(%d): %v", frame.ID, err)
+		}
+		if err := ring.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+
+	files := ring.Files()
+	if len(files) != 2 || files[0] != "ring_00002_20250101120001.gcap" {
+		t.Fatalf("Files() = %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ring_00001_20250101120000.gcap")); !os.IsNotExist(err) {
+		t.Fatalf("first file of the ring was not deleted: %v", err)
+	}
+}
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/gob"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -23,6 +26,16 @@ type SaveMetadata struct {
 	EndTime     time.Time `json:"end_time"`
 	FrameCount  int       `json:"frame_count"`
 	Description string    `json:"description"`
+	Version     int       `json:"version"` // .gcap format version
+}
+
+// registerGobTypes registers the concrete types stored in the interface
+// fields of a frame so they can be gob-encoded
+func registerGobTypes() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(map[string]bool{})
+	gob.Register(&models.WMMParameterSet{})
 }
 
 // NewStorageManager creates a new storage manager
@@ -71,101 +84,75 @@ func (sm *StorageManager) SaveFrames(frames []*models.Frame, metadata *SaveMetad
 		return fmt.Errorf("no frames to save")
 	}
 
-	// Generate a filename if not provided
-	filename := metadata.Filename
-	if filename == "" {
-		timestamp := time.Now().Format("20060102_150405")
-		filename = fmt.Sprintf("capture_%s.gcap", timestamp)
-	}
-
-	// Ensure the filename has the correct extension
-	if filepath.Ext(filename) != ".gcap" {
-		filename += ".gcap"
-	}
-
-	// Create the output file
-	filePath := filepath.Join(sm.outputDir, filename)
-	file, err := os.Create(filePath)
+	writer, err := sm.CreateCapture(metadata)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %v", err)
+		return err
 	}
-	defer file.Close()
-
-	// Create a gob encoder for serializing the frames
-	encoder := gob.NewEncoder(file)
-
-	// Register any complex types that might be in the frame
-	gob.Register(map[string]interface{}{})
-	gob.Register([]interface{}{})
-
-	// First write the metadata
-	if metadata.StartTime.IsZero() && len(frames) > 0 {
-		metadata.StartTime = frames[0].Timestamp
-	}
-	if metadata.EndTime.IsZero() && len(frames) > 0 {
-		metadata.EndTime = frames[len(frames)-1].Timestamp
-	}
-	if metadata.FrameCount == 0 {
-		metadata.FrameCount = len(frames)
+	for _, frame := range frames {
+		if err := writer.WriteFrame(frame); err != nil {
+			writer.Close()
+			return err
+		}
 	}
-	metadata.Filename = filename
 
-	if err := encoder.Encode(metadata); err != nil {
-		return fmt.Errorf("failed to encode metadata: %v", err)
-	}
+	return writer.Close()
+}
 
-	// Create a serializable copy of the frames without OriginalPacket
-	serializableFrames := make([]*models.Frame, len(frames))
-	for i, frame := range frames {
-		// Create a copy of the frame without OriginalPacket
-		serializableFrame := *frame
-		serializableFrame.OriginalPacket = nil
-		serializableFrames[i] = &serializableFrame
+// SaveFile writes exported data, such as a reassembled stream, to a file in the
+// output directory and returns the name of the file
+func (sm *StorageManager) SaveFile(filename string, data []byte) (string, error) {
+	if filename == "" {
+		return "", fmt.Errorf("no filename given")
 	}
+	filename = filepath.Base(filename)
 
-	// Then write the frames
-	if err := encoder.Encode(serializableFrames); err != nil {
-		return fmt.Errorf("failed to encode frames: %v", err)
+	if err := os.WriteFile(filepath.Join(sm.outputDir, filename), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %v", err)
 	}
 
-	return nil
+	return filename, nil
 }
 
-// LoadFrames loads frames from a file
-func (sm *StorageManager) LoadFrames(filename string) ([]*models.Frame, *SaveMetadata, error) {
-	// Check if the file exists
-	filePath := filepath.Join(sm.outputDir, filename)
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, nil, fmt.Errorf("file %s does not exist", filename)
+// SaveCSV writes a table, such as conversation statistics, as a CSV file in the
+// output directory and returns the name of the file
+func (sm *StorageManager) SaveCSV(filename string, header []string, rows [][]string) (string, error) {
+	if filepath.Ext(filename) != ".csv" {
+		filename += ".csv"
 	}
 
-	// Open the file
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open file: %v", err)
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return "", fmt.Errorf("failed to encode CSV header: %v", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return "", fmt.Errorf("failed to encode CSV rows: %v", err)
 	}
-	defer file.Close()
-
-	// Create a gob decoder for deserializing the frames
-	decoder := gob.NewDecoder(file)
 
-	// Register any complex types that might be in the frame
-	gob.Register(map[string]interface{}{})
-	gob.Register([]interface{}{})
+	return sm.SaveFile(filename, buf.Bytes())
+}
 
-	// First read the metadata
-	var metadata SaveMetadata
-	if err := decoder.Decode(&metadata); err != nil {
-		return nil, nil, fmt.Errorf("failed to decode metadata: %v", err)
+// LoadFrames loads every frame of a file
+func (sm *StorageManager) LoadFrames(filename string) ([]*models.Frame, *SaveMetadata, error) {
+	reader, err := sm.OpenCapture(filename)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer reader.Close()
 
-	// Then read the frames
-	var frames []*models.Frame
-	if err := decoder.Decode(&frames); err != nil {
-		return nil, nil, fmt.Errorf("failed to decode frames: %v", err)
+	frames := make([]*models.Frame, 0, reader.Len())
+	for {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		frames = append(frames, frame)
 	}
 
-	return frames, &metadata, nil
+	return frames, reader.Metadata(), nil
 }
 
 // ListSavedCaptures returns a list of all saved captures
@@ -183,29 +170,14 @@ func (sm *StorageManager) ListSavedCaptures() ([]*SaveMetadata, error) {
 			continue
 		}
 
-		// Open the file
-		filePath := filepath.Join(sm.outputDir, file.Name())
-		file, err := os.Open(filePath)
+		// Read the metadata without decoding the frames
+		registerGobTypes()
+		metadata, err := readCaptureMetadata(filepath.Join(sm.outputDir, file.Name()))
 		if err != nil {
-			continue // Skip files we can't open
-		}
-
-		// Create a gob decoder for deserializing the metadata
-		decoder := gob.NewDecoder(file)
-
-		// Register any complex types that might be in the frame
-		gob.Register(map[string]interface{}{})
-		gob.Register([]interface{}{})
-
-		// Read the metadata
-		var metadata SaveMetadata
-		if err := decoder.Decode(&metadata); err != nil {
-			file.Close()
 			continue // Skip files with invalid metadata
 		}
 
-		file.Close()
-		captures = append(captures, &metadata)
+		captures = append(captures, metadata)
 	}
 
 	return captures, nil
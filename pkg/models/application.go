@@ -0,0 +1,90 @@
+package models
+
+// DHCPOption contains a single DHCP or DHCPv6 option
+type DHCPOption struct {
+	Code uint16
+	Name string
+	Data []byte
+}
+
+// DHCPInfo contains a decoded DHCP (version 4) or DHCPv6 (version 6) message
+type DHCPInfo struct {
+	Version       int
+	MessageType   string
+	TransactionID uint32
+	ClientMAC     string
+
+	// DHCPv4 header addresses
+	ClientIP string // ciaddr
+	YourIP   string // yiaddr
+	ServerIP string // siaddr
+	RelayIP  string // giaddr
+
+	// Decoded options
+	RequestedIP      string
+	Hostname         string
+	VendorClass      string
+	ServerIdentifier string // Server IP for DHCPv4, server DUID (hex) for DHCPv6
+	ClientIdentifier string // Client DUID (hex) for DHCPv6
+	LeaseTime        uint32 // Seconds
+	SubnetMask       string
+	Routers          []string
+	DNSServers       []string
+	CircuitID        string // Option 82 sub-option 1
+	RemoteID         string // Option 82 sub-option 2
+	Options          []DHCPOption
+}
+
+// IsServerMessage reports whether the message is sent by a DHCP server
+func (d *DHCPInfo) IsServerMessage() bool {
+	switch d.MessageType {
+	case "Offer", "Ack", "Nak", "Advertise", "Reply":
+		return true
+	default:
+		return false
+	}
+}
+
+// DNSQuestion is an entry of the question section of a DNS message
+type DNSQuestion struct {
+	Name  string
+	Type  string
+	Class string
+}
+
+// DNSRecord is a resource record of a DNS message
+type DNSRecord struct {
+	Name  string
+	Type  string
+	Class string
+	TTL   uint32
+	Data  string // Decoded RDATA in presentation format
+}
+
+// EDNSInfo contains the EDNS(0) parameters carried in an OPT record
+type EDNSInfo struct {
+	UDPSize       uint16
+	ExtendedRCode uint8
+	Version       uint8
+	DNSSECOK      bool
+	Options       []string
+}
+
+// DNSInfo contains a decoded DNS, mDNS or LLMNR message
+type DNSInfo struct {
+	Protocol           string // "DNS", "mDNS" or "LLMNR"
+	ID                 uint16
+	Response           bool
+	OpCode             string
+	Authoritative      bool
+	Truncated          bool
+	RecursionDesired   bool
+	RecursionAvailable bool
+	ResponseCode       uint8
+	ResponseCodeName   string
+	Questions          []DNSQuestion
+	Answers            []DNSRecord
+	Authorities        []DNSRecord
+	Additionals        []DNSRecord
+	EDNS               *EDNSInfo
+}
@@ -0,0 +1,30 @@
+package models
+
+// PoEInfo contains the Power over Ethernet parameters advertised by a neighbor
+type PoEInfo struct {
+	PowerType      string
+	PowerSource    string
+	PowerPriority  string
+	Class          uint8
+	RequestedWatts float64
+	AllocatedWatts float64
+}
+
+// DiscoveryInfo contains an LLDP or CDP advertisement from a neighboring device
+type DiscoveryInfo struct {
+	Protocol            string // "LLDP" or "CDP"
+	ChassisID           string
+	ChassisIDType       string
+	PortID              string
+	PortIDType          string
+	PortDescription     string
+	SystemName          string
+	SystemDescription   string
+	Platform            string
+	ManagementAddresses []string
+	VLAN                uint16 // Port VLAN ID (LLDP) or native VLAN (CDP), 0 when not advertised
+	Capabilities        []string
+	EnabledCapabilities []string
+	PoE                 *PoEInfo
+	TTL                 uint16 // Seconds
+}
@@ -0,0 +1,75 @@
+package models
+
+// VLANTag is an IEEE 802.1Q (C-tag) or 802.1ad (S-tag) VLAN tag
+type VLANTag struct {
+	TPID     uint16 // Tag protocol identifier: 0x8100 C-tag, 0x88A8 or 0x9100 S-tag
+	Priority uint8  // Priority code point (802.1p)
+	DEI      bool   // Drop eligible indicator
+	VID      uint16
+}
+
+// IsServiceTag reports whether the tag is a provider (S-tag) tag
+func (t VLANTag) IsServiceTag() bool {
+	return t.TPID == 0x88A8 || t.TPID == 0x9100
+}
+
+// Kind returns "S-tag" or "C-tag"
+func (t VLANTag) Kind() string {
+	if t.IsServiceTag() {
+		return "S-tag"
+	}
+	return "C-tag"
+}
+
+// MPLSLabel is an entry of an MPLS label stack
+type MPLSLabel struct {
+	Label         uint32
+	TrafficClass  uint8
+	BottomOfStack bool
+	TTL           uint8
+}
+
+// PPPoETag is a tag of a PPPoE discovery packet
+type PPPoETag struct {
+	Type  uint16
+	Name  string
+	Value string
+}
+
+// PPPOption is a configuration option of an LCP or IPCP packet
+type PPPOption struct {
+	Type  uint8
+	Name  string
+	Value string
+}
+
+// PPPControlInfo contains an LCP or IPCP negotiation packet
+type PPPControlInfo struct {
+	Protocol   string // "LCP" or "IPCP"
+	Code       uint8
+	CodeName   string
+	Identifier uint8
+	Options    []PPPOption
+}
+
+// PPPoEInfo contains a PPPoE discovery or session header and its PPP payload
+type PPPoEInfo struct {
+	Version         uint8
+	Type            uint8
+	Code            uint8
+	CodeName        string // PADI, PADO, PADR, PADS, PADT or Session
+	SessionID       uint16
+	Length          uint16
+	Tags            []PPPoETag // Discovery stage only
+	PPPProtocol     uint16     // Session stage only
+	PPPProtocolName string
+	Control         *PPPControlInfo // LCP or IPCP negotiation, if any
+}
+
+// VLANID returns the innermost (customer) VLAN ID of the frame, 0 when untagged
+func (f *Frame) VLANID() uint16 {
+	if len(f.VLANTags) == 0 {
+		return 0
+	}
+	return f.VLANTags[len(f.VLANTags)-1].VID
+}
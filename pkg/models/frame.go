@@ -1,9 +1,12 @@
 package models
 
 import (
+	"bytes"
+	"strings"
 	"time"
 
 	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
 )
 
 // FrameType represents the type of network frame
@@ -35,21 +38,52 @@ type QoSInfo struct {
 	Details     map[string]interface{}
 }
 
+// RadioTapInfo contains the radio metadata reported by a radiotap header
+type RadioTapInfo struct {
+	Length        int     // Length of the radiotap header in bytes
+	Flags         uint8   // Raw radiotap flags field
+	Rate          float64 // Data rate in Mbps (0 when unknown)
+	MCS           int     // HT MCS index (-1 when not present)
+	ChannelFreq   uint16  // Channel frequency in MHz
+	Signal        int8    // Antenna signal in dBm
+	ShortPreamble bool
+	FCSIncluded   bool // The 802.11 frame ends with a 4-byte FCS
+	BadFCS        bool // The driver reported a failed FCS check
+}
+
+// FCSInfo contains the frame check sequence found at the end of a frame
+type FCSInfo struct {
+	Value    uint32 // FCS carried by the frame
+	Computed uint32 // CRC32 computed over the frame
+	Valid    bool
+	Length   int // Length of the link layer frame including the FCS
+}
+
 // Frame represents a network frame with all its information
 type Frame struct {
 	ID              int64
 	Timestamp       time.Time
 	FrameType       FrameType
 	RawData         []byte
-	Length          int
+	Length          int             // Captured length, the length of RawData
+	OriginalLength  int             // Length of the packet on the wire, 0 for frames saved without it
+	LinkType        layers.LinkType // Link type of the capture the frame was read from
 	SourceMAC       string
 	DestinationMAC  string
+	FCS             *FCSInfo // Trailing frame check sequence, nil when the capture does not include it
 	
-	// For 802.3 Ethernet frames
-	EtherType       uint16
-	VLANInfo        interface{}
+	// For 802.3 Ethernet frames (EtherType is also set from the LLC/SNAP header of 802.11 data frames)
+	EtherType       uint16      // EtherType of the payload, announced by the innermost VLAN tag in tagged frames
+	OuterEtherType  uint16      // EtherType of the Ethernet header itself, the TPID of the outermost tag in tagged frames
+	VLANTags        []VLANTag   // 802.1Q/802.1ad tag stack, outermost first
+	MPLSLabels      []MPLSLabel // MPLS label stack, top first
+	PPPoE           *PPPoEInfo
+	Discovery       *DiscoveryInfo // LLDP or CDP advertisement
+	STP             *STPInfo       // Spanning tree BPDU
+	NetworkOffset   int // Offset of the network layer header within RawData, 0 when unknown
 	
 	// For 802.11 WLAN frames
+	RadioTap        *RadioTapInfo
 	FrameControl    interface{}
 	Duration        uint16
 	SequenceControl uint16
@@ -58,6 +92,24 @@ type Frame struct {
 	Address3        string // Usually BSSID
 	Address4        string // Used in ad-hoc mode
 	
+	// Network and transport layers
+	ARP             *ARPInfo
+	IPv4            *IPv4Info
+	IPv6            *IPv6Info
+	TCP             *TCPInfo
+	UDP             *UDPInfo
+	ICMP            *ICMPInfo
+	ApplicationOffset int // Offset of the transport payload within RawData, 0 when unknown
+
+	// Tunnel encapsulation
+	Tunnel          *TunnelInfo
+	Inner           *Frame // Decapsulated inner frame, its RawData is a slice of the outer RawData
+
+	// Application layer
+	DHCP            *DHCPInfo
+	DNS             *DNSInfo
+	TLS             *TLSInfo
+
 	// Security and QoS info
 	Security        *SecurityInfo
 	QoS             *QoSInfo
@@ -78,4 +130,67 @@ type FrameFilter struct {
 	BSSID           string
 	EncryptionTypes []string
 	ContainsBytes   []byte
-} 
\ No newline at end of file
+}
+
+// Matches reports whether the frame, or any frame encapsulated in it, satisfies every criterion of the filter
+func (ff *FrameFilter) Matches(frame *Frame) bool {
+	for _, f := range frame.Frames() {
+		if ff.matchesFrame(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFrame checks the filter criteria against a single frame
+func (ff *FrameFilter) matchesFrame(frame *Frame) bool {
+	if len(ff.FrameTypes) > 0 {
+		found := false
+		for _, frameType := range ff.FrameTypes {
+			if frame.FrameType == frameType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if ff.SourceMAC != "" && !strings.EqualFold(ff.SourceMAC, frame.SourceMAC) {
+		return false
+	}
+	if ff.DestinationMAC != "" && !strings.EqualFold(ff.DestinationMAC, frame.DestinationMAC) {
+		return false
+	}
+	if ff.BSSID != "" && !strings.EqualFold(ff.BSSID, frame.BSSID()) {
+		return false
+	}
+
+	if len(ff.EncryptionTypes) > 0 {
+		if frame.Security == nil {
+			return false
+		}
+		found := false
+		for _, encryption := range ff.EncryptionTypes {
+			if strings.EqualFold(encryption, frame.Security.EncryptionType) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(ff.ContainsBytes) > 0 && !bytes.Contains(frame.RawData, ff.ContainsBytes) {
+		return false
+	}
+
+	return true
+} 
+
+// FrameMatcher selects frames, such as a FrameFilter or a compiled display filter
+type FrameMatcher interface {
+	Matches(frame *Frame) bool
+}
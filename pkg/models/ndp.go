@@ -0,0 +1,71 @@
+package models
+
+import "fmt"
+
+// ICMPv6 Neighbor Discovery message types (RFC 4861)
+const (
+	NDRouterSolicitation    uint8 = 133
+	NDRouterAdvertisement   uint8 = 134
+	NDNeighborSolicitation  uint8 = 135
+	NDNeighborAdvertisement uint8 = 136
+	NDRedirect              uint8 = 137
+)
+
+// NDPrefix is a Prefix Information option of a Router Advertisement
+type NDPrefix struct {
+	Prefix            string // Address and length, e.g. 2001:db8::/64
+	OnLink            bool
+	Autonomous        bool   // Usable for stateless address autoconfiguration
+	ValidLifetime     uint32 // Seconds, 0xffffffff is infinite
+	PreferredLifetime uint32
+}
+
+// NDInfo contains an ICMPv6 Neighbor Discovery message and its options. Only
+// the fields of its message type and the options it carried are set.
+type NDInfo struct {
+	TargetAddress      string // Neighbor Solicitation and Advertisement, Redirect
+	DestinationAddress string // Redirect
+
+	// Router Advertisement
+	CurHopLimit      uint8
+	Managed          bool // Addresses are assigned through DHCPv6
+	OtherConfig      bool // Other configuration is obtained through DHCPv6
+	RouterPreference string
+	RouterLifetime   uint16 // Seconds, 0 when the router is not a default router
+	ReachableTime    uint32 // Milliseconds
+	RetransTimer     uint32 // Milliseconds
+
+	// Neighbor Advertisement
+	Router    bool
+	Solicited bool
+	Override  bool
+
+	// Options
+	SourceLinkAddress string
+	TargetLinkAddress string
+	MTU               uint32
+	Prefixes          []NDPrefix
+	RDNSS             []string // Recursive DNS servers (RFC 8106)
+	RDNSSLifetime     uint32
+}
+
+// IsDADProbe reports whether a Neighbor Solicitation is a duplicate address
+// detection probe, sent from the unspecified address
+func (f *Frame) IsDADProbe() bool {
+	return f.ICMP != nil && f.ICMP.ND != nil && f.ICMP.Type == NDNeighborSolicitation && f.SourceIP() == "::"
+}
+
+// NDRouterPreferenceName returns the name of the default router preference of a
+// Router Advertisement (RFC 4191)
+func NDRouterPreferenceName(flags uint8) string {
+	switch (flags >> 3) & 0x3 {
+	case 0:
+		return "Medium"
+	case 1:
+		return "High"
+	case 3:
+		return "Low"
+	default:
+		return fmt.Sprintf("Reserved (%d)", (flags>>3)&0x3)
+	}
+}
@@ -0,0 +1,254 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IP protocol numbers used across the frame model
+const (
+	IPProtocolICMPv4 = 1
+	IPProtocolTCP    = 6
+	IPProtocolUDP    = 17
+	IPProtocolICMPv6 = 58
+)
+
+// IPOption contains a single IPv4 header option
+type IPOption struct {
+	Type   uint8
+	Length uint8
+	Data   []byte
+}
+
+// IPv4Info contains the decoded IPv4 header
+type IPv4Info struct {
+	IHL            uint8 // Header length in 32-bit words
+	DSCP           uint8
+	ECN            uint8
+	TotalLength    uint16
+	ID             uint16
+	DontFragment   bool
+	MoreFragments  bool
+	FragmentOffset uint16
+	TTL            uint8
+	Protocol       uint8
+	Checksum       uint16
+	SourceIP       string
+	DestinationIP  string
+	Options        []IPOption
+}
+
+// IPv6ExtensionHeader contains a decoded IPv6 extension header
+type IPv6ExtensionHeader struct {
+	Name       string // Hop-by-Hop, Routing, Fragment, Destination
+	NextHeader uint8
+	Length     int // Header length in bytes
+
+	// Routing header fields
+	RoutingType  uint8
+	SegmentsLeft uint8
+
+	// Fragment header fields
+	FragmentOffset uint16
+	MoreFragments  bool
+	Identification uint32
+}
+
+// IPv6Info contains the decoded IPv6 header
+type IPv6Info struct {
+	TrafficClass     uint8
+	DSCP             uint8
+	ECN              uint8
+	FlowLabel        uint32
+	PayloadLength    uint16
+	NextHeader       uint8 // Next header of the fixed header
+	Protocol         uint8 // Upper layer protocol after all extension headers
+	HopLimit         uint8
+	SourceIP         string
+	DestinationIP    string
+	ExtensionHeaders []IPv6ExtensionHeader
+}
+
+// TCPFlags contains the TCP control bits
+type TCPFlags struct {
+	FIN, SYN, RST, PSH, ACK, URG, ECE, CWR, NS bool
+}
+
+// String returns the set flags as a comma separated list
+func (f TCPFlags) String() string {
+	var flags []string
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{f.SYN, "SYN"}, {f.FIN, "FIN"}, {f.RST, "RST"}, {f.PSH, "PSH"}, {f.ACK, "ACK"},
+		{f.URG, "URG"}, {f.ECE, "ECE"}, {f.CWR, "CWR"}, {f.NS, "NS"},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	return strings.Join(flags, ", ")
+}
+
+// TCPOption contains a single TCP header option
+type TCPOption struct {
+	Kind uint8
+	Name string
+	Data []byte
+}
+
+// TCPInfo contains the decoded TCP header
+type TCPInfo struct {
+	SourcePort      uint16
+	DestinationPort uint16
+	Seq             uint32
+	Ack             uint32
+	DataOffset      uint8 // Header length in 32-bit words
+	Flags           TCPFlags
+	Window          uint16
+	Checksum        uint16
+	Urgent          uint16
+	Options         []TCPOption
+	PayloadLength   int
+}
+
+// UDPInfo contains the decoded UDP header
+type UDPInfo struct {
+	SourcePort      uint16
+	DestinationPort uint16
+	Length          uint16
+	Checksum        uint16
+	PayloadLength   int
+}
+
+// ICMPInfo contains the decoded ICMP or ICMPv6 header
+type ICMPInfo struct {
+	Version  int // 4 for ICMP, 6 for ICMPv6
+	Type     uint8
+	Code     uint8
+	TypeName string
+	Checksum uint16
+	ID       uint16 // Echo request/reply identifier
+	Seq      uint16 // Echo request/reply sequence number
+	ND       *NDInfo
+}
+
+// SourceIP returns the source IP address of the frame, if any
+func (f *Frame) SourceIP() string {
+	switch {
+	case f.IPv4 != nil:
+		return f.IPv4.SourceIP
+	case f.IPv6 != nil:
+		return f.IPv6.SourceIP
+	default:
+		return ""
+	}
+}
+
+// DestinationIP returns the destination IP address of the frame, if any
+func (f *Frame) DestinationIP() string {
+	switch {
+	case f.IPv4 != nil:
+		return f.IPv4.DestinationIP
+	case f.IPv6 != nil:
+		return f.IPv6.DestinationIP
+	default:
+		return ""
+	}
+}
+
+// Ports returns the transport source and destination ports, if any
+func (f *Frame) Ports() (uint16, uint16, bool) {
+	switch {
+	case f.TCP != nil:
+		return f.TCP.SourcePort, f.TCP.DestinationPort, true
+	case f.UDP != nil:
+		return f.UDP.SourcePort, f.UDP.DestinationPort, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// TransportProtocol returns the name of the highest decoded network or transport protocol
+func (f *Frame) TransportProtocol() string {
+	switch {
+	case f.TCP != nil:
+		return "TCP"
+	case f.UDP != nil:
+		return "UDP"
+	case f.ICMP != nil && f.ICMP.Version == 6:
+		return "ICMPv6"
+	case f.ICMP != nil:
+		return "ICMP"
+	case f.IPv4 != nil:
+		return "IPv4"
+	case f.IPv6 != nil:
+		return "IPv6"
+	default:
+		return ""
+	}
+}
+
+// Endpoints returns the source and destination as ip:port (or ip) strings
+func (f *Frame) Endpoints() (string, string) {
+	src, dst := f.SourceIP(), f.DestinationIP()
+	if src == "" {
+		return "", ""
+	}
+
+	if srcPort, dstPort, ok := f.Ports(); ok {
+		return joinHostPort(src, srcPort), joinHostPort(dst, dstPort)
+	}
+	return src, dst
+}
+
+// ApplicationPayload returns the transport payload of the frame, if any
+func (f *Frame) ApplicationPayload() []byte {
+	var length int
+	switch {
+	case f.TCP != nil:
+		length = f.TCP.PayloadLength
+	case f.UDP != nil:
+		length = f.UDP.PayloadLength
+	default:
+		return nil
+	}
+
+	if f.ApplicationOffset <= 0 || f.ApplicationOffset+length > len(f.RawData) {
+		return nil
+	}
+	return f.RawData[f.ApplicationOffset : f.ApplicationOffset+length]
+}
+
+// joinHostPort formats an address and port, bracketing IPv6 addresses
+func joinHostPort(ip string, port uint16) string {
+	if strings.Contains(ip, ":") {
+		return fmt.Sprintf("[%s]:%d", ip, port)
+	}
+	return fmt.Sprintf("%s:%d", ip, port)
+}
+
+// ARPInfo contains a decoded ARP request or reply
+type ARPInfo struct {
+	HardwareType uint16
+	ProtocolType uint16
+	Operation    uint16 // 1 = request, 2 = reply
+	SenderMAC    string
+	SenderIP     string
+	TargetMAC    string
+	TargetIP     string
+	Gratuitous   bool // Sender announces its own binding (sender IP == target IP)
+}
+
+// OperationName returns a human readable ARP operation
+func (a *ARPInfo) OperationName() string {
+	switch a.Operation {
+	case 1:
+		return "Request"
+	case 2:
+		return "Reply"
+	default:
+		return fmt.Sprintf("Unknown (%d)", a.Operation)
+	}
+}
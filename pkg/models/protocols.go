@@ -0,0 +1,169 @@
+package models
+
+// wellKnownPorts names the application protocol usually carried on a TCP or UDP
+// port, for payloads that are not decoded
+var wellKnownPorts = map[uint16]string{
+	20:   "FTP-DATA",
+	21:   "FTP",
+	22:   "SSH",
+	23:   "Telnet",
+	25:   "SMTP",
+	80:   "HTTP",
+	110:  "POP3",
+	123:  "NTP",
+	143:  "IMAP",
+	161:  "SNMP",
+	162:  "SNMP",
+	389:  "LDAP",
+	443:  "TLS",
+	445:  "SMB",
+	514:  "Syslog",
+	587:  "SMTP",
+	636:  "TLS",
+	853:  "TLS",
+	993:  "TLS",
+	995:  "TLS",
+	1812: "RADIUS",
+	1900: "SSDP",
+	3389: "RDP",
+	5060: "SIP",
+	8080: "HTTP",
+	8443: "TLS",
+}
+
+// ProtocolStack returns the names of the protocols decoded in the frame, from
+// the link layer up, followed by the protocols of the frames it encapsulates
+// (e.g. Ethernet, IPv4, UDP, VXLAN, Ethernet, IPv4, TCP, TLS)
+func (f *Frame) ProtocolStack() []string {
+	var stack []string
+	for current := f; current != nil; current = current.Inner {
+		stack = append(stack, current.protocolLayers()...)
+	}
+	return stack
+}
+
+// protocolLayers returns the protocols decoded in a single frame, ending with its
+// tunnel header if it has one
+func (f *Frame) protocolLayers() []string {
+	var stack []string
+
+	switch f.FrameType {
+	case EthernetFrame:
+		// Packets decapsulated from IP tunnels have no link layer header
+		if f.SourceMAC != "" || f.DestinationMAC != "" {
+			stack = append(stack, "Ethernet")
+		}
+		for _, tag := range f.VLANTags {
+			if tag.IsServiceTag() {
+				stack = append(stack, "802.1ad")
+			} else {
+				stack = append(stack, "802.1Q")
+			}
+		}
+		if len(f.MPLSLabels) > 0 {
+			stack = append(stack, "MPLS")
+		}
+		if f.PPPoE != nil {
+			stack = append(stack, "PPPoE")
+			if f.PPPoE.Code == 0 {
+				stack = append(stack, "PPP")
+			}
+			if f.PPPoE.Control != nil {
+				stack = append(stack, f.PPPoE.Control.Protocol)
+			}
+		}
+		// EtherType values up to 1500 are 802.3 lengths followed by an LLC header
+		if f.EtherType <= 1500 && (f.EtherType > 0 || f.STP != nil || f.Discovery != nil) {
+			stack = append(stack, "LLC")
+		}
+	default:
+		if f.RadioTap != nil {
+			stack = append(stack, "Radiotap")
+		}
+		stack = append(stack, "802.11")
+		switch {
+		case f.FrameType == WLANManagementFrame:
+			stack = append(stack, "802.11 Management")
+		case f.FrameType == WLANDataFrame && f.EtherType != 0:
+			stack = append(stack, "LLC")
+		}
+	}
+
+	switch {
+	case f.STP != nil:
+		stack = append(stack, f.STP.VersionName)
+	case f.Discovery != nil:
+		stack = append(stack, f.Discovery.Protocol)
+	case f.EtherType == 0x888E:
+		stack = append(stack, "EAPOL")
+	case f.ARP != nil:
+		stack = append(stack, "ARP")
+	}
+
+	if f.IPv4 != nil {
+		stack = append(stack, "IPv4")
+	} else if f.IPv6 != nil {
+		stack = append(stack, "IPv6")
+	}
+
+	switch {
+	case f.ICMP != nil && f.ICMP.Version == 6:
+		stack = append(stack, "ICMPv6")
+	case f.ICMP != nil:
+		stack = append(stack, "ICMP")
+	case f.TCP != nil:
+		stack = append(stack, "TCP")
+	case f.UDP != nil:
+		stack = append(stack, "UDP")
+	}
+
+	if f.Tunnel != nil {
+		if f.Tunnel.Type == "ERSPAN" {
+			stack = append(stack, "GRE")
+		}
+		if f.Tunnel.Type != "IP-in-IP" {
+			stack = append(stack, f.Tunnel.Type)
+		}
+		return stack
+	}
+
+	if application := f.applicationProtocol(); application != "" {
+		stack = append(stack, application)
+	}
+	return stack
+}
+
+// applicationProtocol returns the name of the decoded application protocol, or
+// guesses it from well-known ports when the transport payload is not decoded
+func (f *Frame) applicationProtocol() string {
+	switch {
+	case f.DHCP != nil && f.DHCP.Version == 6:
+		return "DHCPv6"
+	case f.DHCP != nil:
+		return "DHCP"
+	case f.DNS != nil:
+		return f.DNS.Protocol
+	case f.TLS != nil:
+		return "TLS"
+	}
+
+	var payloadLength int
+	switch {
+	case f.TCP != nil:
+		payloadLength = f.TCP.PayloadLength
+	case f.UDP != nil:
+		payloadLength = f.UDP.PayloadLength
+	}
+	if payloadLength == 0 {
+		return ""
+	}
+
+	srcPort, dstPort, _ := f.Ports()
+	if name, ok := wellKnownPorts[dstPort]; ok {
+		return name
+	}
+	if name, ok := wellKnownPorts[srcPort]; ok {
+		return name
+	}
+	return "Data"
+}
@@ -0,0 +1,64 @@
+package models
+
+import "fmt"
+
+// BridgeID identifies a spanning tree bridge
+type BridgeID struct {
+	Priority          uint16 // Bridge priority, a multiple of 4096
+	SystemIDExtension uint16 // VLAN or MST instance the ID belongs to
+	MAC               string
+}
+
+// String returns the bridge ID in the usual priority.extension/MAC notation
+func (b BridgeID) String() string {
+	return fmt.Sprintf("%d.%d/%s", b.Priority, b.SystemIDExtension, b.MAC)
+}
+
+// MSTInstance contains an MSTI configuration message of an MST BPDU
+type MSTInstance struct {
+	ID                   uint16
+	RegionalRootID       BridgeID
+	InternalRootPathCost uint32
+	BridgePriority       uint8
+	PortPriority         uint8
+	RemainingHops        uint8
+	PortRole             string
+	TopologyChange       bool
+	Learning             bool
+	Forwarding           bool
+}
+
+// MSTInfo contains the MST fields of a version 3 BPDU
+type MSTInfo struct {
+	ConfigName               string
+	Revision                 uint16
+	Digest                   string
+	CISTInternalRootPathCost uint32
+	CISTBridgeID             BridgeID
+	RemainingHops            uint8
+	Instances                []MSTInstance
+}
+
+// STPInfo contains a decoded STP, RSTP or MSTP bridge protocol data unit
+type STPInfo struct {
+	Version           uint8  // 0 STP, 2 RSTP, 3 MSTP
+	VersionName       string // "STP", "RSTP" or "MSTP"
+	BPDUType          string // "Configuration", "TCN" or "RST"
+	PVSTVLAN          uint16 // Originating VLAN of Cisco PVST+ BPDUs, 0 otherwise
+	TopologyChange    bool
+	TopologyChangeAck bool
+	Proposal          bool
+	Agreement         bool
+	Learning          bool
+	Forwarding        bool
+	PortRole          string
+	RootID            BridgeID
+	RootPathCost      uint32
+	BridgeID          BridgeID
+	PortID            uint16
+	MessageAge        float64 // Seconds
+	MaxAge            float64
+	HelloTime         float64
+	ForwardDelay      float64
+	MST               *MSTInfo
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+)
+
+// TLS record content types
+const (
+	TLSChangeCipherSpec uint8 = 20
+	TLSAlert            uint8 = 21
+	TLSHandshake        uint8 = 22
+	TLSApplicationData  uint8 = 23
+	TLSHeartbeat        uint8 = 24
+)
+
+// TLS handshake message types
+const (
+	TLSClientHello uint8 = 1
+	TLSServerHello uint8 = 2
+	TLSCertificate uint8 = 11
+)
+
+// TLSRecord is the header of a TLS record
+type TLSRecord struct {
+	ContentType uint8
+	Version     uint16 // Record layer version, 0x0303 for TLS 1.2 and 1.3
+	Length      int
+}
+
+// TLSCertificateInfo contains the fields of an X.509 certificate sent by a TLS server
+type TLSCertificateInfo struct {
+	Subject   string
+	Issuer    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	DNSNames  []string
+}
+
+// TLSHandshakeInfo contains a TLS handshake message. Only the fields of its
+// message type are set.
+type TLSHandshakeInfo struct {
+	Type   uint8
+	Length int
+
+	// ClientHello and ServerHello
+	Version             uint16   // Legacy version of the hello
+	SupportedVersions   []uint16 // ClientHello supported_versions, or the ServerHello selected version
+	CipherSuites        []uint16 // Offered by the client, or the one selected by the server
+	Extensions          []uint16 // Extension types in the order they were sent
+	SNI                 string
+	ALPN                []string // Offered by the client, or the one selected by the server
+	SupportedGroups     []uint16
+	ECPointFormats      []uint8
+	SignatureAlgorithms []uint16
+
+	// Certificate
+	Certificates []TLSCertificateInfo
+}
+
+// TLSInfo contains the TLS records that start in a TCP segment and the
+// handshake messages completely contained in them
+type TLSInfo struct {
+	Records    []TLSRecord
+	Handshakes []TLSHandshakeInfo
+}
+
+// NegotiatedVersion returns the TLS version selected by a ServerHello, or the
+// highest version offered by a ClientHello
+func (h *TLSHandshakeInfo) NegotiatedVersion() uint16 {
+	version := h.Version
+	for _, v := range h.SupportedVersions {
+		if !IsTLSGrease(v) && v > version {
+			version = v
+		}
+	}
+	return version
+}
+
+// TLSContentTypeName returns the name of a TLS record content type
+func TLSContentTypeName(contentType uint8) string {
+	switch contentType {
+	case TLSChangeCipherSpec:
+		return "Change Cipher Spec"
+	case TLSAlert:
+		return "Alert"
+	case TLSHandshake:
+		return "Handshake"
+	case TLSApplicationData:
+		return "Application Data"
+	case TLSHeartbeat:
+		return "Heartbeat"
+	default:
+		return fmt.Sprintf("Unknown (%d)", contentType)
+	}
+}
+
+// TLSHandshakeTypeName returns the name of a TLS handshake message type
+func TLSHandshakeTypeName(handshakeType uint8) string {
+	switch handshakeType {
+	case 0:
+		return "Hello Request"
+	case TLSClientHello:
+		return "Client Hello"
+	case TLSServerHello:
+		return "Server Hello"
+	case 4:
+		return "New Session Ticket"
+	case 8:
+		return "Encrypted Extensions"
+	case TLSCertificate:
+		return "Certificate"
+	case 12:
+		return "Server Key Exchange"
+	case 13:
+		return "Certificate Request"
+	case 14:
+		return "Server Hello Done"
+	case 15:
+		return "Certificate Verify"
+	case 16:
+		return "Client Key Exchange"
+	case 20:
+		return "Finished"
+	default:
+		return fmt.Sprintf("Unknown (%d)", handshakeType)
+	}
+}
+
+// TLSVersionName returns the name of a TLS protocol version
+func TLSVersionName(version uint16) string {
+	return tls.VersionName(version)
+}
+
+// TLSCipherSuiteName returns the IANA name of a cipher suite
+func TLSCipherSuiteName(suite uint16) string {
+	return tls.CipherSuiteName(suite)
+}
+
+// IsTLSGrease reports whether a cipher suite, extension, group or version is a
+// GREASE value (RFC 8701) that clients send to exercise extensibility
+func IsTLSGrease(value uint16) bool {
+	return value&0x0f0f == 0x0a0a && value>>8 == value&0xff
+}
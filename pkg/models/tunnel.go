@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TunnelInfo contains the encapsulation header that carries a frame's inner packet
+type TunnelInfo struct {
+	Type       string // GRE, ERSPAN, VXLAN, Geneve or IP-in-IP
+	Protocol   uint16 // EtherType of the inner packet (0x6558 for Ethernet)
+	Offset     int    // Offset of the inner packet within RawData, 0 when it is not decapsulated
+	VNI        uint32 // VXLAN or Geneve network identifier
+	KeyPresent bool
+	Key        uint32 // GRE key
+	SeqPresent bool
+	Seq        uint32 // GRE sequence number
+	SessionID  uint16 // ERSPAN session
+	ERSPANVer  uint8  // ERSPAN version (1, 2 or 3)
+	ERSPANVLAN uint16 // VLAN of the mirrored frame reported by ERSPAN II
+	OptionsLen int    // Length of the Geneve options in bytes
+	OAM        bool   // Geneve OAM packet
+}
+
+// Frames returns the frame followed by every frame it encapsulates, outermost first
+func (f *Frame) Frames() []*Frame {
+	var frames []*Frame
+	for current := f; current != nil; current = current.Inner {
+		frames = append(frames, current)
+	}
+	return frames
+}
+
+// Innermost returns the most deeply encapsulated frame
+func (f *Frame) Innermost() *Frame {
+	current := f
+	for current.Inner != nil {
+		current = current.Inner
+	}
+	return current
+}
+
+// String returns a short description of the tunnel header
+func (t *TunnelInfo) String() string {
+	switch t.Type {
+	case "VXLAN", "Geneve":
+		return fmt.Sprintf("%s VNI %d", t.Type, t.VNI)
+	case "GRE":
+		if t.KeyPresent {
+			return fmt.Sprintf("GRE key %d", t.Key)
+		}
+		return "GRE"
+	case "ERSPAN":
+		if t.ERSPANVer > 1 {
+			return fmt.Sprintf("ERSPAN type %s session %d", strings.Repeat("I", int(t.ERSPANVer)), t.SessionID)
+		}
+		return "ERSPAN type I"
+	default:
+		return t.Type
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+// WMM access categories, in the order used by the WMM parameter element
+const (
+	AccessCategoryBestEffort = "AC_BE"
+	AccessCategoryBackground = "AC_BK"
+	AccessCategoryVideo      = "AC_VI"
+	AccessCategoryVoice      = "AC_VO"
+)
+
+// WMMACParameters contains the EDCA parameters advertised for one access category
+type WMMACParameters struct {
+	AccessCategory string
+	AIFSN          int
+	ACM            bool // Admission control mandatory
+	ECWMin         int
+	ECWMax         int
+	TXOPLimit      int // In units of 32μs, 0 means a single frame
+}
+
+// WMMParameterSet contains the WMM parameter element advertised by an access point
+type WMMParameterSet struct {
+	QoSInfo      uint8
+	UAPSD        bool
+	ACParameters []WMMACParameters
+}
+
+// Parameters returns the advertised parameters for an access category
+func (w *WMMParameterSet) Parameters(accessCategory string) (WMMACParameters, bool) {
+	for _, params := range w.ACParameters {
+		if params.AccessCategory == accessCategory {
+			return params, true
+		}
+	}
+	return WMMACParameters{}, false
+}
+
+// BSSID returns the BSSID of a management or data frame based on its DS flags
+func (f *Frame) BSSID() string {
+	if f.FrameType == EthernetFrame || f.FrameType == WLANControlFrame {
+		return ""
+	}
+
+	frameControl, ok := f.FrameControl.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	toDS, _ := frameControl["ToDS"].(bool)
+	fromDS, _ := frameControl["FromDS"].(bool)
+
+	switch {
+	case !toDS && !fromDS:
+		return f.Address3
+	case toDS && !fromDS:
+		return f.Address1
+	case !toDS && fromDS:
+		return f.Address2
+	default:
+		return "" // WDS frames have no BSSID
+	}
+}
+
+// LinkAddresses returns the link layer source and destination of the frame.
+// For 802.11 frames these are the original source and final destination
+// (SA/DA) resolved from the DS flags, not the transmitter and receiver.
+func (f *Frame) LinkAddresses() (string, string) {
+	if f.FrameType == EthernetFrame {
+		return f.SourceMAC, f.DestinationMAC
+	}
+
+	frameControl, _ := f.FrameControl.(map[string]interface{})
+	toDS, _ := frameControl["ToDS"].(bool)
+	fromDS, _ := frameControl["FromDS"].(bool)
+
+	switch {
+	case f.FrameType == WLANControlFrame || (!toDS && !fromDS):
+		return f.Address2, f.Address1
+	case toDS && !fromDS:
+		return f.Address2, f.Address3
+	case !toDS && fromDS:
+		return f.Address3, f.Address1
+	default:
+		return f.Address4, f.Address3
+	}
+}
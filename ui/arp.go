@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// arpModel represents the ARP binding table and alerts screen
+type arpModel struct {
+	arp    *analyzer.ARPAnalyzer
+	scroll scroller
+}
+
+// newARPModel creates a new ARP screen model
+func newARPModel(arp *analyzer.ARPAnalyzer) *arpModel {
+	return &arpModel{
+		arp: arp,
+	}
+}
+
+// Init initializes the ARP screen model
+func (m *arpModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the ARP screen model
+func (m *arpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the ARP screen
+func (m *arpModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🔗 Tabla ARP y Alertas de Suplantación\n\n")
+
+	var content strings.Builder
+
+	alerts := m.arp.Alerts()
+	content.WriteString(fmt.Sprintf("Alertas (%d):\n", len(alerts)))
+	if len(alerts) == 0 {
+		content.WriteString("  Ninguna\n")
+	}
+	for _, alert := range alerts {
+		content.WriteString(fmt.Sprintf("  ⚠ [%s] #%d %s: %s\n",
+			alert.Timestamp.Format("15:04:05.000"), alert.FrameID, alert.Type, alert.Description))
+	}
+
+	gateways := m.arp.Gateways()
+	if len(gateways) > 0 {
+		ips := make([]string, 0, len(gateways))
+		for ip := range gateways {
+			ips = append(ips, ip)
+		}
+		sort.Strings(ips)
+
+		content.WriteString("\nGateways:\n")
+		for _, ip := range ips {
+			mac := gateways[ip]
+			if mac == "" {
+				mac = "(aún no visto)"
+			}
+			content.WriteString(fmt.Sprintf("  %s → %s\n", ip, mac))
+		}
+	}
+
+	bindings := m.arp.Bindings()
+	content.WriteString(fmt.Sprintf("\nAsociaciones IP → MAC (%d):\n", len(bindings)))
+	if len(bindings) > 0 {
+		content.WriteString(fmt.Sprintf("  %-15s %-17s %7s %-12s %s\n", "IP", "MAC", "Tramas", "Última vez", "MACs anteriores"))
+	}
+	for _, binding := range bindings {
+		content.WriteString(fmt.Sprintf("  %-15s %-17s %7d %-12s %s\n",
+			binding.IP,
+			binding.MAC,
+			binding.Frames,
+			binding.LastSeen.Format("15:04:05.000"),
+			strings.Join(binding.PreviousMACs, ", "),
+		))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/capture"
+)
+
+// captureFilterPreset is a common capture filter offered by the builder
+type captureFilterPreset struct {
+	name        string
+	description string
+	argument    string // Prompt for the value the preset needs, empty when it needs none
+	wlan        bool   // Only valid on 802.11 link types
+	build       func(argument string) (string, error)
+}
+
+// captureFilterPresets are the presets of the capture filter builder. The last
+// entry edits the expression directly.
+var captureFilterPresets = []captureFilterPreset{
+	{
+		name:        "Solo EAPOL",
+		description: "Autenticación 802.1X y handshake WPA",
+		build:       func(string) (string, error) { return capture.EAPOLFilter(), nil },
+	},
+	{
+		name:        "Solo tramas de gestión",
+		description: "Beacons, probes, autenticación y asociación 802.11",
+		wlan:        true,
+		build:       func(string) (string, error) { return capture.ManagementFilter(), nil },
+	},
+	{
+		name:        "BSSID",
+		description: "Tramas de una red 802.11",
+		argument:    "BSSID (ej., aa:bb:cc:dd:ee:ff)",
+		wlan:        true,
+		build:       capture.BSSIDFilter,
+	},
+	{
+		name:        "VLAN",
+		description: "Tramas Ethernet con un VLAN ID",
+		argument:    "VLAN ID (0-4095)",
+		build:       capture.VLANFilter,
+	},
+	{
+		name:        "Expresión personalizada",
+		description: "Escribir o editar la expresión BPF",
+	},
+}
+
+// Input modes of the capture filter builder
+const (
+	captureFilterBrowsing = iota
+	captureFilterArgument
+	captureFilterExpression
+)
+
+// captureFilterModel represents the capture filter builder screen. The draft
+// expression is validated against the link type of the interface as it is
+// built, and applied to the engine for the next capture session.
+type captureFilterModel struct {
+	engine   *capture.CaptureEngine
+	cursor   int
+	mode     int
+	combine  bool // The preset being built is added to the draft instead of replacing it
+	input    string
+	inputErr error
+	draft    string
+	draftErr error
+	message  string
+}
+
+// newCaptureFilterModel creates a new capture filter builder model
+func newCaptureFilterModel(engine *capture.CaptureEngine) *captureFilterModel {
+	return &captureFilterModel{
+		engine: engine,
+	}
+}
+
+// open starts the builder from the filter currently used by the engine
+func (m *captureFilterModel) open() {
+	m.mode = captureFilterBrowsing
+	m.message = ""
+	m.setDraft(m.engine.GetFilter())
+}
+
+// editing reports whether keys are being typed in a text input
+func (m *captureFilterModel) editing() bool {
+	return m.mode != captureFilterBrowsing
+}
+
+// setDraft replaces the draft expression and validates it
+func (m *captureFilterModel) setDraft(draft string) {
+	m.draft = strings.TrimSpace(draft)
+	m.draftErr = m.engine.ValidateFilter(m.draft)
+}
+
+// addPreset builds the preset under the cursor into the draft
+func (m *captureFilterModel) addPreset(argument string) error {
+	expression, err := captureFilterPresets[m.cursor].build(argument)
+	if err != nil {
+		return err
+	}
+	if m.combine {
+		expression = capture.CombineFilters(m.draft, expression)
+	}
+	m.setDraft(expression)
+	return nil
+}
+
+// Init initializes the capture filter builder model
+func (m *captureFilterModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the capture filter builder model
+func (m *captureFilterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.editing() {
+		m.updateInput(keyMsg)
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(captureFilterPresets)-1 {
+			m.cursor++
+		}
+	case "enter", "a":
+		m.message = ""
+		m.combine = keyMsg.String() == "a"
+		preset := captureFilterPresets[m.cursor]
+		switch {
+		case preset.build == nil:
+			m.mode = captureFilterExpression
+			m.input = m.draft
+		case preset.argument != "":
+			m.mode = captureFilterArgument
+			m.input = ""
+		default:
+			m.addPreset("")
+		}
+		m.inputErr = nil
+	case "c":
+		m.message = ""
+		m.setDraft("")
+	case "g":
+		if err := m.engine.SetFilter(m.draft); err != nil {
+			m.draftErr = err
+			return m, nil
+		}
+		if m.draft == "" {
+			m.message = "Filtro de captura eliminado; se capturará todo el tráfico en la próxima captura"
+		} else {
+			m.message = "Filtro de captura aplicado; se usará en la próxima captura"
+		}
+	}
+
+	return m, nil
+}
+
+// updateInput handles the keys typed in the argument or expression input
+func (m *captureFilterModel) updateInput(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if m.mode == captureFilterExpression {
+			if m.combine {
+				m.setDraft(capture.CombineFilters(m.draft, m.input))
+			} else {
+				m.setDraft(m.input)
+			}
+		} else if err := m.addPreset(m.input); err != nil {
+			m.inputErr = err
+			return
+		}
+		m.mode = captureFilterBrowsing
+	case tea.KeyEsc:
+		m.mode = captureFilterBrowsing
+	default:
+		m.input = editText(m.input, msg)
+	}
+}
+
+// View renders the capture filter builder
+func (m *captureFilterModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🧰 Filtro de Captura\n\n")
+
+	linkType, linkKnown := m.engine.LinkType()
+	if linkKnown {
+		sb.WriteString(fmt.Sprintf("Interfaz: %s (tipo de enlace: %s)\n", m.engine.GetInterfaceName(), linkType))
+	} else {
+		sb.WriteString(fmt.Sprintf("Interfaz: %s (tipo de enlace desconocido hasta iniciar la captura)\n", m.engine.GetInterfaceName()))
+	}
+	sb.WriteString(fmt.Sprintf("Filtro actual: %s\n", filterOrNone(m.engine.GetFilter())))
+	sb.WriteString(fmt.Sprintf("Borrador:      %s\n", filterOrNone(m.draft)))
+	if m.draftErr != nil {
+		sb.WriteString(fmt.Sprintf("⚠️ %v\n", m.draftErr))
+	} else if linkKnown {
+		sb.WriteString("✓ Válido para el tipo de enlace\n")
+	} else {
+		sb.WriteString("✓ Sintaxis válida, se compila para el tipo de enlace al iniciar la captura\n")
+	}
+	sb.WriteString("\n")
+
+	for i, preset := range captureFilterPresets {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		note := ""
+		if preset.wlan && linkKnown && !capture.IsWLANLinkType(linkType) {
+			note = " (requiere 802.11)"
+		}
+		sb.WriteString(fmt.Sprintf("%s %-24s %s%s\n", cursor, preset.name, preset.description, note))
+	}
+
+	switch m.mode {
+	case captureFilterArgument:
+		sb.WriteString(fmt.Sprintf("\n%s: %s█\n", captureFilterPresets[m.cursor].argument, m.input))
+	case captureFilterExpression:
+		sb.WriteString(fmt.Sprintf("\nExpresión BPF: %s█\n", m.input))
+	}
+	if m.inputErr != nil {
+		sb.WriteString(fmt.Sprintf("⚠️ %v\n", m.inputErr))
+	}
+	if m.message != "" {
+		sb.WriteString(fmt.Sprintf("\n%s\n", m.message))
+	}
+
+	if m.editing() {
+		sb.WriteString("\nEnter para confirmar, Esc para cancelar\n")
+	} else {
+		sb.WriteString("\nEnter para usar el preset, 'a' para añadirlo al borrador con \"and\", 'c' para vaciar el borrador\n")
+		sb.WriteString("'g' para aplicar el borrador a las próximas capturas, Esc para volver\n")
+	}
+
+	return sb.String()
+}
+
+// filterOrNone returns a filter expression, or a placeholder when it is empty
+func filterOrNone(filter string) string {
+	if filter == "" {
+		return "(ninguno)"
+	}
+	return filter
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/filter"
 	"github.com/julianarchila/gocapture/internal/storage"
 	"github.com/julianarchila/gocapture/pkg/models"
 )
@@ -20,6 +21,7 @@ func newMainMenuModel() *mainMenuModel {
 	return &mainMenuModel{
 		options: []string{
 			"Iniciar Captura",
+			"Filtro de Captura",
 			"Cargar Captura",
 			"Salir",
 		},
@@ -76,31 +78,130 @@ func (m *mainMenuModel) View() string {
 	return sb.String()
 }
 
-// frameListModel represents the frame list UI component
+// maxFilterHistory is the number of display filters remembered by the frame list
+const maxFilterHistory = 50
+
+// frameListModel represents the frame list UI component. A display filter
+// selects the visible frames without discarding the others.
 type frameListModel struct {
 	frames   []*models.Frame
-	cursor   int
+	visible  []*models.Frame // Frames matched by the display filter
+	cursor   int             // Index within visible
 	offset   int
 	pageSize int
+
+	// Display filter bar
+	filter       *filter.Filter
+	editing      bool
+	input        string
+	inputErr     error
+	history      []string // Applied filters, oldest first
+	historyIndex int      // Position while browsing the history, len(history) for the input
 }
 
 // newFrameListModel creates a new frame list model
 func newFrameListModel() *frameListModel {
+	emptyFilter, _ := filter.Compile("")
 	return &frameListModel{
 		frames:   make([]*models.Frame, 0),
+		visible:  make([]*models.Frame, 0),
 		cursor:   0,
 		offset:   0,
 		pageSize: 10,
+		filter:   emptyFilter,
 	}
 }
 
-// setFrames sets the frames to display in the list
+// setFrames sets the frames to display in the list, keeping the display filter
 func (m *frameListModel) setFrames(frames []*models.Frame) {
 	m.frames = frames
+	m.visible = m.visible[:0]
+	for _, frame := range frames {
+		if m.filter.Matches(frame) {
+			m.visible = append(m.visible, frame)
+		}
+	}
 	m.cursor = 0
 	m.offset = 0
 }
 
+// selected returns the frame under the cursor, if any
+func (m *frameListModel) selected() *models.Frame {
+	if m.cursor < 0 || m.cursor >= len(m.visible) {
+		return nil
+	}
+	return m.visible[m.cursor]
+}
+
+// setCursor moves the cursor to a visible frame, scrolling it into view
+func (m *frameListModel) setCursor(cursor int) {
+	if cursor >= len(m.visible) {
+		cursor = len(m.visible) - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	m.cursor = cursor
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.pageSize {
+		m.offset = m.cursor - m.pageSize + 1
+	}
+}
+
+// applyFilter compiles and applies a display filter. The cursor stays on the
+// selected frame when it still matches, or moves to the next matching frame.
+func (m *frameListModel) applyFilter(expression string) error {
+	compiled, err := filter.Compile(expression)
+	if err != nil {
+		return err
+	}
+
+	selected := m.selected()
+	m.filter = compiled
+	m.visible = make([]*models.Frame, 0, len(m.visible))
+	cursor := -1
+	for _, frame := range m.frames {
+		if !compiled.Matches(frame) {
+			continue
+		}
+		if cursor < 0 && selected != nil && frame.ID >= selected.ID {
+			cursor = len(m.visible)
+		}
+		m.visible = append(m.visible, frame)
+	}
+	if cursor < 0 {
+		cursor = len(m.visible) - 1
+	}
+
+	// Center the selected frame when it has to scroll
+	m.offset = 0
+	if cursor >= m.pageSize {
+		m.offset = cursor - m.pageSize/2
+	}
+	m.setCursor(cursor)
+
+	if !compiled.Empty() {
+		m.addHistory(compiled.String())
+	}
+	return nil
+}
+
+// addHistory remembers an applied filter, moving it to the end if it was already known
+func (m *frameListModel) addHistory(expression string) {
+	for i, previous := range m.history {
+		if previous == expression {
+			m.history = append(m.history[:i], m.history[i+1:]...)
+			break
+		}
+	}
+	m.history = append(m.history, expression)
+	if len(m.history) > maxFilterHistory {
+		m.history = m.history[len(m.history)-maxFilterHistory:]
+	}
+}
+
 // Init initializes the frame list model
 func (m *frameListModel) Init() tea.Cmd {
 	return nil
@@ -108,61 +209,125 @@ func (m *frameListModel) Init() tea.Cmd {
 
 // Update handles updates to the frame list model
 func (m *frameListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
-				if m.cursor < m.offset {
-					m.offset = m.cursor
-				}
-			}
-		case "down", "j":
-			if m.cursor < len(m.frames)-1 {
-				m.cursor++
-				if m.cursor >= m.offset+m.pageSize {
-					m.offset = m.cursor - m.pageSize + 1
-				}
-			}
-		case "pgup":
-			m.cursor -= m.pageSize
-			if m.cursor < 0 {
-				m.cursor = 0
-			}
-			m.offset = m.cursor
-		case "pgdown":
-			m.cursor += m.pageSize
-			if m.cursor >= len(m.frames) {
-				m.cursor = len(m.frames) - 1
-			}
-			if m.cursor >= m.offset+m.pageSize {
-				m.offset = m.cursor - m.pageSize + 1
-			}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.editing {
+		m.updateFilterInput(keyMsg)
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "/":
+		m.editing = true
+		m.input = m.filter.String()
+		m.inputErr = nil
+		m.historyIndex = len(m.history)
+	case "up", "k":
+		if m.cursor > 0 {
+			m.setCursor(m.cursor - 1)
+		}
+	case "down", "j":
+		if m.cursor < len(m.visible)-1 {
+			m.setCursor(m.cursor + 1)
+		}
+	case "pgup":
+		m.cursor -= m.pageSize
+		if m.cursor < 0 {
+			m.cursor = 0
 		}
+		m.offset = m.cursor
+	case "pgdown":
+		m.setCursor(m.cursor + m.pageSize)
 	}
 
 	return m, nil
 }
 
+// updateFilterInput handles the keys typed in the display filter bar
+func (m *frameListModel) updateFilterInput(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if err := m.applyFilter(m.input); err != nil {
+			m.inputErr = err
+			return
+		}
+		m.editing = false
+		m.inputErr = nil
+	case tea.KeyEsc:
+		m.editing = false
+		m.inputErr = nil
+	case tea.KeyUp:
+		if m.historyIndex > 0 {
+			m.historyIndex--
+			m.input = m.history[m.historyIndex]
+		}
+	case tea.KeyDown:
+		if m.historyIndex < len(m.history)-1 {
+			m.historyIndex++
+			m.input = m.history[m.historyIndex]
+		} else {
+			m.historyIndex = len(m.history)
+			m.input = ""
+		}
+	default:
+		m.input = editText(m.input, msg)
+	}
+}
+
+// editText applies a key typed in a single line text input
+func editText(input string, msg tea.KeyMsg) string {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if runes := []rune(input); len(runes) > 0 {
+			return string(runes[:len(runes)-1])
+		}
+	case tea.KeyCtrlU:
+		return ""
+	case tea.KeySpace:
+		return input + " "
+	case tea.KeyRunes:
+		return input + string(msg.Runes)
+	}
+	return input
+}
+
 // View renders the frame list
 func (m *frameListModel) View() string {
 	var sb strings.Builder
 
 	sb.WriteString("📋 Lista de Tramas\n\n")
 
-	// Show total frame count
-	sb.WriteString(fmt.Sprintf("Total de tramas: %d\n\n", len(m.frames)))
+	// Show the display filter bar and the frame counts
+	switch {
+	case m.editing:
+		sb.WriteString(fmt.Sprintf("Filtro: %s█\n", m.input))
+		if m.inputErr != nil {
+			sb.WriteString(fmt.Sprintf("⚠️ %v\n", m.inputErr))
+		}
+	case !m.filter.Empty():
+		sb.WriteString(fmt.Sprintf("Filtro: %s\n", m.filter))
+	}
+	if m.filter.Empty() {
+		sb.WriteString(fmt.Sprintf("Total de tramas: %d\n\n", len(m.frames)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Tramas: %d de %d coinciden con el filtro\n\n", len(m.visible), len(m.frames)))
+	}
 
 	// Calculate end index for pagination
 	end := m.offset + m.pageSize
-	if end > len(m.frames) {
-		end = len(m.frames)
+	if end > len(m.visible) {
+		end = len(m.visible)
+	}
+
+	if len(m.visible) == 0 {
+		sb.WriteString("Ninguna trama coincide con el filtro\n")
 	}
 
 	// Show frames
 	for i := m.offset; i < end; i++ {
-		frame := m.frames[i]
+		frame := m.visible[i]
 
 		cursor := " "
 		if i == m.cursor {
@@ -188,24 +353,37 @@ func (m *frameListModel) View() string {
 			}
 		}
 
+		// Prefer IP endpoints over MAC addresses when the network layer was decoded
+		source, destination := frame.SourceMAC, frame.DestinationMAC
+		if srcEndpoint, dstEndpoint := frame.Endpoints(); srcEndpoint != "" {
+			source = srcEndpoint
+			destination = fmt.Sprintf("%s %s", dstEndpoint, frame.TransportProtocol())
+		}
+
 		// Format the line
 		sb.WriteString(fmt.Sprintf("%s #%d [%s] %s → %s (%d bytes)\n",
 			cursor,
 			frame.ID,
 			frame.Timestamp.Format("15:04:05.000"),
-			frame.SourceMAC,
-			frame.DestinationMAC,
+			source,
+			destination,
 			frame.Length,
 		))
 		sb.WriteString(fmt.Sprintf("  %s\n", summary))
 	}
 
 	// Show pagination info
-	if len(m.frames) > m.pageSize {
-		sb.WriteString(fmt.Sprintf("\nMostrando %d-%d de %d tramas\n", m.offset+1, end, len(m.frames)))
+	if len(m.visible) > m.pageSize {
+		sb.WriteString(fmt.Sprintf("\nMostrando %d-%d de %d tramas\n", m.offset+1, end, len(m.visible)))
 	}
 
+	if m.editing {
+		sb.WriteString("\nEnter para aplicar el filtro (vacío para quitarlo), ↑/↓ para el historial, Esc para cancelar\n")
+		return sb.String()
+	}
 	sb.WriteString("\nUse las teclas de flecha para navegar, Enter para ver detalles de la trama\n")
+	sb.WriteString("Presione '/' para filtrar y 'e' para ver estadísticas\n")
+	sb.WriteString("Presione 's' para guardar la captura, 'x' para exportar las tramas mostradas a pcapng o 'X' a pcap\n")
 
 	return sb.String()
 }
@@ -312,6 +490,7 @@ func (m *frameDetailModel) View() string {
 
 	sb.WriteString("\nUse Tab para cambiar modos de vista, flechas para desplazar\n")
 	sb.WriteString("Press n/right for next frame, p/left for previous frame\n")
+	sb.WriteString("Presione 'f' para seguir el stream TCP\n")
 
 	return sb.String()
 }
@@ -342,6 +521,24 @@ func (m *frameDetailModel) renderSummaryView(sb *strings.Builder) {
 		sb.WriteString("Desconocido\n")
 	}
 
+	// Show network and transport layers
+	if frame.TransportProtocol() != "" || frame.ARP != nil {
+		sb.WriteString("\nRed y Transporte:\n")
+		renderNetworkSummary(sb, frame)
+	}
+
+	// Show application layer protocols
+	if hasApplicationLayer(frame) {
+		sb.WriteString("\nAplicación:\n")
+		renderApplicationSummary(sb, frame)
+	}
+
+	// Show tunnels and the frames they carry
+	if frame.Tunnel != nil {
+		sb.WriteString("\nTúneles:\n")
+		renderTunnelSummary(sb, frame)
+	}
+
 	// Show analysis results
 	if len(frame.AnalysisResults) > 0 {
 		sb.WriteString("\nAnálisis:\n")
@@ -362,6 +559,13 @@ func (m *frameDetailModel) renderDetailsView(sb *strings.Builder) {
 	sb.WriteString(fmt.Sprintf("  ID: %d\n", frame.ID))
 	sb.WriteString(fmt.Sprintf("  Tiempo: %s\n", frame.Timestamp.Format("2006-01-02 15:04:05.000")))
 	sb.WriteString(fmt.Sprintf("  Longitud: %d bytes\n", frame.Length))
+	if fcs := frame.FCS; fcs != nil {
+		if fcs.Valid {
+			sb.WriteString(fmt.Sprintf("  FCS: 0x%08x (correcto)\n", fcs.Value))
+		} else {
+			sb.WriteString(fmt.Sprintf("  FCS: 0x%08x (incorrecto, calculado 0x%08x)\n", fcs.Value, fcs.Computed))
+		}
+	}
 
 	// MAC addresses
 	sb.WriteString("\nDirecciones MAC:\n")
@@ -376,6 +580,16 @@ func (m *frameDetailModel) renderDetailsView(sb *strings.Builder) {
 		if frame.EtherType != 0 {
 			sb.WriteString(fmt.Sprintf("  EtherType: 0x%04x\n", frame.EtherType))
 		}
+		if frame.OuterEtherType != 0 && frame.OuterEtherType != frame.EtherType {
+			sb.WriteString(fmt.Sprintf("  EtherType Externo: 0x%04x\n", frame.OuterEtherType))
+		}
+		renderEncapsulationDetails(sb, frame)
+		if discovery := frame.Discovery; discovery != nil {
+			renderDiscoveryDetails(sb, discovery)
+		}
+		if stp := frame.STP; stp != nil {
+			renderSTPDetails(sb, stp)
+		}
 	case models.WLANManagementFrame:
 		sb.WriteString("  Tipo: Gestión WLAN\n")
 		if frame.FrameControl != nil {
@@ -393,6 +607,23 @@ func (m *frameDetailModel) renderDetailsView(sb *strings.Builder) {
 		}
 	}
 
+	// Network and transport layers
+	if frame.TransportProtocol() != "" || frame.ARP != nil {
+		sb.WriteString("\nCapas de Red y Transporte:\n")
+		renderNetworkDetails(sb, frame)
+	}
+
+	// Application layer protocols
+	if hasApplicationLayer(frame) {
+		sb.WriteString("\nCapa de Aplicación:\n")
+		renderApplicationDetails(sb, frame)
+	}
+
+	// Tunnel headers and inner frames
+	if frame.Tunnel != nil {
+		renderTunnelDetails(sb, frame)
+	}
+
 	// Analysis results
 	if len(frame.AnalysisResults) > 0 {
 		sb.WriteString("\nResultados del Análisis:\n")
@@ -402,21 +633,514 @@ func (m *frameDetailModel) renderDetailsView(sb *strings.Builder) {
 	}
 }
 
+// renderNetworkSummary renders a one line summary per network and transport layer
+func renderNetworkSummary(sb *strings.Builder, frame *models.Frame) {
+	if frame.ARP != nil {
+		sb.WriteString(fmt.Sprintf("  ARP %s: %s (%s) → %s (%s)\n",
+			frame.ARP.OperationName(), frame.ARP.SenderIP, frame.ARP.SenderMAC, frame.ARP.TargetIP, frame.ARP.TargetMAC))
+	}
+	if frame.IPv4 != nil {
+		sb.WriteString(fmt.Sprintf("  IPv4: %s → %s (TTL %d, DSCP %d)\n",
+			frame.IPv4.SourceIP, frame.IPv4.DestinationIP, frame.IPv4.TTL, frame.IPv4.DSCP))
+	}
+	if frame.IPv6 != nil {
+		sb.WriteString(fmt.Sprintf("  IPv6: %s → %s (Hop Limit %d, DSCP %d)\n",
+			frame.IPv6.SourceIP, frame.IPv6.DestinationIP, frame.IPv6.HopLimit, frame.IPv6.DSCP))
+	}
+	if frame.TCP != nil {
+		sb.WriteString(fmt.Sprintf("  TCP: %d → %d [%s] Seq=%d Ack=%d Win=%d Len=%d\n",
+			frame.TCP.SourcePort, frame.TCP.DestinationPort, frame.TCP.Flags,
+			frame.TCP.Seq, frame.TCP.Ack, frame.TCP.Window, frame.TCP.PayloadLength))
+	}
+	if frame.UDP != nil {
+		sb.WriteString(fmt.Sprintf("  UDP: %d → %d Len=%d\n",
+			frame.UDP.SourcePort, frame.UDP.DestinationPort, frame.UDP.PayloadLength))
+	}
+	if frame.ICMP != nil {
+		sb.WriteString(fmt.Sprintf("  ICMPv%d: %s\n", frame.ICMP.Version, frame.ICMP.TypeName))
+	}
+}
+
+// renderNetworkDetails renders every decoded field of the network and transport layers
+func renderNetworkDetails(sb *strings.Builder, frame *models.Frame) {
+	if arp := frame.ARP; arp != nil {
+		sb.WriteString("  ARP:\n")
+		sb.WriteString(fmt.Sprintf("    Tipo de Hardware: %d\n", arp.HardwareType))
+		sb.WriteString(fmt.Sprintf("    Tipo de Protocolo: 0x%04x\n", arp.ProtocolType))
+		sb.WriteString(fmt.Sprintf("    Operación: %s\n", arp.OperationName()))
+		sb.WriteString(fmt.Sprintf("    MAC Emisor: %s\n", arp.SenderMAC))
+		sb.WriteString(fmt.Sprintf("    IP Emisor: %s\n", arp.SenderIP))
+		sb.WriteString(fmt.Sprintf("    MAC Destino: %s\n", arp.TargetMAC))
+		sb.WriteString(fmt.Sprintf("    IP Destino: %s\n", arp.TargetIP))
+		sb.WriteString(fmt.Sprintf("    Gratuito: %v\n", arp.Gratuitous))
+	}
+	if ip := frame.IPv4; ip != nil {
+		sb.WriteString("  IPv4:\n")
+		sb.WriteString(fmt.Sprintf("    Origen: %s\n", ip.SourceIP))
+		sb.WriteString(fmt.Sprintf("    Destino: %s\n", ip.DestinationIP))
+		sb.WriteString(fmt.Sprintf("    Longitud Cabecera: %d bytes\n", int(ip.IHL)*4))
+		sb.WriteString(fmt.Sprintf("    DSCP: %d, ECN: %d\n", ip.DSCP, ip.ECN))
+		sb.WriteString(fmt.Sprintf("    Longitud Total: %d\n", ip.TotalLength))
+		sb.WriteString(fmt.Sprintf("    Identificación: 0x%04x\n", ip.ID))
+		sb.WriteString(fmt.Sprintf("    Flags: DF=%v MF=%v, Offset de Fragmento: %d\n",
+			ip.DontFragment, ip.MoreFragments, ip.FragmentOffset))
+		sb.WriteString(fmt.Sprintf("    TTL: %d\n", ip.TTL))
+		sb.WriteString(fmt.Sprintf("    Protocolo: %d\n", ip.Protocol))
+		sb.WriteString(fmt.Sprintf("    Checksum: 0x%04x\n", ip.Checksum))
+		for _, option := range ip.Options {
+			sb.WriteString(fmt.Sprintf("    Opción: tipo %d, longitud %d, datos %x\n", option.Type, option.Length, option.Data))
+		}
+	}
+
+	if ip := frame.IPv6; ip != nil {
+		sb.WriteString("  IPv6:\n")
+		sb.WriteString(fmt.Sprintf("    Origen: %s\n", ip.SourceIP))
+		sb.WriteString(fmt.Sprintf("    Destino: %s\n", ip.DestinationIP))
+		sb.WriteString(fmt.Sprintf("    Clase de Tráfico: 0x%02x (DSCP %d, ECN %d)\n", ip.TrafficClass, ip.DSCP, ip.ECN))
+		sb.WriteString(fmt.Sprintf("    Etiqueta de Flujo: 0x%05x\n", ip.FlowLabel))
+		sb.WriteString(fmt.Sprintf("    Longitud de Carga: %d\n", ip.PayloadLength))
+		sb.WriteString(fmt.Sprintf("    Siguiente Cabecera: %d\n", ip.NextHeader))
+		sb.WriteString(fmt.Sprintf("    Límite de Saltos: %d\n", ip.HopLimit))
+		for _, ext := range ip.ExtensionHeaders {
+			sb.WriteString(fmt.Sprintf("    Cabecera de Extensión %s: %d bytes, siguiente %d", ext.Name, ext.Length, ext.NextHeader))
+			switch ext.Name {
+			case "Routing":
+				sb.WriteString(fmt.Sprintf(", tipo %d, segmentos restantes %d", ext.RoutingType, ext.SegmentsLeft))
+			case "Fragment":
+				sb.WriteString(fmt.Sprintf(", offset %d, MF=%v, ID 0x%08x", ext.FragmentOffset, ext.MoreFragments, ext.Identification))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if tcp := frame.TCP; tcp != nil {
+		sb.WriteString("  TCP:\n")
+		sb.WriteString(fmt.Sprintf("    Puerto Origen: %d\n", tcp.SourcePort))
+		sb.WriteString(fmt.Sprintf("    Puerto Destino: %d\n", tcp.DestinationPort))
+		sb.WriteString(fmt.Sprintf("    Número de Secuencia: %d\n", tcp.Seq))
+		sb.WriteString(fmt.Sprintf("    Número de Acuse: %d\n", tcp.Ack))
+		sb.WriteString(fmt.Sprintf("    Longitud Cabecera: %d bytes\n", int(tcp.DataOffset)*4))
+		sb.WriteString(fmt.Sprintf("    Flags: %s\n", tcp.Flags))
+		sb.WriteString(fmt.Sprintf("    Ventana: %d\n", tcp.Window))
+		sb.WriteString(fmt.Sprintf("    Checksum: 0x%04x\n", tcp.Checksum))
+		sb.WriteString(fmt.Sprintf("    Puntero Urgente: %d\n", tcp.Urgent))
+		for _, option := range tcp.Options {
+			sb.WriteString(fmt.Sprintf("    Opción: %s %x\n", option.Name, option.Data))
+		}
+		sb.WriteString(fmt.Sprintf("    Carga Útil: %d bytes\n", tcp.PayloadLength))
+	}
+
+	if udp := frame.UDP; udp != nil {
+		sb.WriteString("  UDP:\n")
+		sb.WriteString(fmt.Sprintf("    Puerto Origen: %d\n", udp.SourcePort))
+		sb.WriteString(fmt.Sprintf("    Puerto Destino: %d\n", udp.DestinationPort))
+		sb.WriteString(fmt.Sprintf("    Longitud: %d\n", udp.Length))
+		sb.WriteString(fmt.Sprintf("    Checksum: 0x%04x\n", udp.Checksum))
+		sb.WriteString(fmt.Sprintf("    Carga Útil: %d bytes\n", udp.PayloadLength))
+	}
+
+	if icmp := frame.ICMP; icmp != nil {
+		sb.WriteString(fmt.Sprintf("  ICMPv%d:\n", icmp.Version))
+		sb.WriteString(fmt.Sprintf("    Tipo: %d (%s)\n", icmp.Type, icmp.TypeName))
+		sb.WriteString(fmt.Sprintf("    Código: %d\n", icmp.Code))
+		sb.WriteString(fmt.Sprintf("    Checksum: 0x%04x\n", icmp.Checksum))
+		if icmp.ID != 0 || icmp.Seq != 0 {
+			sb.WriteString(fmt.Sprintf("    Identificador: %d, Secuencia: %d\n", icmp.ID, icmp.Seq))
+		}
+		if icmp.ND != nil {
+			renderNDDetails(sb, icmp.ND)
+		}
+	}
+}
+
+// renderNDDetails renders the fields and options of a Neighbor Discovery message
+func renderNDDetails(sb *strings.Builder, nd *models.NDInfo) {
+	if nd.TargetAddress != "" {
+		sb.WriteString(fmt.Sprintf("    Dirección Objetivo: %s\n", nd.TargetAddress))
+	}
+	if nd.DestinationAddress != "" {
+		sb.WriteString(fmt.Sprintf("    Dirección Destino: %s\n", nd.DestinationAddress))
+	}
+	if nd.RouterPreference != "" {
+		sb.WriteString(fmt.Sprintf("    Límite de Saltos: %d, Tiempo de Vida del Router: %ds, Preferencia: %s\n",
+			nd.CurHopLimit, nd.RouterLifetime, nd.RouterPreference))
+		sb.WriteString(fmt.Sprintf("    Managed: %v, Other: %v, Reachable: %d ms, Retrans: %d ms\n",
+			nd.Managed, nd.OtherConfig, nd.ReachableTime, nd.RetransTimer))
+	}
+	if nd.Router || nd.Solicited || nd.Override {
+		sb.WriteString(fmt.Sprintf("    Flags: Router %v, Solicited %v, Override %v\n", nd.Router, nd.Solicited, nd.Override))
+	}
+	if nd.SourceLinkAddress != "" {
+		sb.WriteString(fmt.Sprintf("    Dirección de Enlace Origen: %s\n", nd.SourceLinkAddress))
+	}
+	if nd.TargetLinkAddress != "" {
+		sb.WriteString(fmt.Sprintf("    Dirección de Enlace Objetivo: %s\n", nd.TargetLinkAddress))
+	}
+	if nd.MTU != 0 {
+		sb.WriteString(fmt.Sprintf("    MTU: %d\n", nd.MTU))
+	}
+	for _, prefix := range nd.Prefixes {
+		sb.WriteString(fmt.Sprintf("    Prefijo: %s (on-link %v, autónomo %v, válido %ds, preferido %ds)\n",
+			prefix.Prefix, prefix.OnLink, prefix.Autonomous, prefix.ValidLifetime, prefix.PreferredLifetime))
+	}
+	if len(nd.RDNSS) > 0 {
+		sb.WriteString(fmt.Sprintf("    Servidores DNS (RDNSS): %s, vida %ds\n", strings.Join(nd.RDNSS, ", "), nd.RDNSSLifetime))
+	}
+}
+
+// renderEncapsulationDetails renders the VLAN tags, MPLS labels and PPPoE header of an Ethernet frame
+func renderEncapsulationDetails(sb *strings.Builder, frame *models.Frame) {
+	for _, tag := range frame.VLANTags {
+		sb.WriteString(fmt.Sprintf("  %s (TPID 0x%04x): VID %d, PCP %d, DEI %v\n", tag.Kind(), tag.TPID, tag.VID, tag.Priority, tag.DEI))
+	}
+
+	for _, label := range frame.MPLSLabels {
+		sb.WriteString(fmt.Sprintf("  MPLS: etiqueta %d, TC %d, S %v, TTL %d\n", label.Label, label.TrafficClass, label.BottomOfStack, label.TTL))
+	}
+
+	if pppoe := frame.PPPoE; pppoe != nil {
+		sb.WriteString("  PPPoE:\n")
+		sb.WriteString(fmt.Sprintf("    Versión: %d, Tipo: %d\n", pppoe.Version, pppoe.Type))
+		sb.WriteString(fmt.Sprintf("    Código: %s\n", pppoe.CodeName))
+		sb.WriteString(fmt.Sprintf("    ID de Sesión: 0x%04x\n", pppoe.SessionID))
+		sb.WriteString(fmt.Sprintf("    Longitud: %d\n", pppoe.Length))
+		for _, tag := range pppoe.Tags {
+			sb.WriteString(fmt.Sprintf("    Tag %s: %s\n", tag.Name, tag.Value))
+		}
+		if pppoe.PPPProtocolName != "" {
+			sb.WriteString(fmt.Sprintf("    Protocolo PPP: %s (0x%04x)\n", pppoe.PPPProtocolName, pppoe.PPPProtocol))
+		}
+		if control := pppoe.Control; control != nil {
+			sb.WriteString(fmt.Sprintf("    %s: %s, identificador %d\n", control.Protocol, control.CodeName, control.Identifier))
+			for _, option := range control.Options {
+				sb.WriteString(fmt.Sprintf("      %s: %s\n", option.Name, option.Value))
+			}
+		}
+	}
+}
+
+// renderTunnelSummary renders one line per tunnel followed by the layers of the frame it carries
+func renderTunnelSummary(sb *strings.Builder, frame *models.Frame) {
+	for f := frame; f != nil && f.Tunnel != nil; f = f.Inner {
+		sb.WriteString(fmt.Sprintf("  %s\n", f.Tunnel))
+		if inner := f.Inner; inner != nil {
+			if inner.SourceMAC != "" {
+				sb.WriteString(fmt.Sprintf("  Ethernet: %s → %s\n", inner.SourceMAC, inner.DestinationMAC))
+			}
+			renderNetworkSummary(sb, inner)
+		}
+	}
+}
+
+// renderTunnelDetails renders every tunnel header and the layers of the inner frames, outermost first
+func renderTunnelDetails(sb *strings.Builder, frame *models.Frame) {
+	level := 1
+	for f := frame; f != nil && f.Tunnel != nil; f = f.Inner {
+		tunnel := f.Tunnel
+
+		sb.WriteString(fmt.Sprintf("\nTúnel %s:\n", tunnel.Type))
+		sb.WriteString(fmt.Sprintf("  Protocolo Interno: 0x%04x\n", tunnel.Protocol))
+		switch tunnel.Type {
+		case "VXLAN":
+			sb.WriteString(fmt.Sprintf("  VNI: %d\n", tunnel.VNI))
+		case "Geneve":
+			sb.WriteString(fmt.Sprintf("  VNI: %d\n", tunnel.VNI))
+			sb.WriteString(fmt.Sprintf("  Opciones: %d bytes, OAM: %v\n", tunnel.OptionsLen, tunnel.OAM))
+		case "GRE", "ERSPAN":
+			if tunnel.KeyPresent {
+				sb.WriteString(fmt.Sprintf("  Clave: %d\n", tunnel.Key))
+			}
+			if tunnel.SeqPresent {
+				sb.WriteString(fmt.Sprintf("  Secuencia: %d\n", tunnel.Seq))
+			}
+			if tunnel.Type == "ERSPAN" {
+				sb.WriteString(fmt.Sprintf("  Tipo ERSPAN: %s\n", strings.Repeat("I", int(tunnel.ERSPANVer))))
+				if tunnel.ERSPANVer > 1 {
+					sb.WriteString(fmt.Sprintf("  Sesión: %d, VLAN: %d\n", tunnel.SessionID, tunnel.ERSPANVLAN))
+				}
+			}
+		}
+
+		inner := f.Inner
+		if inner == nil {
+			sb.WriteString("  Carga no decapsulada\n")
+			break
+		}
+
+		sb.WriteString(fmt.Sprintf("\nTrama Interna (nivel %d):\n", level))
+		if inner.SourceMAC != "" {
+			sb.WriteString(fmt.Sprintf("  Origen: %s\n", inner.SourceMAC))
+			sb.WriteString(fmt.Sprintf("  Destino: %s\n", inner.DestinationMAC))
+			sb.WriteString(fmt.Sprintf("  EtherType: 0x%04x\n", inner.EtherType))
+			renderEncapsulationDetails(sb, inner)
+		}
+		renderNetworkDetails(sb, inner)
+		if hasApplicationLayer(inner) {
+			renderApplicationDetails(sb, inner)
+		}
+		level++
+	}
+}
+
+// renderDiscoveryDetails renders the fields of an LLDP or CDP advertisement
+func renderDiscoveryDetails(sb *strings.Builder, discovery *models.DiscoveryInfo) {
+	sb.WriteString(fmt.Sprintf("  %s:\n", discovery.Protocol))
+	sb.WriteString(fmt.Sprintf("    Chasis: %s (%s)\n", discovery.ChassisID, discovery.ChassisIDType))
+	sb.WriteString(fmt.Sprintf("    Puerto: %s (%s)\n", discovery.PortID, discovery.PortIDType))
+	sb.WriteString(fmt.Sprintf("    TTL: %d s\n", discovery.TTL))
+	if discovery.PortDescription != "" {
+		sb.WriteString(fmt.Sprintf("    Descripción del Puerto: %s\n", discovery.PortDescription))
+	}
+	if discovery.SystemName != "" {
+		sb.WriteString(fmt.Sprintf("    Nombre del Sistema: %s\n", discovery.SystemName))
+	}
+	if discovery.SystemDescription != "" {
+		sb.WriteString(fmt.Sprintf("    Descripción del Sistema: %s\n", discovery.SystemDescription))
+	}
+	if discovery.Platform != "" {
+		sb.WriteString(fmt.Sprintf("    Plataforma: %s\n", discovery.Platform))
+	}
+	if len(discovery.ManagementAddresses) > 0 {
+		sb.WriteString(fmt.Sprintf("    Direcciones de Gestión: %s\n", strings.Join(discovery.ManagementAddresses, ", ")))
+	}
+	if discovery.VLAN != 0 {
+		sb.WriteString(fmt.Sprintf("    VLAN: %d\n", discovery.VLAN))
+	}
+	if len(discovery.Capabilities) > 0 {
+		sb.WriteString(fmt.Sprintf("    Capacidades: %s\n", strings.Join(discovery.Capabilities, ", ")))
+		sb.WriteString(fmt.Sprintf("    Capacidades Habilitadas: %s\n", strings.Join(discovery.EnabledCapabilities, ", ")))
+	}
+	if poe := discovery.PoE; poe != nil {
+		sb.WriteString(fmt.Sprintf("    PoE: clase %d, solicitado %.1f W, asignado %.1f W\n", poe.Class, poe.RequestedWatts, poe.AllocatedWatts))
+		if poe.PowerType != "" {
+			sb.WriteString(fmt.Sprintf("    PoE Tipo: %s, Fuente: %s, Prioridad: %s\n", poe.PowerType, poe.PowerSource, poe.PowerPriority))
+		}
+	}
+}
+
+// renderSTPDetails renders the fields of a spanning tree BPDU
+func renderSTPDetails(sb *strings.Builder, stp *models.STPInfo) {
+	sb.WriteString(fmt.Sprintf("  %s BPDU (%s):\n", stp.VersionName, stp.BPDUType))
+	if stp.PVSTVLAN != 0 {
+		sb.WriteString(fmt.Sprintf("    VLAN PVST+: %d\n", stp.PVSTVLAN))
+	}
+	if stp.BPDUType == "TCN" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("    Raíz: %s\n", stp.RootID))
+	sb.WriteString(fmt.Sprintf("    Costo a la Raíz: %d\n", stp.RootPathCost))
+	sb.WriteString(fmt.Sprintf("    Puente: %s\n", stp.BridgeID))
+	sb.WriteString(fmt.Sprintf("    Puerto: 0x%04x\n", stp.PortID))
+	sb.WriteString(fmt.Sprintf("    Flags: TC=%v TCA=%v", stp.TopologyChange, stp.TopologyChangeAck))
+	if stp.BPDUType == "RST" {
+		sb.WriteString(fmt.Sprintf(" Propuesta=%v Acuerdo=%v Aprendiendo=%v Reenviando=%v, Rol: %s",
+			stp.Proposal, stp.Agreement, stp.Learning, stp.Forwarding, stp.PortRole))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("    Temporizadores: edad %.0f s, edad máx %.0f s, hello %.0f s, retardo %.0f s\n",
+		stp.MessageAge, stp.MaxAge, stp.HelloTime, stp.ForwardDelay))
+	if mst := stp.MST; mst != nil {
+		sb.WriteString(fmt.Sprintf("    Región MST: %s, revisión %d, digest %s\n", mst.ConfigName, mst.Revision, mst.Digest))
+		sb.WriteString(fmt.Sprintf("    Puente CIST: %s, costo interno %d, saltos restantes %d\n",
+			mst.CISTBridgeID, mst.CISTInternalRootPathCost, mst.RemainingHops))
+		for _, instance := range mst.Instances {
+			sb.WriteString(fmt.Sprintf("    MSTI %d: raíz regional %s, costo %d, prioridad %d, rol %s, TC=%v\n",
+				instance.ID, instance.RegionalRootID, instance.InternalRootPathCost,
+				int(instance.BridgePriority)<<8, instance.PortRole, instance.TopologyChange))
+		}
+	}
+}
+
+// hasApplicationLayer reports whether an application protocol was decoded on the frame
+func hasApplicationLayer(frame *models.Frame) bool {
+	return frame.DHCP != nil || frame.DNS != nil || frame.TLS != nil
+}
+
+// renderApplicationSummary renders a one line summary per application protocol
+func renderApplicationSummary(sb *strings.Builder, frame *models.Frame) {
+	if dhcp := frame.DHCP; dhcp != nil {
+		line := fmt.Sprintf("  DHCPv%d %s xid=0x%08x cliente %s", dhcp.Version, dhcp.MessageType, dhcp.TransactionID, dhcp.ClientMAC)
+		if ip := dhcp.YourIP; ip != "" && ip != "0.0.0.0" && ip != "<nil>" {
+			line += fmt.Sprintf(" IP %s", ip)
+		} else if dhcp.RequestedIP != "" {
+			line += fmt.Sprintf(" solicita %s", dhcp.RequestedIP)
+		}
+		sb.WriteString(line + "\n")
+	}
+	if dns := frame.DNS; dns != nil {
+		kind := "consulta"
+		if dns.Response {
+			kind = "respuesta " + dns.ResponseCodeName
+		}
+		line := fmt.Sprintf("  %s %s id=0x%04x", dns.Protocol, kind, dns.ID)
+		for _, question := range dns.Questions {
+			line += fmt.Sprintf(" %s %s", question.Type, question.Name)
+		}
+		for _, answer := range dns.Answers {
+			line += fmt.Sprintf(" → %s", answer.Data)
+		}
+		sb.WriteString(line + "\n")
+	}
+	if tls := frame.TLS; tls != nil {
+		var parts []string
+		for _, handshake := range tls.Handshakes {
+			part := models.TLSHandshakeTypeName(handshake.Type)
+			if handshake.SNI != "" {
+				part += " SNI=" + handshake.SNI
+			}
+			parts = append(parts, part)
+		}
+		if len(parts) == 0 {
+			for _, record := range tls.Records {
+				parts = append(parts, models.TLSContentTypeName(record.ContentType))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("  TLS %s\n", strings.Join(parts, ", ")))
+	}
+}
+
+// renderApplicationDetails renders every decoded field of the application protocols
+func renderApplicationDetails(sb *strings.Builder, frame *models.Frame) {
+	if dhcp := frame.DHCP; dhcp != nil {
+		sb.WriteString(fmt.Sprintf("  DHCPv%d:\n", dhcp.Version))
+		sb.WriteString(fmt.Sprintf("    Tipo de Mensaje: %s\n", dhcp.MessageType))
+		sb.WriteString(fmt.Sprintf("    ID de Transacción: 0x%08x\n", dhcp.TransactionID))
+		writeIfSet := func(label string, value string) {
+			if value != "" && value != "0.0.0.0" && value != "<nil>" {
+				sb.WriteString(fmt.Sprintf("    %s: %s\n", label, value))
+			}
+		}
+		writeIfSet("MAC Cliente", dhcp.ClientMAC)
+		writeIfSet("DUID Cliente", dhcp.ClientIdentifier)
+		writeIfSet("IP Cliente", dhcp.ClientIP)
+		writeIfSet("IP Asignada", dhcp.YourIP)
+		writeIfSet("IP Siguiente Servidor", dhcp.ServerIP)
+		writeIfSet("IP Relay", dhcp.RelayIP)
+		writeIfSet("IP Solicitada", dhcp.RequestedIP)
+		writeIfSet("Nombre de Host", dhcp.Hostname)
+		writeIfSet("Clase de Fabricante", dhcp.VendorClass)
+		writeIfSet("Identificador de Servidor", dhcp.ServerIdentifier)
+		if dhcp.LeaseTime > 0 {
+			sb.WriteString(fmt.Sprintf("    Tiempo de Concesión: %d s\n", dhcp.LeaseTime))
+		}
+		writeIfSet("Máscara de Subred", dhcp.SubnetMask)
+		writeIfSet("Routers", strings.Join(dhcp.Routers, ", "))
+		writeIfSet("Servidores DNS", strings.Join(dhcp.DNSServers, ", "))
+		writeIfSet("Opción 82 Circuit ID", dhcp.CircuitID)
+		writeIfSet("Opción 82 Remote ID", dhcp.RemoteID)
+		for _, option := range dhcp.Options {
+			sb.WriteString(fmt.Sprintf("    Opción %d (%s): %x\n", option.Code, option.Name, option.Data))
+		}
+	}
+
+	if dns := frame.DNS; dns != nil {
+		sb.WriteString(fmt.Sprintf("  %s:\n", dns.Protocol))
+		sb.WriteString(fmt.Sprintf("    ID de Transacción: 0x%04x\n", dns.ID))
+		sb.WriteString(fmt.Sprintf("    Respuesta: %v, OpCode: %s\n", dns.Response, dns.OpCode))
+		sb.WriteString(fmt.Sprintf("    Flags: AA=%v TC=%v RD=%v RA=%v\n",
+			dns.Authoritative, dns.Truncated, dns.RecursionDesired, dns.RecursionAvailable))
+		if dns.Response {
+			sb.WriteString(fmt.Sprintf("    Código de Respuesta: %s\n", dns.ResponseCodeName))
+		}
+		for _, question := range dns.Questions {
+			sb.WriteString(fmt.Sprintf("    Pregunta: %s %s %s\n", question.Name, question.Class, question.Type))
+		}
+		sections := []struct {
+			label   string
+			records []models.DNSRecord
+		}{
+			{"Respuesta", dns.Answers},
+			{"Autoridad", dns.Authorities},
+			{"Adicional", dns.Additionals},
+		}
+		for _, section := range sections {
+			for _, record := range section.records {
+				sb.WriteString(fmt.Sprintf("    %s: %s %d %s %s %s\n",
+					section.label, record.Name, record.TTL, record.Class, record.Type, record.Data))
+			}
+		}
+		if edns := dns.EDNS; edns != nil {
+			sb.WriteString(fmt.Sprintf("    EDNS: versión %d, tamaño UDP %d, DO=%v",
+				edns.Version, edns.UDPSize, edns.DNSSECOK))
+			if len(edns.Options) > 0 {
+				sb.WriteString(fmt.Sprintf(", opciones %s", strings.Join(edns.Options, ", ")))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if tls := frame.TLS; tls != nil {
+		renderTLSDetails(sb, tls)
+	}
+}
+
+// renderTLSDetails renders the TLS records of a frame and the handshake messages they contain
+func renderTLSDetails(sb *strings.Builder, tls *models.TLSInfo) {
+	sb.WriteString("  TLS:\n")
+	for _, record := range tls.Records {
+		sb.WriteString(fmt.Sprintf("    Registro: %s, %s, %d bytes\n",
+			models.TLSContentTypeName(record.ContentType), models.TLSVersionName(record.Version), record.Length))
+	}
+
+	for _, handshake := range tls.Handshakes {
+		sb.WriteString(fmt.Sprintf("    %s (%d bytes)\n", models.TLSHandshakeTypeName(handshake.Type), handshake.Length))
+		switch handshake.Type {
+		case models.TLSClientHello, models.TLSServerHello:
+			sb.WriteString(fmt.Sprintf("      Versión: %s\n", models.TLSVersionName(handshake.Version)))
+			if len(handshake.SupportedVersions) > 0 {
+				versions := make([]string, 0, len(handshake.SupportedVersions))
+				for _, version := range handshake.SupportedVersions {
+					if !models.IsTLSGrease(version) {
+						versions = append(versions, models.TLSVersionName(version))
+					}
+				}
+				sb.WriteString(fmt.Sprintf("      Versiones Soportadas: %s\n", strings.Join(versions, ", ")))
+			}
+			if handshake.SNI != "" {
+				sb.WriteString(fmt.Sprintf("      SNI: %s\n", handshake.SNI))
+			}
+			if len(handshake.ALPN) > 0 {
+				sb.WriteString(fmt.Sprintf("      ALPN: %s\n", strings.Join(handshake.ALPN, ", ")))
+			}
+			sb.WriteString(fmt.Sprintf("      Cipher Suites (%d):\n", len(handshake.CipherSuites)))
+			for _, suite := range handshake.CipherSuites {
+				if !models.IsTLSGrease(suite) {
+					sb.WriteString(fmt.Sprintf("        %s\n", models.TLSCipherSuiteName(suite)))
+				}
+			}
+		case models.TLSCertificate:
+			for i, cert := range handshake.Certificates {
+				sb.WriteString(fmt.Sprintf("      Certificado %d: %s\n", i+1, cert.Subject))
+				sb.WriteString(fmt.Sprintf("        Emisor: %s\n", cert.Issuer))
+				sb.WriteString(fmt.Sprintf("        Validez: %s - %s\n",
+					cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02")))
+				if len(cert.DNSNames) > 0 {
+					sb.WriteString(fmt.Sprintf("        Nombres DNS: %s\n", strings.Join(cert.DNSNames, ", ")))
+				}
+			}
+		}
+	}
+}
+
 // renderHexView renders the hex dump view of the frame
 func (m *frameDetailModel) renderHexView(sb *strings.Builder) {
 	frame := m.frame
 
 	sb.WriteString("Vista Hexadecimal de la Trama\n\n")
 
-	// Format the hex dump
-	for i := 0; i < len(frame.RawData); i += 16 {
+	writeHexDump(sb, frame.RawData)
+}
+
+// writeHexDump writes data as offset, hex and ASCII columns of 16 bytes
+func writeHexDump(sb *strings.Builder, data []byte) {
+	for i := 0; i < len(data); i += 16 {
 		// Print offset
 		sb.WriteString(fmt.Sprintf("%08x  ", i))
 
 		// Print hex values
 		for j := 0; j < 16; j++ {
-			if i+j < len(frame.RawData) {
-				sb.WriteString(fmt.Sprintf("%02x ", frame.RawData[i+j]))
+			if i+j < len(data) {
+				sb.WriteString(fmt.Sprintf("%02x ", data[i+j]))
 			} else {
 				sb.WriteString("   ")
 			}
@@ -428,8 +1152,8 @@ func (m *frameDetailModel) renderHexView(sb *strings.Builder) {
 		// Print ASCII representation
 		sb.WriteString(" |")
 		for j := 0; j < 16; j++ {
-			if i+j < len(frame.RawData) {
-				b := frame.RawData[i+j]
+			if i+j < len(data) {
+				b := data[i+j]
 				if b >= 32 && b <= 126 {
 					sb.WriteString(fmt.Sprintf("%c", b))
 				} else {
@@ -523,12 +1247,12 @@ func (m *savedCapturesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // loadCapture loads a captured file
 func (m *savedCapturesModel) loadCapture(filename string) tea.Cmd {
 	return func() tea.Msg {
-		frames, _, err := m.storageManager.LoadFrames(filename)
+		frames, metadata, err := m.storageManager.LoadFrames(filename)
 		if err != nil {
 			return nil
 		}
 
-		return loadFramesMsg{frames: frames}
+		return loadFramesMsg{frames: frames, interfaceName: metadata.Interface}
 	}
 }
 
@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+	"github.com/julianarchila/gocapture/internal/storage"
+)
+
+// tableOrders lists the sort orders of the conversation and endpoint screens with their labels
+var tableOrders = []struct {
+	order analyzer.TableOrder
+	label string
+}{
+	{analyzer.SortByBytes, "bytes"},
+	{analyzer.SortByFrames, "tramas"},
+	{analyzer.SortByDuration, "duración"},
+	{analyzer.SortByStart, "inicio"},
+	{analyzer.SortByAddress, "dirección"},
+}
+
+// trafficModel represents the conversations screen, or the endpoints screen when endpoints is set
+type trafficModel struct {
+	conversations  *analyzer.ConversationAnalyzer
+	storageManager *storage.StorageManager
+	endpoints      bool
+	level          int
+	order          int
+	message        string
+	scroll         scroller
+}
+
+// newConversationsModel creates a new conversations screen model
+func newConversationsModel(conversations *analyzer.ConversationAnalyzer, storageManager *storage.StorageManager) *trafficModel {
+	return &trafficModel{
+		conversations:  conversations,
+		storageManager: storageManager,
+	}
+}
+
+// newEndpointsModel creates a new endpoints screen model
+func newEndpointsModel(conversations *analyzer.ConversationAnalyzer, storageManager *storage.StorageManager) *trafficModel {
+	return &trafficModel{
+		conversations:  conversations,
+		storageManager: storageManager,
+		endpoints:      true,
+	}
+}
+
+// Init initializes the conversations screen model
+func (m *trafficModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the conversations screen model
+func (m *trafficModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "tab":
+			m.level = (m.level + 1) % len(analyzer.ConversationLevels)
+			m.message = ""
+			m.scroll = scroller{}
+		case "o":
+			m.order = (m.order + 1) % len(tableOrders)
+			m.scroll = scroller{}
+		case "s":
+			m.export()
+		default:
+			m.scroll.update(keyMsg)
+		}
+	}
+
+	return m, nil
+}
+
+// export saves the table shown on screen as a CSV file in the captures directory
+func (m *trafficModel) export() {
+	level := analyzer.ConversationLevels[m.level]
+	order := tableOrders[m.order].order
+
+	var header []string
+	var rows [][]string
+	name := "conversaciones"
+	if m.endpoints {
+		name = "endpoints"
+		header = []string{"protocolo", "direccion", "tramas_tx", "bytes_tx", "tramas_rx", "bytes_rx", "inicio", "duracion_s"}
+		for _, e := range m.conversations.Endpoints(level, order) {
+			rows = append(rows, []string{
+				e.Protocol, e.Address,
+				fmt.Sprint(e.TxFrames), fmt.Sprint(e.TxBytes),
+				fmt.Sprint(e.RxFrames), fmt.Sprint(e.RxBytes),
+				e.FirstSeen.Format(time.RFC3339Nano), fmt.Sprintf("%.6f", e.Duration().Seconds()),
+			})
+		}
+	} else {
+		header = []string{"protocolo", "direccion_a", "direccion_b", "bssid", "tramas_ab", "bytes_ab", "tramas_ba", "bytes_ba",
+			"inicio", "duracion_s", "bps_ab", "bps_ba"}
+		for _, c := range m.conversations.Conversations(level, order) {
+			rows = append(rows, []string{
+				c.Protocol, c.AddressA, c.AddressB, c.BSSID,
+				fmt.Sprint(c.FramesAB), fmt.Sprint(c.BytesAB),
+				fmt.Sprint(c.FramesBA), fmt.Sprint(c.BytesBA),
+				c.FirstSeen.Format(time.RFC3339Nano), fmt.Sprintf("%.6f", c.Duration().Seconds()),
+				fmt.Sprintf("%.0f", c.RateAB()), fmt.Sprintf("%.0f", c.RateBA()),
+			})
+		}
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.csv", name, strings.ToLower(level.String()), time.Now().Format("20060102_150405"))
+	if saved, err := m.storageManager.SaveCSV(filename, header, rows); err != nil {
+		m.message = fmt.Sprintf("Error al exportar: %v", err)
+	} else {
+		m.message = fmt.Sprintf("Tabla exportada a %s (%d filas)", saved, len(rows))
+	}
+}
+
+// View renders the conversations screen
+func (m *trafficModel) View() string {
+	var sb strings.Builder
+
+	level := analyzer.ConversationLevels[m.level]
+	order := tableOrders[m.order].order
+
+	var content strings.Builder
+	if m.endpoints {
+		sb.WriteString(fmt.Sprintf("📇 Endpoints %s\n\n", level))
+		m.renderEndpoints(&content, m.conversations.Endpoints(level, order))
+	} else {
+		sb.WriteString(fmt.Sprintf("💬 Conversaciones %s\n\n", level))
+		m.renderConversations(&content, m.conversations.Conversations(level, order))
+	}
+	sb.WriteString(fmt.Sprintf("Ordenado por: %s\n", tableOrders[m.order].label))
+	if dropped := m.conversations.Dropped(); dropped > 0 {
+		sb.WriteString(fmt.Sprintf("Tablas llenas: %d tramas sin contabilizar\n", dropped))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString(m.scroll.render(content.String()))
+	if m.message != "" {
+		sb.WriteString(fmt.Sprintf("\n%s\n", m.message))
+	}
+	sb.WriteString("\nTab: nivel L2/L3/L4, o: orden, s: exportar CSV, flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
+
+// renderConversations renders the conversation table
+func (m *trafficModel) renderConversations(sb *strings.Builder, conversations []analyzer.Conversation) {
+	if len(conversations) == 0 {
+		sb.WriteString("Sin conversaciones\n")
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("%-8s %-24s %-24s %7s %10s %7s %10s %10s %11s %11s\n",
+		"Proto", "Dirección A", "Dirección B", "Tr A→B", "Bytes A→B", "Tr B→A", "Bytes B→A", "Duración", "bps A→B", "bps B→A"))
+	for _, c := range conversations {
+		sb.WriteString(fmt.Sprintf("%-8s %-24s %-24s %7d %10d %7d %10d %10s %11.0f %11.0f\n",
+			c.Protocol,
+			truncate(c.AddressA, 24),
+			truncate(c.AddressB, 24),
+			c.FramesAB, c.BytesAB,
+			c.FramesBA, c.BytesBA,
+			c.Duration().Round(time.Millisecond),
+			c.RateAB(), c.RateBA(),
+		))
+		if c.BSSID != "" {
+			sb.WriteString(fmt.Sprintf("         BSSID %s\n", c.BSSID))
+		}
+	}
+}
+
+// renderEndpoints renders the endpoint table
+func (m *trafficModel) renderEndpoints(sb *strings.Builder, endpoints []analyzer.Endpoint) {
+	if len(endpoints) == 0 {
+		sb.WriteString("Sin endpoints\n")
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("%-8s %-40s %8s %10s %8s %10s %10s\n",
+		"Proto", "Dirección", "Tr Tx", "Bytes Tx", "Tr Rx", "Bytes Rx", "Duración"))
+	for _, e := range endpoints {
+		sb.WriteString(fmt.Sprintf("%-8s %-40s %8d %10d %8d %10d %10s\n",
+			e.Protocol,
+			truncate(e.Address, 40),
+			e.TxFrames, e.TxBytes,
+			e.RxFrames, e.RxBytes,
+			e.Duration().Round(time.Millisecond),
+		))
+	}
+}
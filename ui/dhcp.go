@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// dhcpModel represents the DHCP leases, servers and alerts screen
+type dhcpModel struct {
+	dhcp   *analyzer.DHCPAnalyzer
+	scroll scroller
+}
+
+// newDHCPModel creates a new DHCP screen model
+func newDHCPModel(dhcp *analyzer.DHCPAnalyzer) *dhcpModel {
+	return &dhcpModel{
+		dhcp: dhcp,
+	}
+}
+
+// Init initializes the DHCP screen model
+func (m *dhcpModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the DHCP screen model
+func (m *dhcpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the DHCP screen
+func (m *dhcpModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("📋 Concesiones DHCP y Servidores\n\n")
+
+	var content strings.Builder
+
+	alerts := m.dhcp.Alerts()
+	content.WriteString(fmt.Sprintf("Alertas (%d):\n", len(alerts)))
+	if len(alerts) == 0 {
+		content.WriteString("  Ninguna\n")
+	}
+	for _, alert := range alerts {
+		content.WriteString(fmt.Sprintf("  ⚠ [%s] #%d VLAN %d %s: %s\n",
+			alert.Timestamp.Format("15:04:05.000"), alert.FrameID, alert.VLAN, alert.Type, alert.Description))
+	}
+
+	servers := m.dhcp.Servers()
+	content.WriteString(fmt.Sprintf("\nServidores (%d):\n", len(servers)))
+	if len(servers) > 0 {
+		content.WriteString(fmt.Sprintf("  %-5s %-4s %-39s %-17s %6s %6s %5s\n", "VLAN", "Ver", "Servidor", "MAC", "Offers", "Acks", "Naks"))
+	}
+	for _, server := range servers {
+		content.WriteString(fmt.Sprintf("  %-5d v%-3d %-39s %-17s %6d %6d %5d\n",
+			server.VLAN, server.Version, truncate(server.ServerID, 39), server.MAC, server.Offers, server.Acks, server.Naks))
+	}
+
+	clients := m.dhcp.Clients()
+	content.WriteString(fmt.Sprintf("\nClientes (%d):\n", len(clients)))
+	for _, client := range clients {
+		content.WriteString(fmt.Sprintf("  VLAN %d %s", client.VLAN, client.ClientID))
+		if client.Hostname != "" {
+			content.WriteString(fmt.Sprintf(" (%s)", client.Hostname))
+		}
+		if client.IP != "" {
+			content.WriteString(fmt.Sprintf(" → %s de %s, %d s", client.IP, client.Server, client.LeaseTime))
+		}
+		content.WriteString("\n")
+		if client.VendorClass != "" {
+			content.WriteString(fmt.Sprintf("    Clase de Fabricante: %s\n", client.VendorClass))
+		}
+		if client.CircuitID != "" || client.RemoteID != "" {
+			content.WriteString(fmt.Sprintf("    Opción 82: circuit %s, remote %s\n", client.CircuitID, client.RemoteID))
+		}
+		for _, event := range client.Events {
+			content.WriteString(fmt.Sprintf("    %s #%-6d %-12s %s %s\n",
+				event.Timestamp.Format("15:04:05.000"), event.FrameID, event.MessageType, event.IP, event.Server))
+		}
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// dnsRecentTransactions is the number of transactions listed on the DNS screen
+const dnsRecentTransactions = 50
+
+// dnsModel represents the DNS statistics screen
+type dnsModel struct {
+	dns    *analyzer.DNSAnalyzer
+	scroll scroller
+}
+
+// newDNSModel creates a new DNS statistics screen model
+func newDNSModel(dns *analyzer.DNSAnalyzer) *dnsModel {
+	return &dnsModel{
+		dns: dns,
+	}
+}
+
+// Init initializes the DNS statistics screen model
+func (m *dnsModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the DNS statistics screen model
+func (m *dnsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the DNS statistics screen
+func (m *dnsModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🌐 Estadísticas DNS, mDNS y LLMNR\n\n")
+
+	var content strings.Builder
+
+	stats := m.dns.Stats()
+	content.WriteString(fmt.Sprintf("Consultas: %d  Respuestas: %d  Sin respuesta: %d  Respuestas sin consulta: %d\n",
+		stats.Queries, stats.Responses, stats.Unanswered, stats.Unmatched))
+	content.WriteString(fmt.Sprintf("NXDOMAIN: %d (%.1f%% de las respuestas)\n", stats.NXDomain, stats.NXDomainRate()*100))
+	content.WriteString(fmt.Sprintf("Latencia: mín %s  media %s  máx %s\n", stats.MinLatency, stats.AverageLatency, stats.MaxLatency))
+	content.WriteString(fmt.Sprintf("Protocolos: %s\n", formatCounts(stats.Protocols)))
+	content.WriteString(fmt.Sprintf("Tipos de consulta: %s\n", formatCounts(stats.QueryTypes)))
+	content.WriteString(fmt.Sprintf("Códigos de respuesta: %s\n", formatCounts(stats.ResponseCodes)))
+
+	clients := m.dns.Clients()
+	content.WriteString(fmt.Sprintf("\nClientes (%d):\n", len(clients)))
+	for _, client := range clients {
+		content.WriteString(fmt.Sprintf("  %-39s consultas %d, respuestas %d, NXDOMAIN %d\n",
+			client.Client, client.Queries, client.Responses, client.NXDomain))
+
+		names := make([]string, 0, len(client.Names))
+		for name := range client.Names {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			content.WriteString(fmt.Sprintf("    %s (%d)\n", name, client.Names[name]))
+		}
+	}
+
+	transactions := m.dns.Transactions()
+	if len(transactions) > dnsRecentTransactions {
+		transactions = transactions[len(transactions)-dnsRecentTransactions:]
+	}
+	content.WriteString(fmt.Sprintf("\nÚltimas transacciones (%d):\n", len(transactions)))
+	for _, transaction := range transactions {
+		content.WriteString(fmt.Sprintf("  #%-6d %-5s %-5s %-40s %-8s %10s %s\n",
+			transaction.QueryFrame,
+			transaction.Protocol,
+			transaction.Type,
+			truncate(transaction.Name, 40),
+			transaction.ResponseCode,
+			transaction.Latency,
+			strings.Join(transaction.Answers, ", "),
+		))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
+
+// formatCounts formats a map of counters sorted by descending count
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// dscpModel represents the DSCP mapping audit screen
+type dscpModel struct {
+	dscp   *analyzer.DSCPAnalyzer
+	scroll scroller
+}
+
+// newDSCPModel creates a new DSCP audit model
+func newDSCPModel(dscp *analyzer.DSCPAnalyzer) *dscpModel {
+	return &dscpModel{
+		dscp: dscp,
+	}
+}
+
+// Init initializes the DSCP audit model
+func (m *dscpModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the DSCP audit model
+func (m *dscpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the DSCP audit screen
+func (m *dscpModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🏷 Auditoría DSCP ↔ Prioridad L2\n\n")
+
+	entries := m.dscp.Entries()
+
+	var total, mismatched int
+	for _, entry := range entries {
+		total += entry.Frames
+		if entry.Mismatch() {
+			mismatched += entry.Frames
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("Tramas auditadas: %d | Discrepancias: %d | IP sin prioridad L2: %d\n\n",
+		total, mismatched, m.dscp.Unprioritized()))
+
+	var content strings.Builder
+	if len(entries) == 0 {
+		content.WriteString("No se han visto tramas IP con prioridad 802.11 o 802.1p\n")
+	} else {
+		content.WriteString(fmt.Sprintf("  %-12s %-10s %9s %9s %8s\n", "DSCP", "Origen", "Prioridad", "Esperada", "Tramas"))
+		for _, entry := range entries {
+			marker := " "
+			if entry.Mismatch() {
+				marker = "⚠"
+			}
+			content.WriteString(fmt.Sprintf("%s %-12s %-10s %9d %9d %8d\n",
+				marker,
+				fmt.Sprintf("%s (%d)", analyzer.DSCPName(entry.DSCP), entry.DSCP),
+				entry.Source,
+				entry.Priority,
+				entry.Expected,
+				entry.Frames,
+			))
+		}
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// errorsModel represents the error frames screen
+type errorsModel struct {
+	errors *analyzer.ErrorAnalyzer
+	scroll scroller
+}
+
+// newErrorsModel creates a new error frames screen model
+func newErrorsModel(errors *analyzer.ErrorAnalyzer) *errorsModel {
+	return &errorsModel{
+		errors: errors,
+	}
+}
+
+// Init initializes the error frames screen model
+func (m *errorsModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the error frames screen model
+func (m *errorsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the error frames screen
+func (m *errorsModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🧯 Tramas con Errores\n\n")
+
+	var content strings.Builder
+
+	stats := m.errors.Stats()
+	content.WriteString("Resumen:\n")
+	content.WriteString(fmt.Sprintf("  Tramas con FCS verificado: %d\n", stats.Checked))
+	content.WriteString(fmt.Sprintf("  Tramas con errores: %d\n", stats.ErrorFrames))
+	content.WriteString(fmt.Sprintf("  FCS incorrecto: %d, Runt: %d, Giant: %d\n", stats.BadFCS, stats.Runts, stats.Giants))
+	if stats.Checked == 0 {
+		content.WriteString("  La captura no incluye el FCS; use -fcs present al capturar desde un tap.\n")
+	}
+
+	sources := m.errors.Sources()
+	content.WriteString(fmt.Sprintf("\nOrígenes con errores (%d):\n", len(sources)))
+	if len(sources) == 0 {
+		content.WriteString("  Ninguno\n")
+	} else {
+		content.WriteString(fmt.Sprintf("  %-17s %8s %8s %7s %6s %6s %6s  %s\n",
+			"Origen", "Tramas", "Errores", "Tasa", "FCS", "Runt", "Giant", "Última"))
+	}
+	for _, source := range sources {
+		address := source.Address
+		if address == "" {
+			address = "(sin dirección)"
+		}
+		content.WriteString(fmt.Sprintf("  %-17s %8d %8d %6.1f%% %6d %6d %6d  #%d %s\n",
+			address, source.Frames, source.ErrorFrames, source.ErrorRate(),
+			source.BadFCS, source.Runts, source.Giants,
+			source.LastFrameID, source.LastError.Format("15:04:05")))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
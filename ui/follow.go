@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+	"github.com/julianarchila/gocapture/internal/storage"
+)
+
+// Directions shown by the follow stream screen
+const (
+	followBoth = iota
+	followClient
+	followServer
+)
+
+// followModel represents the follow TCP stream screen
+type followModel struct {
+	storageManager *storage.StorageManager
+	stream         *analyzer.TCPStream
+	hex            bool
+	direction      int
+	message        string
+	scroll         scroller
+}
+
+// newFollowModel creates a new follow stream screen model
+func newFollowModel(storageManager *storage.StorageManager) *followModel {
+	return &followModel{
+		storageManager: storageManager,
+	}
+}
+
+// setStream sets the stream to display
+func (m *followModel) setStream(stream *analyzer.TCPStream) {
+	m.stream = stream
+	m.direction = followBoth
+	m.message = ""
+	m.scroll = scroller{}
+}
+
+// Init initializes the follow stream screen model
+func (m *followModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the follow stream screen model
+func (m *followModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "tab":
+			m.hex = !m.hex
+			m.scroll = scroller{}
+		case "d":
+			m.direction = (m.direction + 1) % 3
+			m.scroll = scroller{}
+		case "s":
+			m.save()
+		default:
+			m.scroll.update(keyMsg)
+		}
+	}
+
+	return m, nil
+}
+
+// save writes the bytes of the selected direction to a file in the captures directory
+func (m *followModel) save() {
+	if m.stream == nil {
+		return
+	}
+
+	suffix := map[int]string{followBoth: "", followClient: "_cliente", followServer: "_servidor"}[m.direction]
+	filename := fmt.Sprintf("stream_%d_%s%s.bin", m.stream.ID, time.Now().Format("20060102_150405"), suffix)
+
+	data := m.stream.Payload(m.direction != followServer, m.direction != followClient)
+	if saved, err := m.storageManager.SaveFile(filename, data); err != nil {
+		m.message = fmt.Sprintf("Error al guardar: %v", err)
+	} else {
+		m.message = fmt.Sprintf("Stream guardado en %s (%d bytes)", saved, len(data))
+	}
+}
+
+// View renders the follow stream screen
+func (m *followModel) View() string {
+	var sb strings.Builder
+
+	if m.stream == nil {
+		return "No hay stream seleccionado"
+	}
+	stream := m.stream
+
+	sb.WriteString(fmt.Sprintf("🔗 Seguir Stream TCP #%d\n\n", stream.ID))
+	sb.WriteString(fmt.Sprintf("Cliente: %s (%d bytes)\n", stream.Client, stream.ClientBytes))
+	sb.WriteString(fmt.Sprintf("Servidor: %s (%d bytes)\n", stream.Server, stream.ServerBytes))
+
+	state := "abierto"
+	if stream.Closed {
+		state = "cerrado"
+	}
+	sb.WriteString(fmt.Sprintf("Tramas: %d, duración %s, %s\n", stream.Frames,
+		stream.LastSeen.Sub(stream.FirstSeen).Round(time.Millisecond), state))
+	if stream.Truncated {
+		sb.WriteString("Solo se conservó el primer MiB de carga útil\n")
+	}
+
+	mode := "ASCII"
+	if m.hex {
+		mode = "Hex"
+	}
+	directions := []string{"ambas direcciones", "solo cliente → servidor", "solo servidor → cliente"}
+	sb.WriteString(fmt.Sprintf("Mostrando: %s, %s\n\n", directions[m.direction], mode))
+
+	var content strings.Builder
+	shown := 0
+	for _, chunk := range stream.Chunks {
+		if (chunk.FromClient && m.direction == followServer) || (!chunk.FromClient && m.direction == followClient) {
+			continue
+		}
+		shown++
+
+		arrow := "Cliente → Servidor"
+		if !chunk.FromClient {
+			arrow = "Servidor → Cliente"
+		}
+		content.WriteString(fmt.Sprintf("── %s, trama #%d, %s, %d bytes",
+			arrow, chunk.FrameID, chunk.Timestamp.Format("15:04:05.000"), len(chunk.Data)))
+		if chunk.Skipped > 0 {
+			content.WriteString(fmt.Sprintf(" [%d bytes perdidos antes]", chunk.Skipped))
+		}
+		content.WriteString("\n")
+
+		if m.hex {
+			writeHexDump(&content, chunk.Data)
+		} else {
+			writeStreamText(&content, chunk.Data)
+		}
+	}
+	if shown == 0 {
+		content.WriteString("Sin carga útil reensamblada\n")
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	if m.message != "" {
+		sb.WriteString(fmt.Sprintf("\n%s\n", m.message))
+	}
+	sb.WriteString("\nTab: ASCII/Hex, d: dirección, s: guardar, flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
+
+// writeStreamText writes payload as text, replacing non-printable bytes with dots
+func writeStreamText(sb *strings.Builder, data []byte) {
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\r\n"), "\n") {
+		for _, b := range []byte(strings.TrimRight(line, "\r")) {
+			if (b >= 32 && b <= 126) || b == '\t' {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteString("\n")
+	}
+}
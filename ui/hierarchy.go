@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// hierarchyModel represents the protocol hierarchy screen
+type hierarchyModel struct {
+	hierarchy *analyzer.HierarchyAnalyzer
+	scroll    scroller
+}
+
+// newHierarchyModel creates a new protocol hierarchy screen model
+func newHierarchyModel(hierarchy *analyzer.HierarchyAnalyzer) *hierarchyModel {
+	return &hierarchyModel{
+		hierarchy: hierarchy,
+	}
+}
+
+// Init initializes the protocol hierarchy screen model
+func (m *hierarchyModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the protocol hierarchy screen model
+func (m *hierarchyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the protocol hierarchy screen
+func (m *hierarchyModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🌳 Jerarquía de Protocolos\n\n")
+
+	root := m.hierarchy.Hierarchy()
+	sb.WriteString(fmt.Sprintf("Total: %d tramas, %d bytes\n\n", root.Frames, root.Bytes))
+
+	var content strings.Builder
+	if len(root.Children) == 0 {
+		content.WriteString("Sin tramas\n")
+	} else {
+		content.WriteString(fmt.Sprintf("%-36s %8s %9s %8s %11s\n", "Protocolo", "% Tramas", "Tramas", "% Bytes", "Bytes"))
+		for _, child := range root.Children {
+			renderProtocolNode(&content, child, 0)
+		}
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
+
+// renderProtocolNode renders a protocol and its children indented by depth
+func renderProtocolNode(sb *strings.Builder, node *analyzer.ProtocolNode, depth int) {
+	name := strings.Repeat("  ", depth) + node.Protocol
+	sb.WriteString(fmt.Sprintf("%-36s %7.1f%% %9d %7.1f%% %11d\n",
+		truncate(name, 36), node.FramePercent, node.Frames, node.BytePercent, node.Bytes))
+
+	for _, child := range node.Children {
+		renderProtocolNode(sb, child, depth+1)
+	}
+}
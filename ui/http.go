@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+	"github.com/julianarchila/gocapture/internal/storage"
+)
+
+// httpModel represents the HTTP transactions screen
+type httpModel struct {
+	http           *analyzer.HTTPAnalyzer
+	storageManager *storage.StorageManager
+	message        string
+	scroll         scroller
+}
+
+// newHTTPModel creates a new HTTP transactions screen model
+func newHTTPModel(http *analyzer.HTTPAnalyzer, storageManager *storage.StorageManager) *httpModel {
+	return &httpModel{
+		http:           http,
+		storageManager: storageManager,
+	}
+}
+
+// Init initializes the HTTP transactions screen model
+func (m *httpModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the HTTP transactions screen model
+func (m *httpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "s":
+			m.export()
+		default:
+			m.scroll.update(keyMsg)
+		}
+	}
+
+	return m, nil
+}
+
+// export saves the transactions as a HAR file in the captures directory
+func (m *httpModel) export() {
+	transactions := m.http.Transactions()
+	har := storage.NewHAR()
+	for _, t := range transactions {
+		har.Log.Entries = append(har.Log.Entries, harEntry(t))
+	}
+
+	filename := fmt.Sprintf("http_%s.har", time.Now().Format("20060102_150405"))
+	if saved, err := m.storageManager.SaveHAR(filename, har); err != nil {
+		m.message = fmt.Sprintf("Error al exportar: %v", err)
+	} else {
+		m.message = fmt.Sprintf("Transacciones exportadas a %s (%d entradas)", saved, len(transactions))
+	}
+}
+
+// harEntry converts an HTTP transaction to a HAR entry
+func harEntry(t analyzer.HTTPTransaction) storage.HAREntry {
+	host := t.Host
+	if host == "" {
+		host = t.Server
+	}
+	rawURL := t.URI
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		rawURL = "http://" + host + t.URI
+	}
+
+	entry := storage.HAREntry{
+		StartedDateTime: t.Start().Format(time.RFC3339Nano),
+		Request: storage.HARRequest{
+			Method:      t.Method,
+			URL:         rawURL,
+			HTTPVersion: t.RequestVersion,
+			Cookies:     []storage.HARNameValue{},
+			Headers:     harHeaders(t.RequestHeaders),
+			QueryString: []storage.HARNameValue{},
+			HeadersSize: t.RequestHeaderSize,
+			BodySize:    t.RequestBodySize,
+		},
+		Response: storage.HARResponse{
+			Status:      t.Status,
+			StatusText:  t.Reason,
+			HTTPVersion: t.ResponseVersion,
+			Cookies:     []storage.HARNameValue{},
+			Headers:     harHeaders(t.ResponseHeaders),
+			Content:     storage.HARContent{Size: t.ResponseBodySize, MimeType: t.ContentType},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings:    storage.HARTimings{Send: -1, Wait: -1, Receive: -1},
+		Connection: fmt.Sprint(t.StreamID),
+	}
+
+	if parsed, err := url.Parse(rawURL); err == nil {
+		for name, values := range parsed.Query() {
+			for _, value := range values {
+				entry.Request.QueryString = append(entry.Request.QueryString, storage.HARNameValue{Name: name, Value: value})
+			}
+		}
+	}
+	if ip, _, err := net.SplitHostPort(t.Server); err == nil {
+		entry.ServerIPAddress = ip
+	}
+
+	if t.Answered() {
+		entry.Response.HeadersSize = t.ResponseHeaderSize
+		entry.Response.BodySize = t.ResponseBodySize
+		entry.Response.RedirectURL = analyzer.HTTPHeaderValue(t.ResponseHeaders, "Location")
+		if t.Method != "" {
+			entry.Timings = storage.HARTimings{
+				Send:    milliseconds(t.RequestEnd.Sub(t.RequestStart)),
+				Wait:    milliseconds(t.ResponseTime()),
+				Receive: milliseconds(t.ResponseEnd.Sub(t.ResponseStart)),
+			}
+			entry.Time = entry.Timings.Send + entry.Timings.Wait + entry.Timings.Receive
+		}
+	}
+
+	return entry
+}
+
+// harHeaders converts HTTP headers to HAR name and value pairs
+func harHeaders(headers []analyzer.HTTPHeader) []storage.HARNameValue {
+	pairs := make([]storage.HARNameValue, 0, len(headers))
+	for _, header := range headers {
+		pairs = append(pairs, storage.HARNameValue{Name: header.Name, Value: header.Value})
+	}
+	return pairs
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// View renders the HTTP transactions screen
+func (m *httpModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🌍 Transacciones HTTP\n\n")
+
+	transactions := m.http.Transactions()
+	statusClasses := make(map[string]int)
+	methods := make(map[string]int)
+	var answered int
+	var total, slowest time.Duration
+	for _, t := range transactions {
+		if t.Method != "" {
+			methods[t.Method]++
+		}
+		if !t.Answered() {
+			continue
+		}
+		statusClasses[fmt.Sprintf("%dxx", t.Status/100)]++
+		if t.Method != "" {
+			answered++
+			total += t.ResponseTime()
+			slowest = max(slowest, t.ResponseTime())
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString("Resumen:\n")
+	content.WriteString(fmt.Sprintf("  Transacciones: %d\n", len(transactions)))
+	content.WriteString(fmt.Sprintf("  Métodos: %s\n", formatCounts(methods)))
+	content.WriteString(fmt.Sprintf("  Códigos de estado: %s\n", formatCounts(statusClasses)))
+	if answered > 0 {
+		content.WriteString(fmt.Sprintf("  Tiempo de respuesta: medio %s, máximo %s\n",
+			formatRTT(total/time.Duration(answered)), formatRTT(slowest)))
+	}
+	if dropped := m.http.Dropped(); dropped > 0 {
+		content.WriteString(fmt.Sprintf("  Tabla llena: %d transacciones sin guardar\n", dropped))
+	}
+
+	content.WriteString(fmt.Sprintf("\nTransacciones (%d):\n", len(transactions)))
+	if len(transactions) == 0 {
+		content.WriteString("  Sin transacciones\n")
+	}
+	for _, t := range transactions {
+		request := "(petición no capturada)"
+		if t.Method != "" {
+			request = fmt.Sprintf("%s %s%s", t.Method, t.Host, t.URI)
+		}
+		response := "sin respuesta"
+		if t.Answered() {
+			response = fmt.Sprintf("%d %s", t.Status, t.Reason)
+			if t.ContentType != "" {
+				response += ", " + t.ContentType
+			}
+			response += fmt.Sprintf(", %d bytes", t.ResponseBodySize)
+			if t.Method != "" {
+				response += ", " + formatRTT(t.ResponseTime())
+			}
+			if !t.Complete {
+				response += " [incompleta]"
+			}
+		}
+		content.WriteString(fmt.Sprintf("  %s #%d %s\n", t.Start().Format("15:04:05.000"), t.StreamID, truncate(request, 100)))
+		content.WriteString(fmt.Sprintf("      → %s\n", response))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	if m.message != "" {
+		sb.WriteString(fmt.Sprintf("\n%s\n", m.message))
+	}
+	sb.WriteString("\ns: exportar HAR, flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
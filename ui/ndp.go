@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// ndModel represents the IPv6 neighbor cache and router advertisement screen
+type ndModel struct {
+	nd     *analyzer.NDAnalyzer
+	scroll scroller
+}
+
+// newNDModel creates a new IPv6 neighbor screen model
+func newNDModel(nd *analyzer.NDAnalyzer) *ndModel {
+	return &ndModel{
+		nd: nd,
+	}
+}
+
+// Init initializes the IPv6 neighbor screen model
+func (m *ndModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the IPv6 neighbor screen model
+func (m *ndModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the IPv6 neighbor screen
+func (m *ndModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("📡 Vecinos IPv6 y Anuncios de Router\n\n")
+
+	var content strings.Builder
+
+	alerts := m.nd.Alerts()
+	content.WriteString(fmt.Sprintf("Alertas (%d):\n", len(alerts)))
+	if len(alerts) == 0 {
+		content.WriteString("  Ninguna\n")
+	}
+	for _, alert := range alerts {
+		content.WriteString(fmt.Sprintf("  ⚠ [%s] #%d %s: %s\n",
+			alert.Timestamp.Format("15:04:05.000"), alert.FrameID, alert.Type, alert.Description))
+	}
+
+	routers := m.nd.Routers()
+	content.WriteString(fmt.Sprintf("\nRouters (%d):\n", len(routers)))
+	if len(routers) == 0 {
+		content.WriteString("  Ninguno\n")
+	}
+	for _, router := range routers {
+		status := "esperado"
+		if !router.Expected {
+			status = "NO ESPERADO"
+		}
+		content.WriteString(fmt.Sprintf("  %s (%s) [%s]\n", router.IP, router.MAC, status))
+		content.WriteString(fmt.Sprintf("    Anuncios: %d, vida %ds, preferencia %s, M=%v O=%v, último %s\n",
+			router.Advertisements, router.Lifetime, router.Preference, router.Managed, router.OtherConfig,
+			router.LastSeen.Format("15:04:05.000")))
+		if len(router.Prefixes) > 0 {
+			content.WriteString(fmt.Sprintf("    Prefijos: %s\n", strings.Join(router.Prefixes, ", ")))
+		}
+		if router.MTU != 0 {
+			content.WriteString(fmt.Sprintf("    MTU: %d\n", router.MTU))
+		}
+		if len(router.RDNSS) > 0 {
+			content.WriteString(fmt.Sprintf("    DNS: %s\n", strings.Join(router.RDNSS, ", ")))
+		}
+	}
+
+	neighbors := m.nd.Neighbors()
+	content.WriteString(fmt.Sprintf("\nCaché de Vecinos (%d):\n", len(neighbors)))
+	if len(neighbors) > 0 {
+		content.WriteString(fmt.Sprintf("  %-39s %-17s %-6s %7s %-12s %s\n", "IPv6", "MAC", "Router", "Tramas", "Última vez", "MACs anteriores"))
+	}
+	for _, neighbor := range neighbors {
+		router := ""
+		if neighbor.Router {
+			router = "sí"
+		}
+		content.WriteString(fmt.Sprintf("  %-39s %-17s %-6s %7d %-12s %s\n",
+			neighbor.IP,
+			neighbor.MAC,
+			router,
+			neighbor.Frames,
+			neighbor.LastSeen.Format("15:04:05.000"),
+			strings.Join(neighbor.PreviousMACs, ", "),
+		))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// neighborsModel represents the LLDP/CDP neighbors screen
+type neighborsModel struct {
+	neighbors *analyzer.NeighborAnalyzer
+	scroll    scroller
+}
+
+// newNeighborsModel creates a new neighbors screen model
+func newNeighborsModel(neighbors *analyzer.NeighborAnalyzer) *neighborsModel {
+	return &neighborsModel{
+		neighbors: neighbors,
+	}
+}
+
+// Init initializes the neighbors screen model
+func (m *neighborsModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the neighbors screen model
+func (m *neighborsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the neighbors screen
+func (m *neighborsModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🔌 Vecinos LLDP/CDP\n\n")
+
+	var content strings.Builder
+
+	neighbors := m.neighbors.Neighbors()
+	content.WriteString(fmt.Sprintf("Dispositivos descubiertos (%d):\n", len(neighbors)))
+	if len(neighbors) == 0 {
+		content.WriteString("  Ninguno. LLDP y CDP solo se reciben en enlaces cableados.\n")
+	}
+
+	for _, neighbor := range neighbors {
+		name := neighbor.SystemName
+		if name == "" {
+			name = neighbor.ChassisID
+		}
+
+		content.WriteString(fmt.Sprintf("\n  %s [%s] desde %s\n", name, neighbor.Protocol, neighbor.SourceMAC))
+		content.WriteString(fmt.Sprintf("    Chasis: %s (%s)\n", neighbor.ChassisID, neighbor.ChassisIDType))
+		content.WriteString(fmt.Sprintf("    Puerto: %s (%s)", neighbor.PortID, neighbor.PortIDType))
+		if neighbor.PortDescription != "" {
+			content.WriteString(fmt.Sprintf(" - %s", neighbor.PortDescription))
+		}
+		content.WriteString("\n")
+		if neighbor.Platform != "" {
+			content.WriteString(fmt.Sprintf("    Plataforma: %s\n", neighbor.Platform))
+		}
+		if neighbor.SystemDescription != "" {
+			content.WriteString(fmt.Sprintf("    Descripción: %s\n", firstLine(neighbor.SystemDescription)))
+		}
+		if len(neighbor.ManagementAddresses) > 0 {
+			content.WriteString(fmt.Sprintf("    Gestión: %s\n", strings.Join(neighbor.ManagementAddresses, ", ")))
+		}
+		if neighbor.VLAN != 0 {
+			content.WriteString(fmt.Sprintf("    VLAN del puerto: %d\n", neighbor.VLAN))
+		}
+		if len(neighbor.Capabilities) > 0 {
+			content.WriteString(fmt.Sprintf("    Capacidades: %s (habilitadas: %s)\n",
+				strings.Join(neighbor.Capabilities, ", "), strings.Join(neighbor.EnabledCapabilities, ", ")))
+		}
+		if poe := neighbor.PoE; poe != nil {
+			content.WriteString(fmt.Sprintf("    PoE: clase %d, solicitado %.1f W, asignado %.1f W", poe.Class, poe.RequestedWatts, poe.AllocatedWatts))
+			if poe.PowerType != "" {
+				content.WriteString(fmt.Sprintf(", %s, fuente %s, prioridad %s", poe.PowerType, poe.PowerSource, poe.PowerPriority))
+			}
+			content.WriteString("\n")
+		}
+		content.WriteString(fmt.Sprintf("    Tramas: %d, TTL %d s, última %s\n",
+			neighbor.Frames, neighbor.TTL, neighbor.LastSeen.Format("15:04:05")))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
+
+// firstLine returns the first line of a multi-line string
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
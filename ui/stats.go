@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// Statistics menu options
+const (
+	statsOptionAirtime       = "Airtime y Reintentos"
+	statsOptionWMM           = "Cumplimiento WMM"
+	statsOptionDSCP          = "Auditoría DSCP"
+	statsOptionARP           = "Tabla ARP"
+	statsOptionDHCP          = "Concesiones DHCP"
+	statsOptionDNS           = "Estadísticas DNS"
+	statsOptionNeighbors     = "Vecinos LLDP/CDP"
+	statsOptionSTP           = "Spanning Tree"
+	statsOptionErrors        = "Tramas con Errores"
+	statsOptionTCP           = "Análisis TCP"
+	statsOptionConversations = "Conversaciones"
+	statsOptionEndpoints     = "Endpoints"
+	statsOptionHierarchy     = "Jerarquía de Protocolos"
+	statsOptionTLS           = "Sesiones TLS"
+	statsOptionHTTP          = "Transacciones HTTP"
+	statsOptionND            = "Vecinos IPv6"
+)
+
+// statsPageHeight is the number of lines shown at once by scrollable statistics screens
+const statsPageHeight = 30
+
+// statsMenuModel represents the statistics menu UI component
+type statsMenuModel struct {
+	options []string
+	cursor  int
+}
+
+// newStatsMenuModel creates a new statistics menu model
+func newStatsMenuModel() *statsMenuModel {
+	return &statsMenuModel{
+		options: []string{
+			statsOptionAirtime,
+			statsOptionWMM,
+			statsOptionDSCP,
+			statsOptionARP,
+			statsOptionDHCP,
+			statsOptionDNS,
+			statsOptionNeighbors,
+			statsOptionSTP,
+			statsOptionErrors,
+			statsOptionTCP,
+			statsOptionConversations,
+			statsOptionEndpoints,
+			statsOptionHierarchy,
+			statsOptionTLS,
+			statsOptionHTTP,
+			statsOptionND,
+		},
+		cursor: 0,
+	}
+}
+
+// Init initializes the statistics menu model
+func (m *statsMenuModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the statistics menu model
+func (m *statsMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.options)-1 {
+				m.cursor++
+			}
+		case "enter":
+			// Return a statistics selected message
+			return m, func() tea.Msg {
+				return statsSelectedMsg{option: m.options[m.cursor]}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the statistics menu
+func (m *statsMenuModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("📊 Estadísticas\n\n")
+
+	for i, option := range m.options {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+
+		sb.WriteString(fmt.Sprintf("%s %s\n", cursor, option))
+	}
+
+	sb.WriteString("\nUse las teclas de flecha para navegar, Enter para seleccionar, Esc para volver\n")
+
+	return sb.String()
+}
+
+// statsSelectedMsg is a message sent when a statistics screen is selected
+type statsSelectedMsg struct {
+	option string
+}
+
+// airtimeModel represents the retry and airtime dashboard
+type airtimeModel struct {
+	airtime *analyzer.AirtimeAnalyzer
+	order   analyzer.AirtimeOrder
+	limit   int
+}
+
+// newAirtimeModel creates a new airtime dashboard model
+func newAirtimeModel(airtime *analyzer.AirtimeAnalyzer) *airtimeModel {
+	return &airtimeModel{
+		airtime: airtime,
+		order:   analyzer.OrderByRetries,
+		limit:   10,
+	}
+}
+
+// Init initializes the airtime dashboard model
+func (m *airtimeModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the airtime dashboard model
+func (m *airtimeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab":
+			// Toggle between ranking by retries and by airtime
+			if m.order == analyzer.OrderByRetries {
+				m.order = analyzer.OrderByAirtime
+			} else {
+				m.order = analyzer.OrderByRetries
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the airtime dashboard
+func (m *airtimeModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("📶 Airtime y Reintentos\n\n")
+
+	current, average := m.airtime.Utilization()
+	windows := m.airtime.Windows()
+	sb.WriteString(fmt.Sprintf("Utilización del canal: actual %.1f%% | promedio %.1f%% (%d ventanas de %s)\n",
+		current, average, len(windows), m.airtime.WindowSize()))
+
+	if m.order == analyzer.OrderByRetries {
+		sb.WriteString("Ordenado por: porcentaje de reintentos\n\n")
+	} else {
+		sb.WriteString("Ordenado por: airtime estimado\n\n")
+	}
+
+	sb.WriteString("Peores BSS:\n")
+	renderAirtimeTable(&sb, "BSSID", m.airtime.TopBSS(m.limit, m.order))
+
+	sb.WriteString("\nPeores Estaciones:\n")
+	renderAirtimeTable(&sb, "Estación", m.airtime.TopStations(m.limit, m.order))
+
+	sb.WriteString("\nUse Tab para cambiar el orden, Esc para volver\n")
+
+	return sb.String()
+}
+
+// renderAirtimeTable renders a table of airtime statistics
+func renderAirtimeTable(sb *strings.Builder, title string, entries []*analyzer.AirtimeStats) {
+	if len(entries) == 0 {
+		sb.WriteString("  Sin datos suficientes\n")
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("  %-17s %8s %8s %10s %12s\n", title, "Tramas", "Reint.%", "Bytes", "Airtime"))
+	for _, stats := range entries {
+		sb.WriteString(fmt.Sprintf("  %-17s %8d %7.1f%% %10d %12s\n",
+			stats.Address,
+			stats.Frames,
+			stats.RetryPercent(),
+			stats.Bytes,
+			stats.Airtime.Round(time.Microsecond),
+		))
+	}
+}
+
+// scroller keeps the scroll position of a statistics screen
+type scroller struct {
+	offset int
+}
+
+// update moves the scroll position according to a key press
+func (s *scroller) update(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "up", "k":
+		s.offset--
+	case "down", "j":
+		s.offset++
+	case "pgup":
+		s.offset -= statsPageHeight
+	case "pgdown":
+		s.offset += statsPageHeight
+	}
+
+	if s.offset < 0 {
+		s.offset = 0
+	}
+}
+
+// render returns the visible page of content
+func (s *scroller) render(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	if s.offset > len(lines)-statsPageHeight {
+		s.offset = len(lines) - statsPageHeight
+	}
+	if s.offset < 0 {
+		s.offset = 0
+	}
+
+	end := s.offset + statsPageHeight
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var sb strings.Builder
+	for _, line := range lines[s.offset:end] {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	if len(lines) > statsPageHeight {
+		sb.WriteString(fmt.Sprintf("\nMostrando líneas %d-%d de %d\n", s.offset+1, end, len(lines)))
+	}
+
+	return sb.String()
+}
+
+// truncate shortens a string to fit a table column
+func truncate(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:width-1] + "…"
+}
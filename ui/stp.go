@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// stpModel represents the spanning tree screen
+type stpModel struct {
+	stp    *analyzer.STPAnalyzer
+	scroll scroller
+}
+
+// newSTPModel creates a new spanning tree screen model
+func newSTPModel(stp *analyzer.STPAnalyzer) *stpModel {
+	return &stpModel{
+		stp: stp,
+	}
+}
+
+// Init initializes the spanning tree screen model
+func (m *stpModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the spanning tree screen model
+func (m *stpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the spanning tree screen
+func (m *stpModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🌲 Spanning Tree (STP/RSTP/MSTP)\n\n")
+
+	var content strings.Builder
+
+	roots := m.stp.Roots()
+	content.WriteString(fmt.Sprintf("Puentes raíz (%d):\n", len(roots)))
+	if len(roots) == 0 {
+		content.WriteString("  No se han recibido BPDUs\n")
+	}
+	for _, root := range roots {
+		content.WriteString(fmt.Sprintf("  VLAN %-4d %s desde %s, %d cambios\n",
+			root.VLAN, root.RootID, root.Since.Format("15:04:05.000"), root.Changes))
+	}
+
+	bridges := m.stp.Bridges()
+	content.WriteString(fmt.Sprintf("\nPuentes (%d):\n", len(bridges)))
+	if len(bridges) > 0 {
+		content.WriteString(fmt.Sprintf("  %-4s %-30s %-5s %-17s %9s %6s %6s %4s %4s\n",
+			"VLAN", "Puente", "Ver", "Rol", "Costo", "BPDUs", "TC", "TCN", "Fwd"))
+	}
+	for _, bridge := range bridges {
+		content.WriteString(fmt.Sprintf("  %-4d %-30s %-5s %-17s %9d %6d %6d %4d %4v\n",
+			bridge.VLAN,
+			bridge.BridgeID,
+			bridge.Version,
+			bridge.PortRole,
+			bridge.RootPathCost,
+			bridge.BPDUs,
+			bridge.TopologyChanges,
+			bridge.TCNs,
+			bridge.Forwarding,
+		))
+		if bridge.MSTRegion != "" {
+			content.WriteString(fmt.Sprintf("       Región MST: %s\n", bridge.MSTRegion))
+			for _, instance := range bridge.MSTInstances {
+				content.WriteString(fmt.Sprintf("       MSTI %d: raíz regional %s, costo %d, rol %s\n",
+					instance.ID, instance.RegionalRootID, instance.InternalRootPathCost, instance.PortRole))
+			}
+		}
+	}
+
+	events := m.stp.Events()
+	content.WriteString(fmt.Sprintf("\nEventos (%d):\n", len(events)))
+	for _, event := range events {
+		marker := " "
+		if event.Alert {
+			marker = "⚠"
+		}
+		content.WriteString(fmt.Sprintf("  %s [%s] #%d VLAN %d %s: %s\n",
+			marker, event.Timestamp.Format("15:04:05.000"), event.FrameID, event.VLAN, event.Type, event.Description))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
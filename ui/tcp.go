@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// tcpModel represents the TCP expert analysis screen
+type tcpModel struct {
+	tcp    *analyzer.TCPAnalyzer
+	scroll scroller
+}
+
+// newTCPModel creates a new TCP analysis screen model
+func newTCPModel(tcp *analyzer.TCPAnalyzer) *tcpModel {
+	return &tcpModel{
+		tcp: tcp,
+	}
+}
+
+// Init initializes the TCP analysis screen model
+func (m *tcpModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the TCP analysis screen model
+func (m *tcpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the TCP analysis screen
+func (m *tcpModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🔁 Análisis TCP\n\n")
+
+	var content strings.Builder
+
+	connections := m.tcp.Connections()
+	var total analyzer.TCPConnection
+	for _, conn := range connections {
+		total.Retransmissions += conn.Retransmissions
+		total.FastRetransmissions += conn.FastRetransmissions
+		total.OutOfOrder += conn.OutOfOrder
+		total.LostSegments += conn.LostSegments
+		total.DupAcks += conn.DupAcks
+		total.ZeroWindows += conn.ZeroWindows
+		total.WindowFull += conn.WindowFull
+	}
+
+	content.WriteString("Resumen:\n")
+	content.WriteString(fmt.Sprintf("  Conexiones: %d\n", len(connections)))
+	content.WriteString(fmt.Sprintf("  Retransmisiones: %d (rápidas: %d)\n", total.Retransmissions, total.FastRetransmissions))
+	content.WriteString(fmt.Sprintf("  Fuera de orden: %d, Segmentos no capturados: %d\n", total.OutOfOrder, total.LostSegments))
+	content.WriteString(fmt.Sprintf("  ACKs duplicados: %d\n", total.DupAcks))
+	content.WriteString(fmt.Sprintf("  Ventana cero: %d, Ventana llena: %d\n", total.ZeroWindows, total.WindowFull))
+
+	content.WriteString(fmt.Sprintf("\nConexiones (%d), las de más problemas primero:\n", len(connections)))
+	for _, conn := range connections {
+		content.WriteString(fmt.Sprintf("\n  %s ↔ %s\n", conn.Client, conn.Server))
+		content.WriteString(fmt.Sprintf("    Tramas: %d, duración %s\n", conn.Frames, conn.LastSeen.Sub(conn.FirstSeen).Round(time.Millisecond)))
+		if conn.Problems() > 0 {
+			content.WriteString(fmt.Sprintf("    Retrans: %d (rápidas %d), Fuera de orden: %d, No capturados: %d, ACK dup: %d, Ventana cero: %d, Ventana llena: %d\n",
+				conn.Retransmissions, conn.FastRetransmissions, conn.OutOfOrder, conn.LostSegments,
+				conn.DupAcks, conn.ZeroWindows, conn.WindowFull))
+		}
+		if conn.RTTSamples > 0 {
+			content.WriteString(fmt.Sprintf("    RTT: mín %s, media %s, máx %s (%d muestras)\n",
+				formatRTT(conn.MinRTT), formatRTT(conn.AverageRTT()), formatRTT(conn.MaxRTT), conn.RTTSamples))
+		}
+		if conn.HandshakeLatency > 0 {
+			content.WriteString(fmt.Sprintf("    Handshake: %s\n", formatRTT(conn.HandshakeLatency)))
+		}
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
+
+// formatRTT formats a round trip time with microsecond precision
+func formatRTT(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+)
+
+// tlsModel represents the TLS sessions screen
+type tlsModel struct {
+	tls    *analyzer.TLSAnalyzer
+	scroll scroller
+}
+
+// newTLSModel creates a new TLS sessions screen model
+func newTLSModel(tls *analyzer.TLSAnalyzer) *tlsModel {
+	return &tlsModel{
+		tls: tls,
+	}
+}
+
+// Init initializes the TLS sessions screen model
+func (m *tlsModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the TLS sessions screen model
+func (m *tlsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the TLS sessions screen
+func (m *tlsModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🔒 Sesiones TLS\n\n")
+
+	sessions := m.tls.Sessions()
+	versions := make(map[string]int)
+	sniCount := make(map[string]int)
+	for _, session := range sessions {
+		if session.Version != "" {
+			versions[session.Version]++
+		}
+		if session.SNI != "" {
+			sniCount[session.SNI]++
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString("Resumen:\n")
+	content.WriteString(fmt.Sprintf("  Sesiones: %d, nombres SNI distintos: %d\n", len(sessions), len(sniCount)))
+	if len(versions) > 0 {
+		content.WriteString(fmt.Sprintf("  Versiones negociadas: %s\n", formatCounts(versions)))
+	}
+
+	content.WriteString(fmt.Sprintf("\nSesiones (%d):\n", len(sessions)))
+	for _, session := range sessions {
+		content.WriteString(fmt.Sprintf("\n  #%d %s → %s (%s)\n", session.StreamID, session.Client, session.Server,
+			session.FirstSeen.Format("15:04:05.000")))
+		if session.SNI != "" {
+			content.WriteString(fmt.Sprintf("    SNI: %s\n", session.SNI))
+		}
+		if session.Version != "" {
+			content.WriteString(fmt.Sprintf("    Versión: %s (ofrecida %s), %s\n", session.Version, session.OfferedVersion, session.CipherSuite))
+		} else if session.OfferedVersion != "" {
+			content.WriteString(fmt.Sprintf("    Versión ofrecida: %s, sin Server Hello\n", session.OfferedVersion))
+		}
+		if len(session.OfferedALPN) > 0 || session.ALPN != "" {
+			content.WriteString(fmt.Sprintf("    ALPN: %s (ofrecidos %s)\n", session.ALPN, strings.Join(session.OfferedALPN, ", ")))
+		}
+		if session.JA3Hash != "" {
+			content.WriteString(fmt.Sprintf("    JA3: %s\n", session.JA3Hash))
+			content.WriteString(fmt.Sprintf("    JA4: %s\n", session.JA4))
+		}
+		if session.JA3SHash != "" {
+			content.WriteString(fmt.Sprintf("    JA3S: %s\n", session.JA3SHash))
+		}
+		for i, cert := range session.Certificates {
+			expired := ""
+			if !cert.NotAfter.IsZero() && cert.NotAfter.Before(session.FirstSeen) {
+				expired = " [expirado]"
+			}
+			content.WriteString(fmt.Sprintf("    Certificado %d: %s, emisor %s, válido hasta %s%s\n",
+				i+1, cert.Subject, cert.Issuer, cert.NotAfter.Format(time.DateOnly), expired))
+		}
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}
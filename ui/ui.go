@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/julianarchila/gocapture/internal/analyzer"
@@ -12,6 +13,10 @@ import (
 	"github.com/julianarchila/gocapture/pkg/models"
 )
 
+// maxRecordingFrames is the number of frames kept in memory while a capture is
+// recorded to disk, so that long captures do not grow without bound
+const maxRecordingFrames = 10000
+
 // UI states
 const (
 	stateMainMenu = iota
@@ -19,6 +24,25 @@ const (
 	stateFrameList
 	stateFrameDetail
 	stateSavedCaptures
+	stateStatistics
+	stateAirtime
+	stateWMM
+	stateDSCP
+	stateARP
+	stateDHCP
+	stateDNS
+	stateNeighbors
+	stateSTP
+	stateErrors
+	stateFollow
+	stateTCP
+	stateConversations
+	stateEndpoints
+	stateHierarchy
+	stateTLS
+	stateHTTP
+	stateND
+	stateCaptureFilter
 )
 
 // MainModel is the main UI model
@@ -37,22 +61,41 @@ type MainModel struct {
 	frameList     *frameListModel
 	frameDetail   *frameDetailModel
 	savedCaptures *savedCapturesModel
+	statsMenu     *statsMenuModel
+	airtime       *airtimeModel
+	wmm           *wmmModel
+	dscp          *dscpModel
+	arp           *arpModel
+	dhcp          *dhcpModel
+	dns           *dnsModel
+	neighbors     *neighborsModel
+	stp           *stpModel
+	errors        *errorsModel
+	follow        *followModel
+	tcp           *tcpModel
+	conversations *trafficModel
+	endpoints     *trafficModel
+	hierarchy     *hierarchyModel
+	tls           *tlsModel
+	http          *httpModel
+	nd            *ndModel
+	captureFilter *captureFilterModel
+
+	// Interface the current frames were captured on, recorded when exporting them
+	interfaceName string
 
 	// Error message
 	err error
 }
 
 // StartUI initializes and starts the UI
-func StartUI(captureEngine *capture.CaptureEngine) error {
+func StartUI(captureEngine *capture.CaptureEngine, frameAnalyzer *analyzer.FrameAnalyzer) error {
 	// Initialize the storage manager
 	storageManager, err := storage.NewStorageManager("")
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage manager: %v", err)
 	}
 
-	// Initialize the frame analyzer
-	frameAnalyzer := analyzer.NewFrameAnalyzer()
-
 	// Initialize the main model
 	model := &MainModel{
 		state:          stateMainMenu,
@@ -61,6 +104,7 @@ func StartUI(captureEngine *capture.CaptureEngine) error {
 		frameAnalyzer:  frameAnalyzer,
 		frames:         make([]*models.Frame, 0),
 		selectedFrame:  0,
+		interfaceName:  captureEngine.GetInterfaceName(),
 	}
 
 	// Initialize UI components
@@ -68,6 +112,25 @@ func StartUI(captureEngine *capture.CaptureEngine) error {
 	model.frameList = newFrameListModel()
 	model.frameDetail = newFrameDetailModel()
 	model.savedCaptures = newSavedCapturesModel(storageManager)
+	model.statsMenu = newStatsMenuModel()
+	model.airtime = newAirtimeModel(frameAnalyzer.Airtime())
+	model.wmm = newWMMModel(frameAnalyzer.WMM())
+	model.dscp = newDSCPModel(frameAnalyzer.DSCP())
+	model.arp = newARPModel(frameAnalyzer.ARP())
+	model.dhcp = newDHCPModel(frameAnalyzer.DHCP())
+	model.dns = newDNSModel(frameAnalyzer.DNS())
+	model.neighbors = newNeighborsModel(frameAnalyzer.Neighbors())
+	model.stp = newSTPModel(frameAnalyzer.STP())
+	model.errors = newErrorsModel(frameAnalyzer.Errors())
+	model.follow = newFollowModel(storageManager)
+	model.tcp = newTCPModel(frameAnalyzer.TCP())
+	model.conversations = newConversationsModel(frameAnalyzer.Conversations(), storageManager)
+	model.endpoints = newEndpointsModel(frameAnalyzer.Conversations(), storageManager)
+	model.hierarchy = newHierarchyModel(frameAnalyzer.Hierarchy())
+	model.tls = newTLSModel(frameAnalyzer.TLS())
+	model.http = newHTTPModel(frameAnalyzer.HTTP(), storageManager)
+	model.nd = newNDModel(frameAnalyzer.ND())
+	model.captureFilter = newCaptureFilterModel(captureEngine)
 
 	// Create and start the Bubble Tea program
 	p := tea.NewProgram(model, tea.WithAltScreen())
@@ -91,9 +154,14 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "ctrl+c", "q":
-			// Always allow quitting with ctrl+c or q
+		case "ctrl+c":
+			// Always allow quitting with ctrl+c
 			return m, tea.Quit
+		case "q":
+			// Allow quitting with q unless it is being typed in a text input
+			if !m.typing() {
+				return m, tea.Quit
+			}
 		}
 	}
 
@@ -110,6 +178,9 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "Iniciar Captura":
 				m.state = stateCapturing
 				cmds = append(cmds, m.startCapturing())
+			case "Filtro de Captura":
+				m.state = stateCaptureFilter
+				m.captureFilter.open()
 			case "Cargar Captura":
 				m.state = stateSavedCaptures
 				cmds = append(cmds, m.savedCaptures.loadSavedCaptures())
@@ -136,6 +207,10 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.err = fmt.Errorf("Captura guardada en %s", metadata.Filename)
 				}
+			case "x":
+				m.exportFrames(m.frames, storage.FormatPcapng)
+			case "X":
+				m.exportFrames(m.frames, storage.FormatPcap)
 			case "enter":
 				// Stop capturing and show frame list
 				if len(m.frames) > 0 {
@@ -149,28 +224,37 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Check for new frames
 		if newFrameMsg, ok := msg.(newFrameMsg); ok {
 			m.frames = append(m.frames, newFrameMsg.frame)
+			// While the capture loop records frames to disk only the most recent ones are kept
+			if m.captureEngine.Recorder() != nil && len(m.frames) > maxRecordingFrames {
+				m.frames = m.frames[len(m.frames)-maxRecordingFrames:]
+			}
 			// Analyze the frame
 			m.frameAnalyzer.AnalyzeFrame(newFrameMsg.frame)
 			cmds = append(cmds, m.checkForMoreFrames())
 		}
 
 	case stateFrameList:
+		// Keys typed in the display filter bar belong to the frame list
+		editing := m.frameList.editing
+
 		// Update frame list
 		newFrameList, frameListCmd := m.frameList.Update(msg)
 		m.frameList = newFrameList.(*frameListModel)
 		cmds = append(cmds, frameListCmd)
 
 		// Handle key presses in frame list
-		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && !editing {
 			switch keyMsg.String() {
 			case "esc":
 				m.state = stateMainMenu
 			case "enter":
-				if len(m.frames) > 0 {
+				if frame := m.frameList.selected(); frame != nil {
 					m.selectedFrame = m.frameList.cursor
-					m.frameDetail.setFrame(m.frames[m.selectedFrame])
+					m.frameDetail.setFrame(frame)
 					m.state = stateFrameDetail
 				}
+			case "e":
+				m.state = stateStatistics
 			case "s":
 				// Save the current capture
 				metadata := &storage.SaveMetadata{
@@ -182,6 +266,11 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.err = fmt.Errorf("Captura guardada en %s", metadata.Filename)
 				}
+			case "x":
+				// Export the frames matched by the display filter
+				m.exportFrames(m.frameList.visible, storage.FormatPcapng)
+			case "X":
+				m.exportFrames(m.frameList.visible, storage.FormatPcap)
 			}
 		}
 
@@ -197,20 +286,59 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "esc":
 				m.state = stateFrameList
 			case "right", "l", "n":
-				// Next frame
-				if m.selectedFrame < len(m.frames)-1 {
+				// Next frame matched by the display filter
+				if m.selectedFrame < len(m.frameList.visible)-1 {
 					m.selectedFrame++
-					m.frameDetail.setFrame(m.frames[m.selectedFrame])
+					m.frameList.setCursor(m.selectedFrame)
+					m.frameDetail.setFrame(m.frameList.selected())
 				}
 			case "left", "h", "p":
-				// Previous frame
+				// Previous frame matched by the display filter
 				if m.selectedFrame > 0 {
 					m.selectedFrame--
-					m.frameDetail.setFrame(m.frames[m.selectedFrame])
+					m.frameList.setCursor(m.selectedFrame)
+					m.frameDetail.setFrame(m.frameList.selected())
+				}
+			case "f":
+				// Follow the TCP stream of the frame
+				if stream, ok := m.frameAnalyzer.Streams().StreamOf(m.frameDetail.frame); ok {
+					m.follow.setStream(stream)
+					m.state = stateFollow
+				} else {
+					m.err = fmt.Errorf("La trama no pertenece a un stream TCP")
 				}
 			}
 		}
 
+	case stateFollow:
+		// Update the follow stream screen
+		newFollow, followCmd := m.follow.Update(msg)
+		m.follow = newFollow.(*followModel)
+		cmds = append(cmds, followCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateFrameDetail
+			}
+		}
+
+	case stateCaptureFilter:
+		// Keys typed in the builder inputs belong to the builder
+		editing := m.captureFilter.editing()
+
+		// Update the capture filter builder
+		newCaptureFilter, captureFilterCmd := m.captureFilter.Update(msg)
+		m.captureFilter = newCaptureFilter.(*captureFilterModel)
+		cmds = append(cmds, captureFilterCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && !editing {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateMainMenu
+			}
+		}
+
 	case stateSavedCaptures:
 		// Update saved captures list
 		newSavedCaptures, savedCapturesCmd := m.savedCaptures.Update(msg)
@@ -220,6 +348,14 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle saved capture selection
 		if loadFramesMsg, ok := msg.(loadFramesMsg); ok {
 			m.frames = loadFramesMsg.frames
+			m.interfaceName = loadFramesMsg.interfaceName
+
+			// Rebuild the capture-wide statistics from the loaded frames
+			m.frameAnalyzer.Reset()
+			for _, frame := range m.frames {
+				m.frameAnalyzer.AnalyzeFrame(frame)
+			}
+			m.frameAnalyzer.Flush()
 			if len(m.frames) > 0 {
 				m.state = stateFrameList
 				m.frameList.setFrames(m.frames)
@@ -236,6 +372,265 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateMainMenu
 			}
 		}
+
+	case stateStatistics:
+		// Update statistics menu
+		newStatsMenu, statsMenuCmd := m.statsMenu.Update(msg)
+		m.statsMenu = newStatsMenu.(*statsMenuModel)
+		cmds = append(cmds, statsMenuCmd)
+
+		// Handle statistics screen selection
+		if selectedMsg, ok := msg.(statsSelectedMsg); ok {
+			switch selectedMsg.option {
+			case statsOptionAirtime:
+				m.state = stateAirtime
+			case statsOptionWMM:
+				m.state = stateWMM
+			case statsOptionDSCP:
+				m.state = stateDSCP
+			case statsOptionARP:
+				m.state = stateARP
+			case statsOptionDHCP:
+				m.state = stateDHCP
+			case statsOptionDNS:
+				m.state = stateDNS
+			case statsOptionNeighbors:
+				m.state = stateNeighbors
+			case statsOptionSTP:
+				m.state = stateSTP
+			case statsOptionErrors:
+				m.state = stateErrors
+			case statsOptionTCP:
+				m.state = stateTCP
+			case statsOptionConversations:
+				m.state = stateConversations
+			case statsOptionEndpoints:
+				m.state = stateEndpoints
+			case statsOptionHierarchy:
+				m.state = stateHierarchy
+			case statsOptionTLS:
+				m.state = stateTLS
+			case statsOptionHTTP:
+				m.state = stateHTTP
+			case statsOptionND:
+				m.state = stateND
+			}
+		}
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateFrameList
+			}
+		}
+
+	case stateAirtime:
+		// Update airtime dashboard
+		newAirtime, airtimeCmd := m.airtime.Update(msg)
+		m.airtime = newAirtime.(*airtimeModel)
+		cmds = append(cmds, airtimeCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateWMM:
+		// Update WMM compliance screen
+		newWMM, wmmCmd := m.wmm.Update(msg)
+		m.wmm = newWMM.(*wmmModel)
+		cmds = append(cmds, wmmCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateDSCP:
+		// Update DSCP audit screen
+		newDSCP, dscpCmd := m.dscp.Update(msg)
+		m.dscp = newDSCP.(*dscpModel)
+		cmds = append(cmds, dscpCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateARP:
+		// Update ARP screen
+		newARP, arpCmd := m.arp.Update(msg)
+		m.arp = newARP.(*arpModel)
+		cmds = append(cmds, arpCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateDHCP:
+		// Update DHCP screen
+		newDHCP, dhcpCmd := m.dhcp.Update(msg)
+		m.dhcp = newDHCP.(*dhcpModel)
+		cmds = append(cmds, dhcpCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateDNS:
+		// Update DNS screen
+		newDNS, dnsCmd := m.dns.Update(msg)
+		m.dns = newDNS.(*dnsModel)
+		cmds = append(cmds, dnsCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateNeighbors:
+		// Update neighbors screen
+		newNeighbors, neighborsCmd := m.neighbors.Update(msg)
+		m.neighbors = newNeighbors.(*neighborsModel)
+		cmds = append(cmds, neighborsCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateSTP:
+		// Update spanning tree screen
+		newSTP, stpCmd := m.stp.Update(msg)
+		m.stp = newSTP.(*stpModel)
+		cmds = append(cmds, stpCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateErrors:
+		// Update the error frames screen
+		newErrors, errorsCmd := m.errors.Update(msg)
+		m.errors = newErrors.(*errorsModel)
+		cmds = append(cmds, errorsCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateTCP:
+		// Update the TCP analysis screen
+		newTCP, tcpCmd := m.tcp.Update(msg)
+		m.tcp = newTCP.(*tcpModel)
+		cmds = append(cmds, tcpCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateConversations:
+		// Update the conversations screen
+		newConversations, conversationsCmd := m.conversations.Update(msg)
+		m.conversations = newConversations.(*trafficModel)
+		cmds = append(cmds, conversationsCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateEndpoints:
+		// Update the endpoints screen
+		newEndpoints, endpointsCmd := m.endpoints.Update(msg)
+		m.endpoints = newEndpoints.(*trafficModel)
+		cmds = append(cmds, endpointsCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateHierarchy:
+		// Update the protocol hierarchy screen
+		newHierarchy, hierarchyCmd := m.hierarchy.Update(msg)
+		m.hierarchy = newHierarchy.(*hierarchyModel)
+		cmds = append(cmds, hierarchyCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateTLS:
+		// Update the TLS sessions screen
+		newTLS, tlsCmd := m.tls.Update(msg)
+		m.tls = newTLS.(*tlsModel)
+		cmds = append(cmds, tlsCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateHTTP:
+		// Update the HTTP transactions screen
+		newHTTP, httpCmd := m.http.Update(msg)
+		m.http = newHTTP.(*httpModel)
+		cmds = append(cmds, httpCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
+
+	case stateND:
+		// Update the IPv6 neighbor screen
+		newND, ndCmd := m.nd.Update(msg)
+		m.nd = newND.(*ndModel)
+		cmds = append(cmds, ndCmd)
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "esc":
+				m.state = stateStatistics
+			}
+		}
 	}
 
 	return m, tea.Batch(cmds...)
@@ -262,9 +657,18 @@ func (m *MainModel) View() string {
 	case stateCapturing:
 		sb.WriteString("Capturando tramas...\n")
 		sb.WriteString(fmt.Sprintf("Interfaz: %s\n", m.captureEngine.GetInterfaceName()))
-		sb.WriteString(fmt.Sprintf("Tramas capturadas: %d\n", len(m.frames)))
+		sb.WriteString(fmt.Sprintf("Filtro de captura: %s\n", filterOrNone(m.captureEngine.GetFilter())))
+		if recorder := m.captureEngine.Recorder(); recorder != nil {
+			sb.WriteString(fmt.Sprintf("Grabación rotativa: %s (%d tramas grabadas)\n", filterOrNone(recorder.Filename()), recorder.Frames()))
+			if err := m.captureEngine.RecordError(); err != nil {
+				sb.WriteString(fmt.Sprintf("⚠️ Grabación detenida: %v\n", err))
+			}
+			sb.WriteString(fmt.Sprintf("Tramas en memoria: %d (últimas %d)\n", len(m.frames), maxRecordingFrames))
+		} else {
+			sb.WriteString(fmt.Sprintf("Tramas capturadas: %d\n", len(m.frames)))
+		}
 		sb.WriteString("\nPresione Enter para detener y ver las tramas\n")
-		sb.WriteString("Presione 's' para guardar la captura\n")
+		sb.WriteString("Presione 's' para guardar la captura, 'x' para exportarla a pcapng o 'X' a pcap\n")
 		sb.WriteString("Presione Esc para volver al menú principal\n")
 	case stateFrameList:
 		sb.WriteString(m.frameList.View())
@@ -272,11 +676,62 @@ func (m *MainModel) View() string {
 		sb.WriteString(m.frameDetail.View())
 	case stateSavedCaptures:
 		sb.WriteString(m.savedCaptures.View())
+	case stateStatistics:
+		sb.WriteString(m.statsMenu.View())
+	case stateAirtime:
+		sb.WriteString(m.airtime.View())
+	case stateWMM:
+		sb.WriteString(m.wmm.View())
+	case stateDSCP:
+		sb.WriteString(m.dscp.View())
+	case stateARP:
+		sb.WriteString(m.arp.View())
+	case stateDHCP:
+		sb.WriteString(m.dhcp.View())
+	case stateDNS:
+		sb.WriteString(m.dns.View())
+	case stateNeighbors:
+		sb.WriteString(m.neighbors.View())
+	case stateSTP:
+		sb.WriteString(m.stp.View())
+	case stateErrors:
+		sb.WriteString(m.errors.View())
+	case stateFollow:
+		sb.WriteString(m.follow.View())
+	case stateTCP:
+		sb.WriteString(m.tcp.View())
+	case stateConversations:
+		sb.WriteString(m.conversations.View())
+	case stateEndpoints:
+		sb.WriteString(m.endpoints.View())
+	case stateHierarchy:
+		sb.WriteString(m.hierarchy.View())
+	case stateTLS:
+		sb.WriteString(m.tls.View())
+	case stateHTTP:
+		sb.WriteString(m.http.View())
+	case stateND:
+		sb.WriteString(m.nd.View())
+	case stateCaptureFilter:
+		sb.WriteString(m.captureFilter.View())
 	}
 
 	return sb.String()
 }
 
+// typing reports whether the current screen is reading text, so that keys
+// such as q are typed instead of acting on the UI
+func (m *MainModel) typing() bool {
+	switch m.state {
+	case stateFrameList:
+		return m.frameList.editing
+	case stateCaptureFilter:
+		return m.captureFilter.editing()
+	default:
+		return false
+	}
+}
+
 // startCapturing starts capturing frames
 func (m *MainModel) startCapturing() tea.Cmd {
 	return func() tea.Msg {
@@ -286,16 +741,33 @@ func (m *MainModel) startCapturing() tea.Cmd {
 			return nil
 		}
 
-		// Clear any previous frames
+		// Clear any previous frames and statistics
 		m.frames = make([]*models.Frame, 0)
+		m.interfaceName = m.captureEngine.GetInterfaceName()
+		m.frameAnalyzer.Reset()
 
 		// Return a command to check for frames
 		return m.checkForMoreFrames()()
 	}
 }
 
+// exportFrames writes frames to a pcap or pcapng file in the storage directory
+// so that they can be opened with Wireshark
+func (m *MainModel) exportFrames(frames []*models.Frame, format storage.PacketFormat) {
+	filename := fmt.Sprintf("capture_%s.%s", time.Now().Format("20060102_150405"), format)
+	filename, err := m.storageManager.ExportFrames(filename, frames, m.interfaceName)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = fmt.Errorf("%d tramas exportadas a %s", len(frames), filename)
+}
+
 // stopCapturing stops capturing frames
 func (m *MainModel) stopCapturing() tea.Cmd {
+	// No more frames are analyzed once the capture stops
+	m.frameAnalyzer.Flush()
+
 	return func() tea.Msg {
 		// Stop the capture engine
 		m.captureEngine.Stop()
@@ -333,5 +805,6 @@ type menuSelectedMsg struct {
 
 // loadFramesMsg is a message sent when frames are loaded from storage
 type loadFramesMsg struct {
-	frames []*models.Frame
+	frames        []*models.Frame
+	interfaceName string
 }
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/julianarchila/gocapture/internal/analyzer"
+	"github.com/julianarchila/gocapture/pkg/models"
+)
+
+// wmmModel represents the WMM compliance screen
+type wmmModel struct {
+	wmm    *analyzer.WMMAnalyzer
+	scroll scroller
+}
+
+// newWMMModel creates a new WMM compliance model
+func newWMMModel(wmm *analyzer.WMMAnalyzer) *wmmModel {
+	return &wmmModel{
+		wmm: wmm,
+	}
+}
+
+// Init initializes the WMM compliance model
+func (m *wmmModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles updates to the WMM compliance model
+func (m *wmmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.scroll.update(keyMsg)
+	}
+
+	return m, nil
+}
+
+// View renders the WMM compliance screen
+func (m *wmmModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString("🎚 Cumplimiento WMM\n\n")
+
+	var content strings.Builder
+
+	// Findings first, they are what the user is looking for
+	findings := m.wmm.Findings()
+	content.WriteString(fmt.Sprintf("Hallazgos (%d):\n", len(findings)))
+	if len(findings) == 0 {
+		content.WriteString("  Ninguno\n")
+	}
+	for _, finding := range findings {
+		who := finding.Station
+		if who == "" {
+			who = "AP " + finding.BSSID
+		}
+		content.WriteString(fmt.Sprintf("  ⚠ %s [%s] %s\n", who, finding.AccessCategory, finding.Description))
+	}
+
+	// Parameters advertised by access points
+	advertised := m.wmm.AdvertisedParameters()
+	bssids := make([]string, 0, len(advertised))
+	for bssid := range advertised {
+		bssids = append(bssids, bssid)
+	}
+	sort.Strings(bssids)
+
+	content.WriteString("\nParámetros WMM anunciados:\n")
+	if len(bssids) == 0 {
+		content.WriteString("  No se han visto beacons con elemento WMM\n")
+	}
+	for _, bssid := range bssids {
+		wmm := advertised[bssid]
+		content.WriteString(fmt.Sprintf("  %s (U-APSD: %v)\n", bssid, wmm.UAPSD))
+		for _, params := range wmm.ACParameters {
+			content.WriteString(fmt.Sprintf("    %s AIFSN=%d ECWmin=%d ECWmax=%d TXOP=%dμs ACM=%v\n",
+				params.AccessCategory, params.AIFSN, params.ECWMin, params.ECWMax, params.TXOPLimit*32, params.ACM))
+		}
+	}
+
+	// Observed usage per station
+	stations := m.wmm.Stations()
+	content.WriteString(fmt.Sprintf("\nEstaciones QoS (%d):\n", len(stations)))
+	for _, stats := range stations {
+		content.WriteString(fmt.Sprintf("  %s (BSSID %s)\n", stats.Station, stats.BSSID))
+
+		for _, accessCategory := range []string{models.AccessCategoryVoice, models.AccessCategoryVideo, models.AccessCategoryBestEffort, models.AccessCategoryBackground} {
+			acStats, ok := stats.ACs[accessCategory]
+			if !ok {
+				continue
+			}
+			content.WriteString(fmt.Sprintf("    %s: %d tramas, %d bytes (media %.0f), TXOP en %d tramas (máx %dμs)\n",
+				accessCategory, acStats.Frames, acStats.Bytes, acStats.AverageSize(), acStats.TXOPFrames, acStats.MaxTXOP*32))
+		}
+
+		policies := make([]string, 0, len(stats.ACKPolicies))
+		for policy, count := range stats.ACKPolicies {
+			policies = append(policies, fmt.Sprintf("%s=%d", policy, count))
+		}
+		sort.Strings(policies)
+		content.WriteString(fmt.Sprintf("    Políticas ACK: %s\n", strings.Join(policies, ", ")))
+	}
+
+	sb.WriteString(m.scroll.render(content.String()))
+	sb.WriteString("\nUse las flechas para desplazar, Esc para volver\n")
+
+	return sb.String()
+}